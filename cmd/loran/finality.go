@@ -0,0 +1,28 @@
+package loran
+
+import (
+	"github.com/knadh/koanf"
+	"github.com/cicizeo/loran/orchestrator/finality"
+)
+
+const finalityProfile = "profile"
+
+// parseFinalityProfiles returns the finality profiles to use given the finality flags. The profile finality uses the
+// built-in per-chain profiles, optionally overridden by a file; any other finality applies to every chain.
+func parseFinalityProfiles(konfig *koanf.Koanf) (finality.Profiles, error) {
+	finalityType := konfig.String(flagEthFinality)
+	if finalityType != finalityProfile {
+		strategy, err := finality.ParseStrategy(finalityType, uint64(konfig.Int64(flagEthFinalityDepth)))
+		if err != nil {
+			return finality.Profiles{}, err
+		}
+
+		return finality.SingleProfile(strategy), nil
+	}
+
+	if profilesPath := konfig.String(flagEthFinalityProfiles); profilesPath != "" {
+		return finality.LoadProfiles(profilesPath)
+	}
+
+	return finality.DefaultProfiles(), nil
+}
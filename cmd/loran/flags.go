@@ -4,6 +4,8 @@ package loran
 import (
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
 	"github.com/spf13/pflag"
+	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
+	"github.com/cicizeo/loran/orchestrator/ethereum/keystore"
 )
 
 const (
@@ -16,6 +18,11 @@ const (
 	flagCosmosChainID           = "cosmos-chain-id"
 	flagCosmosGRPC              = "cosmos-grpc"
 	flagTendermintRPC           = "tendermint-rpc"
+	flagCosmosLightClient       = "cosmos-light-client"
+	flagCosmosTrustHeight       = "cosmos-trust-height"
+	flagCosmosTrustHash         = "cosmos-trust-hash"
+	flagCosmosTrustPeriod       = "cosmos-trust-period"
+	flagCosmosWitnesses         = "cosmos-witnesses"
 	flagCosmosGasPrices         = "cosmos-gas-prices"
 	flagCosmosKeyring           = "cosmos-keyring"
 	flagCosmosKeyringDir        = "cosmos-keyring-dir"
@@ -31,12 +38,27 @@ const (
 	flagEthPassphrase           = "eth-passphrase"
 	flagEthPK                   = "eth-pk"
 	flagEthUseLedger            = "eth-use-ledger"
+	flagEthRemoteSigner         = "eth-remote-signer"
+	flagEthRemoteSignerMethod   = "eth-remote-signer-method"
 	flagEthRPC                  = "eth-rpc"
 	flagEthGasAdjustment        = "eth-gas-price-adjustment"
 	flagEthGasLimitAdjustment   = "eth-gas-limit-adjustment"
+	flagEthFeeMode              = "eth-fee-mode"
+	flagEthResendAfterBlocks    = "eth-resend-after-blocks"
+	flagEthGasPriceBump         = "eth-gas-price-bump"
+	flagEthMaxGasPrice          = "eth-max-gas-price"
+	flagEthPrivateRPC           = "eth-private-rpc"
+	flagEthPrivateRPCMethod     = "eth-private-rpc-method"
+	flagEthPrivateRPCAuthKey    = "eth-private-rpc-auth-key"
+	flagEthPrivateFallback      = "eth-private-fallback-blocks"
 	flagEthAlchemyWS            = "eth-alchemy-ws"
+	flagEthMempoolSource        = "eth-mempool-source"
+	flagEthMempoolRPC           = "eth-mempool-rpc"
+	flagEthMempoolPollInterval  = "eth-mempool-poll-interval"
 	flagRelayValsets            = "relay-valsets"
 	flagRelayBatches            = "relay-batches"
+	flagRelayLogicCalls         = "relay-logic-calls"
+	flagRelayBatchStrategy      = "relay-batch-strategy"
 	flagCoinGeckoAPI            = "coingecko-api"
 	flagEthGasPrice             = "eth-gas-price"
 	flagEthGasLimit             = "eth-gas-limit"
@@ -44,9 +66,26 @@ const (
 	flagEthBlocksPerLoop        = "eth-blocks-per-loop"
 	flagEthPendingTXWait        = "eth-pending-tx-wait"
 	flagProfitMultiplier        = "profit-multiplier"
+	flagValsetProfitMultiplier  = "valset-profit-multiplier"
+	flagValsetMaxAge            = "valset-max-age"
 	flagRelayerLoopMultiplier   = "relayer-loop-multiplier"
 	flagRequesterLoopMultiplier = "requester-loop-multiplier"
 	flagBridgeStartHeight       = "bridge-start-height"
+	flagEventIndexDir           = "event-index-dir"
+	flagSignJournalDir          = "sign-journal-dir"
+	flagRelayStateDir           = "relay-state-dir"
+	flagSignPolicy              = "sign-policy"
+	flagEthFinality             = "eth-finality"
+	flagEthFinalityDepth        = "eth-finality-depth"
+	flagEthFinalityProfiles     = "eth-finality-profiles"
+	flagEthQuorumRPCs           = "eth-quorum-rpcs"
+	flagEthQuorum               = "eth-quorum"
+	flagEthVerifyReceipts       = "eth-verify-receipts"
+	flagEthHeaderRPC            = "eth-header-rpc"
+	flagWatchtowerInterval      = "watchtower-interval"
+	flagAttestationStallTimeout = "attestation-stall-timeout"
+	flagAlertsFile              = "alerts-file"
+	flagAlertWebhook            = "alert-webhook"
 )
 
 func cosmosFlagSet() *pflag.FlagSet {
@@ -82,6 +121,8 @@ func ethereumKeyOptsFlagSet() *pflag.FlagSet {
 	fs.String(flagEthPassphrase, "", "Specify the passphrase to unlock the private key from armor; If empty then STDIN is used")
 	fs.String(flagEthPK, "", "Provide the Ethereum private key of the orchestrator in hex")
 	fs.Bool(flagEthUseLedger, false, "Use the Ethereum app on hardware ledger to sign transactions")
+	fs.String(flagEthRemoteSigner, "", "Specify the JSON-RPC endpoint of a remote signer (e.g. Clef or Web3Signer) holding the Ethereum key of --eth-from")
+	fs.String(flagEthRemoteSignerMethod, keystore.PersonalSignMethod, "The remote signer method used to sign messages (personal_sign|account_signData)")
 	return fs
 }
 
@@ -91,6 +132,24 @@ func ethereumOptsFlagSet() *pflag.FlagSet {
 	fs.String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
 	fs.Float64(flagEthGasAdjustment, float64(1.3), "Specify a gas price adjustment for Ethereum transactions")
 	fs.Float64(flagEthGasLimitAdjustment, float64(1.2), "Specify a gas limit adjustment for Ethereum transactions")
+	fs.String(flagEthFeeMode, string(committer.FeeModeLegacy), "Specify the kind of Ethereum transactions to send (legacy|dynamic); dynamic sends EIP-1559 transactions and applies the gas price adjustment to the priority fee")
+	fs.Uint64(flagEthResendAfterBlocks, 0, "Number of blocks a sent Ethereum transaction may stay pending before it is replaced with a higher gas price; zero disables the replacements")
+	fs.Uint64(flagEthGasPriceBump, 20, "Percentage the gas price of a replacement Ethereum transaction is increased by (at least 10)")
+	fs.Int64(flagEthMaxGasPrice, 0, "The max gas price (max fee per gas with dynamic fees) in wei of a replacement Ethereum transaction; zero for no limit")
+	fs.String(flagEthPrivateRPC, "", "Specify the (optional) JSON-RPC endpoint of a private transaction relay the Ethereum transactions are sent to instead of the public mempool")
+	fs.String(flagEthPrivateRPCMethod, committer.PrivateTxMethodSendPrivateTransaction, "The private transaction relay method used to send transactions (eth_sendPrivateTransaction|eth_sendBundle)")
+	fs.String(flagEthPrivateRPCAuthKey, "", "Provide the (optional) private key in hex the requests to the private transaction relay are signed with; it doesn't need to hold any funds")
+	fs.Uint64(flagEthPrivateFallback, 25, "Number of blocks a private Ethereum transaction may stay pending before it is sent to the public mempool")
+
+	return fs
+}
+
+func ethereumFinalityFlagSet() *pflag.FlagSet {
+	fs := pflag.NewFlagSet("", pflag.ContinueOnError)
+
+	fs.String(flagEthFinality, "profile", "Specify how Ethereum blocks are considered final (profile|depth|finalized|safe)")
+	fs.Uint64(flagEthFinalityDepth, 13, "Number of confirmations for a block to be considered final, if using depth finality")
+	fs.String(flagEthFinalityProfiles, "", "Specify an (optional) TOML file with per-chain finality profiles overriding the built-in ones, if using profile finality")
 
 	return fs
 }
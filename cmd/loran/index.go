@@ -0,0 +1,115 @@
+package loran
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+)
+
+func getIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "index",
+		Short: "Commands to maintain the local Gravity contract event index",
+	}
+
+	cmd.AddCommand(
+		rebuildIndexCmd(),
+	)
+
+	return cmd
+}
+
+func rebuildIndexCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "rebuild [gravity-addr]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Drop the local event index and rebuild it from scratch",
+		Long: `Drop the local event index and rebuild it from scratch by scanning the Gravity
+contract events on Ethereum, starting from the bridge start height. Use this
+when the index got corrupted. The orchestrator must be stopped while rebuilding.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			eventIndexDir := konfig.String(flagEventIndexDir)
+			if eventIndexDir == "" {
+				return fmt.Errorf("the event index directory must be provided")
+			}
+
+			ethRPCEndpoint := konfig.String(flagEthRPC)
+			ethRPC, err := ethrpc.Dial(ethRPCEndpoint)
+			if err != nil {
+				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", ethRPCEndpoint)
+
+			finalityProfiles, err := parseFinalityProfiles(konfig)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			// listen for and trap any OS signal to gracefully shutdown and exit
+			trapSignal(cancel)
+
+			chainID, err := ethclient.NewClient(ethRPC).ChainID(ctx)
+			if err != nil {
+				return fmt.Errorf("failed to get Ethereum chain ID: %w", err)
+			}
+
+			eventIndex, err := eventindex.NewBadgerIndex(logger, eventIndexDir)
+			if err != nil {
+				return err
+			}
+			defer eventIndex.Close()
+
+			indexer, err := eventindex.NewIndexer(
+				logger,
+				eventIndex,
+				provider.NewEVMProvider(ethRPC),
+				ethcmn.HexToAddress(args[0]),
+				uint64(konfig.Int64(flagBridgeStartHeight)),
+				uint64(konfig.Int64(flagEthBlocksPerLoop)),
+				finalityProfiles.ForChain(chainID.Uint64()),
+				time.Minute,
+			)
+			if err != nil {
+				return err
+			}
+
+			lastIndexedBlock, err := indexer.Rebuild(ctx)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(os.Stderr, "Event index rebuilt up to block %d\n", lastIndexedBlock)
+			return nil
+		},
+	}
+
+	cmd.Flags().String(flagEventIndexDir, "", "Specify the directory of the local Gravity contract event index")
+	cmd.Flags().String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
+	cmd.Flags().Int64(flagBridgeStartHeight, 0, "Set the Ethereum height the Gravity contract was deployed at")
+	cmd.Flags().Int64(flagEthBlocksPerLoop, 2000, "Maximum number of Ethereum blocks to index per request; shrunk automatically if the provider rejects the range")
+	cmd.Flags().AddFlagSet(ethereumFinalityFlagSet())
+
+	return cmd
+}
@@ -22,6 +22,7 @@ import (
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
 	"github.com/knadh/koanf"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
@@ -189,6 +190,7 @@ func initEthereumAccountsManager(
 	ethPrivKey := konfig.String(flagEthPK)
 	ethKeystoreDir := konfig.String(flagEthKeystoreDir)
 	ethPassphrase := konfig.String(flagEthPassphrase)
+	ethRemoteSigner := konfig.String(flagEthRemoteSigner)
 
 	switch {
 	case ethUseLedger:
@@ -262,6 +264,33 @@ func initEthereumAccountsManager(
 
 		return ethKeyFromAddress, signerFn, personalSignFn, nil
 
+	case len(ethRemoteSigner) > 0:
+		if len(ethKeyFrom) == 0 {
+			return emptyEthAddress, nil, nil, errors.New("cannot use a remote signer without from address specified")
+		}
+
+		ethKeyFromAddress = ethcmn.HexToAddress(ethKeyFrom)
+		if ethKeyFromAddress == (ethcmn.Address{}) {
+			return emptyEthAddress, nil, nil, fmt.Errorf("failed to parse Ethereum from address: %s", ethKeyFrom)
+		}
+
+		rc, err := ethrpc.Dial(ethRemoteSigner)
+		if err != nil {
+			return emptyEthAddress, nil, nil, fmt.Errorf("failed to dial the remote signer: %w", err)
+		}
+
+		remoteSigner, err := keystore.NewRemoteSigner(
+			rc,
+			ethKeyFromAddress,
+			ethChainID,
+			konfig.String(flagEthRemoteSignerMethod),
+		)
+		if err != nil {
+			return emptyEthAddress, nil, nil, err
+		}
+
+		return ethKeyFromAddress, remoteSigner.SignerFn(), remoteSigner.PersonalSignFn(), nil
+
 	case len(ethPrivKey) > 0:
 		ethPk, err := ethcrypto.ToECDSA(ethcmn.FromHex(ethPrivKey))
 		if err != nil {
@@ -32,6 +32,8 @@ flag (e.g. LORAN_COSMOS_PK).`,
 	cmd.AddCommand(
 		getOrchestratorCmd(),
 		getBridgeCommand(),
+		getIndexCmd(),
+		getWatchtowerCmd(),
 		getQueryCmd(),
 		getTxCmd(),
 		getVersionCmd(),
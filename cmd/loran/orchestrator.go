@@ -3,27 +3,40 @@ package loran
 
 import (
 	"context"
+	"crypto/ecdsa"
+	"encoding/hex"
 	"fmt"
+	"math/big"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/knadh/koanf"
 	"github.com/rs/zerolog"
 	"github.com/spf13/cobra"
+	"github.com/tendermint/tendermint/light"
 	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
 	"github.com/cicizeo/loran/cmd/loran/client"
 	"github.com/cicizeo/loran/orchestrator"
 	"github.com/cicizeo/loran/orchestrator/coingecko"
 	"github.com/cicizeo/loran/orchestrator/cosmos"
+	"github.com/cicizeo/loran/orchestrator/cosmos/lightclient"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
 	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
 	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
 	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+	"github.com/cicizeo/loran/orchestrator/ethereum/receiptproof"
 	"github.com/cicizeo/loran/orchestrator/relayer"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
+	"github.com/cicizeo/loran/orchestrator/signjournal"
+	"github.com/cicizeo/loran/orchestrator/signpolicy"
 	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
 	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
@@ -124,8 +137,64 @@ func getOrchestratorCmd() *cobra.Command {
 			fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", ethRPCEndpoint)
 			ethProvider := provider.NewEVMProvider(ethRPC)
 
+			// If we have more endpoints, only relay the Gravity events a quorum of them agree on.
+			var (
+				quorumMembers []provider.QuorumMember
+				quorum        = konfig.Int(flagEthQuorum)
+			)
+
+			if quorumRPCEndpoints := konfig.Strings(flagEthQuorumRPCs); len(quorumRPCEndpoints) > 0 {
+				quorumMembers, err = dialQuorumMembers(ethProvider, ethRPCEndpoint, quorumRPCEndpoints)
+				if err != nil {
+					return err
+				}
+
+				if quorum == 0 {
+					quorum = len(quorumMembers)/2 + 1
+				}
+
+				ethProvider, err = provider.NewQuorumProvider(logger, ethProvider, quorumMembers, quorum)
+				if err != nil {
+					return err
+				}
+			}
+
 			ethGasPriceAdjustment := konfig.Float64(flagEthGasAdjustment)
 			ethGasLimitAdjustment := konfig.Float64(flagEthGasLimitAdjustment)
+			ethFeeMode, err := committer.ParseFeeMode(konfig.String(flagEthFeeMode))
+			if err != nil {
+				return err
+			}
+
+			committerOpts := []committer.EVMCommitterOption{
+				committer.OptionFeeMode(ethFeeMode),
+				committer.OptionResendAfterBlocks(uint64(konfig.Int64(flagEthResendAfterBlocks))),
+				committer.OptionGasPriceBump(uint64(konfig.Int64(flagEthGasPriceBump))),
+			}
+
+			if gravityParams.AverageEthereumBlockTime > 0 {
+				// gravityParams.AverageEthereumBlockTime is in milliseconds.
+				committerOpts = append(committerOpts, committer.OptionTxPollInterval(
+					time.Duration(gravityParams.AverageEthereumBlockTime)*time.Millisecond,
+				))
+			}
+
+			if maxGasPrice := konfig.Int64(flagEthMaxGasPrice); maxGasPrice > 0 {
+				committerOpts = append(committerOpts, committer.OptionMaxGasPrice(big.NewInt(maxGasPrice)))
+			}
+
+			if privateRPCEndpoint := konfig.String(flagEthPrivateRPC); privateRPCEndpoint != "" {
+				privateTxSender, err := newPrivateTxSender(konfig, privateRPCEndpoint)
+				if err != nil {
+					return err
+				}
+
+				committerOpts = append(committerOpts, committer.OptionPrivateTxSender(
+					privateTxSender,
+					uint64(konfig.Int64(flagEthPrivateFallback)),
+				))
+			}
+
 			ethCommitter, err := committer.NewEthCommitter(
 				logger,
 				ethKeyFromAddress,
@@ -133,6 +202,7 @@ func getOrchestratorCmd() *cobra.Command {
 				ethGasLimitAdjustment,
 				signerFn,
 				ethProvider,
+				committerOpts...,
 			)
 			if err != nil && err != grpc.ErrServerStopped {
 				return fmt.Errorf("failed to create Ethereum committer: %w", err)
@@ -176,16 +246,119 @@ func getOrchestratorCmd() *cobra.Command {
 			// Here we cast the float64 to a Duration (int64); as we are dealing with ms, we'll lose as much as 1ms.
 			relayerLoopDuration := time.Duration(ethBlockTimeF64*relayerLoopMultiplier) * time.Millisecond
 
+			var (
+				relayerOpts      = []func(relayer.GravityRelayer){relayer.SetPriceFeeder(coingeckoFeed)}
+				orchestratorOpts []func(orchestrator.GravityOrchestrator)
+			)
+
+			batchSelection, err := relayer.NewBatchSelectionStrategy(konfig.String(flagRelayBatchStrategy))
+			if err != nil {
+				return err
+			}
+
+			relayerOpts = append(
+				relayerOpts,
+				relayer.SetBatchSelectionStrategy(batchSelection),
+				relayer.SetValsetProfitability(
+					konfig.Float64(flagValsetProfitMultiplier),
+					konfig.Duration(flagValsetMaxAge),
+				),
+			)
+
+			finalityProfiles, err := parseFinalityProfiles(konfig)
+			if err != nil {
+				return err
+			}
+
+			orchestratorOpts = append(orchestratorOpts, orchestrator.SetFinalityProfiles(finalityProfiles))
+
+			// If asked to, prove every deposit and batch execution against the receipts root of a header we get
+			// from an independent source before claiming it. This is expensive: every block with a deposit or a
+			// batch execution costs one eth_getTransactionReceipt call per transaction in it, which on mainnet
+			// means a few hundred calls per block.
+			if konfig.Bool(flagEthVerifyReceipts) {
+				headerSource, err := newHeaderSource(logger, konfig.String(flagEthHeaderRPC), quorumMembers, quorum)
+				if err != nil {
+					return err
+				}
+
+				orchestratorOpts = append(
+					orchestratorOpts,
+					orchestrator.SetReceiptVerifier(receiptproof.NewVerifier(logger, ethProvider, headerSource)),
+				)
+			}
+
+			// If we have an event index directory, keep a local index of the Gravity events to avoid scanning
+			// Ethereum backwards on every start.
+			if eventIndexDir := konfig.String(flagEventIndexDir); eventIndexDir != "" {
+				eventIndex, err := eventindex.NewBadgerIndex(logger, eventIndexDir)
+				if err != nil {
+					return err
+				}
+				defer eventIndex.Close()
+
+				relayerOpts = append(relayerOpts, relayer.SetEventIndex(eventIndex))
+				orchestratorOpts = append(orchestratorOpts, orchestrator.SetEventIndex(eventIndex))
+			}
+
+			// If we have a signing journal directory, remember every checkpoint we sign so a compromised Cosmos node
+			// can never get our Ethereum key to sign two different checkpoints for the same valset or batch.
+			if signJournalDir := konfig.String(flagSignJournalDir); signJournalDir != "" {
+				signJournal, err := signjournal.NewBadgerJournal(logger, signJournalDir)
+				if err != nil {
+					return err
+				}
+				defer signJournal.Close()
+
+				orchestratorOpts = append(orchestratorOpts, orchestrator.SetSignJournal(signJournal))
+			}
+
+			// Remember the relays we sent and their outcome. Without a directory they are only remembered until we
+			// stop.
+			relayState, err := relaystate.NewBadgerStore(logger, konfig.String(flagRelayStateDir))
+			if err != nil {
+				return err
+			}
+			defer relayState.Close()
+
+			relayerOpts = append(relayerOpts, relayer.SetRelayState(relayState))
+
+			// If we have a signing policy, hold back our signature on the batches and valsets that violate it.
+			if signPolicyPath := konfig.String(flagSignPolicy); signPolicyPath != "" {
+				signPolicy, err := signpolicy.LoadPolicy(signPolicyPath)
+				if err != nil {
+					return err
+				}
+
+				orchestratorOpts = append(
+					orchestratorOpts,
+					orchestrator.SetSignPolicy(signpolicy.NewEngine(logger, signPolicy)),
+				)
+			}
+
+			// If asked to, only sign the valsets and batches proven to be in the committed state, against headers
+			// verified by a Tendermint light client.
+			var orchestratorQuerier gravitytypes.QueryClient = gravityQuerier
+			if konfig.Bool(flagCosmosLightClient) {
+				stateProver, err := newLightProver(ctx, logger, konfig, cosmosChainID, tmRPCEndpoint)
+				if err != nil {
+					return err
+				}
+
+				orchestratorQuerier = lightclient.NewQueryClient(logger, gravityQuerier, stateProver)
+			}
+
 			relayer := relayer.NewGravityRelayer(
 				logger,
 				gravityQuerier,
 				gravityContract,
 				konfig.Bool(flagRelayValsets),
 				konfig.Bool(flagRelayBatches),
+				konfig.Bool(flagRelayLogicCalls),
 				relayerLoopDuration,
 				konfig.Duration(flagEthPendingTXWait),
 				konfig.Float64(flagProfitMultiplier),
-				relayer.SetPriceFeeder(coingeckoFeed),
+				relayerOpts...,
 			)
 
 			logger = logger.With().
@@ -206,7 +379,7 @@ func getOrchestratorCmd() *cobra.Command {
 
 			orch := orchestrator.NewGravityOrchestrator(
 				logger,
-				gravityQuerier,
+				orchestratorQuerier,
 				gravityBroadcaster,
 				gravityContract,
 				ethKeyFromAddress,
@@ -218,8 +391,14 @@ func getOrchestratorCmd() *cobra.Command {
 				batchRequesterLoopDuration,
 				konfig.Int64(flagEthBlocksPerLoop),
 				konfig.Int64(flagBridgeStartHeight),
+				orchestratorOpts...,
 			)
 
+			mempoolSource, err := newMempoolSource(logger, konfig, ethRPCEndpoint)
+			if err != nil {
+				return err
+			}
+
 			ctx, cancel = context.WithCancel(context.Background())
 			g, errCtx := errgroup.WithContext(ctx)
 
@@ -227,11 +406,15 @@ func getOrchestratorCmd() *cobra.Command {
 				return startOrchestrator(errCtx, logger, orch)
 			})
 
-			// If we have the alchemy WS endpoint, start listening for txs against the Gravity Bridge contract.
-			alchemyWS := konfig.String(flagEthAlchemyWS)
-			if alchemyWS != "" {
+			// Follow the relayed transactions until they are mined, replacing the stuck ones.
+			g.Go(func() error {
+				return ethCommitter.TrackPendingTxs(errCtx)
+			})
+
+			// If we can watch the mempool, start listening for txs against the Gravity Bridge contract.
+			if mempoolSource != nil {
 				g.Go(func() error {
-					return gravityContract.SubscribeToPendingTxs(errCtx, alchemyWS)
+					return gravityContract.SubscribeToPendingTxs(errCtx, mempoolSource)
 				})
 			}
 
@@ -244,24 +427,217 @@ func getOrchestratorCmd() *cobra.Command {
 
 	cmd.Flags().Bool(flagRelayValsets, false, "Relay validator set updates to Ethereum")
 	cmd.Flags().Bool(flagRelayBatches, false, "Relay transaction batches to Ethereum")
-	cmd.Flags().Int64(flagEthBlocksPerLoop, 2000, "Number of Ethereum blocks to process per orchestrator loop")
+	cmd.Flags().Bool(flagRelayLogicCalls, false, "Relay arbitrary logic calls to Ethereum")
+	cmd.Flags().String(flagRelayBatchStrategy, relayer.BatchSelectionOldestFirst, "Specify how to pick the batch to relay for each token (oldest-first|highest-fee-per-gas|newest-supersedes)")
+	cmd.Flags().Int64(flagEthBlocksPerLoop, 2000, "Maximum number of Ethereum blocks to process per orchestrator loop; shrunk automatically if the provider rejects the range")
 	cmd.Flags().String(flagCoinGeckoAPI, "https://api.coingecko.com/api/v3", "Specify the coingecko API endpoint")
 	cmd.Flags().Duration(flagEthPendingTXWait, 20*time.Minute, "Time for a pending tx to be considered stale")
 	cmd.Flags().String(flagEthAlchemyWS, "", "Specify the Alchemy websocket endpoint")
+	cmd.Flags().String(flagEthMempoolSource, "", "Specify how to watch the Ethereum mempool for pending relays (alchemy|subscribe|txpool); defaults to alchemy if --eth-alchemy-ws is set, otherwise the mempool is not watched")
+	cmd.Flags().String(flagEthMempoolRPC, "", "Specify the Ethereum RPC endpoint of the subscribe and txpool mempool sources; defaults to --eth-rpc, subscribe needs a websocket endpoint")
+	cmd.Flags().Duration(flagEthMempoolPollInterval, 5*time.Second, "Time between two polls of the txpool mempool source")
 	cmd.Flags().Float64(flagProfitMultiplier, 1.0, "Multiplier to apply to relayer profit")
+	cmd.Flags().Float64(flagValsetProfitMultiplier, 0, "Multiplier to apply to the gas cost of a valset update compared to its reward; 0 relays every valset update")
+	cmd.Flags().Duration(flagValsetMaxAge, 24*time.Hour, "Relay the valset updates regardless of their reward once the valset on Ethereum is older than this; 0 disables it")
 	cmd.Flags().Float64(flagRelayerLoopMultiplier, 3.0, "Multiplier for the relayer loop duration (in ETH blocks)")
 	cmd.Flags().Float64(flagRequesterLoopMultiplier, 60.0, "Multiplier for the batch requester loop duration (in Cosmos blocks)")
 	cmd.Flags().String(flagCosmosFeeGranter, "", "Set an (optional) fee granter address that will pay for Cosmos fees (feegrant must exist)")
 	cmd.Flags().Int64(flagBridgeStartHeight, 0, "Set an (optional) height to wait for the bridge to be available")
 	cmd.Flags().Int(flagCosmosMsgsPerTx, 10, "Set a maximum number of messages to send per transaction (used for claims)")
+	cmd.Flags().String(flagEventIndexDir, "", "Set an (optional) directory to keep a local index of the Gravity contract events")
+	cmd.Flags().String(flagSignJournalDir, "", "Set an (optional) directory to keep a journal of the signed checkpoints, preventing double signs")
+	cmd.Flags().String(flagRelayStateDir, "", "Set an (optional) directory to keep the state of the relayed valsets, batches and logic calls across restarts")
+	cmd.Flags().String(flagSignPolicy, "", "Set an (optional) TOML file with the policy batches and valsets must follow before they are signed")
 	cmd.Flags().AddFlagSet(cosmosFlagSet())
 	cmd.Flags().AddFlagSet(cosmosKeyringFlagSet())
 	cmd.Flags().AddFlagSet(ethereumKeyOptsFlagSet())
+	cmd.Flags().StringSlice(flagEthQuorumRPCs, nil, "Specify (optional) additional Ethereum RPC endpoints the Gravity events must be cross-checked against")
+	cmd.Flags().Int(flagEthQuorum, 0, "Number of Ethereum RPC endpoints (including --eth-rpc) that must agree on an event to relay it; defaults to a majority")
+	cmd.Flags().Bool(flagEthVerifyReceipts, false, "Prove deposits and batch executions against the receipts root of an independently fetched header before claiming them; costs one receipt RPC call per transaction of every block with such an event")
+	cmd.Flags().String(flagEthHeaderRPC, "", "Specify an (optional) Ethereum RPC endpoint to fetch the headers used by --eth-verify-receipts from; defaults to a quorum of --eth-quorum-rpcs")
+	cmd.Flags().Bool(flagCosmosLightClient, false, "Prove the valsets, batches and params to sign against headers of --tendermint-rpc verified by a light client")
+	cmd.Flags().Int64(flagCosmosTrustHeight, 0, "Specify the height of the header the light client trusts at first")
+	cmd.Flags().String(flagCosmosTrustHash, "", "Specify the hash of the header the light client trusts at first")
+	cmd.Flags().Duration(flagCosmosTrustPeriod, 168*time.Hour, "The period the headers verified by the light client can be trusted for; must be shorter than the unbonding period")
+	cmd.Flags().StringSlice(flagCosmosWitnesses, nil, "Specify (optional) additional Tendermint RPC endpoints the light client cross-checks the headers with")
+	cmd.Flags().AddFlagSet(ethereumFinalityFlagSet())
 	cmd.Flags().AddFlagSet(ethereumOptsFlagSet())
 
 	return cmd
 }
 
+// newLightProver returns the prover of the Cosmos state used by --cosmos-light-client, which starts from the trusted
+// header given on the command line.
+func newLightProver(
+	ctx context.Context,
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	cosmosChainID string,
+	tmRPCEndpoint string,
+) (lightclient.StateProver, error) {
+	trustHeight := konfig.Int64(flagCosmosTrustHeight)
+	trustHash, err := hex.DecodeString(konfig.String(flagCosmosTrustHash))
+	if err != nil || trustHeight <= 0 || len(trustHash) == 0 {
+		return nil, fmt.Errorf(
+			"--%s needs a trusted header, set --%s and --%s",
+			flagCosmosLightClient,
+			flagCosmosTrustHeight,
+			flagCosmosTrustHash,
+		)
+	}
+
+	stateProver, err := lightclient.NewLightProver(
+		ctx,
+		logger,
+		cosmosChainID,
+		tmRPCEndpoint,
+		konfig.Strings(flagCosmosWitnesses),
+		light.TrustOptions{
+			Period: konfig.Duration(flagCosmosTrustPeriod),
+			Height: trustHeight,
+			Hash:   trustHash,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start the light client: %w", err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Verifying the Cosmos state with a light client from height %d\n", trustHeight)
+	return stateProver, nil
+}
+
+// dialQuorumMembers connects to the Ethereum RPC endpoints the Gravity events returned by the main endpoint are
+// cross-checked against.
+func dialQuorumMembers(
+	ethProvider provider.EVMProviderWithRet,
+	ethRPCEndpoint string,
+	quorumRPCEndpoints []string,
+) ([]provider.QuorumMember, error) {
+	members := []provider.QuorumMember{{Name: ethRPCEndpoint, Provider: ethProvider}}
+
+	for _, endpoint := range quorumRPCEndpoints {
+		rpcClient, err := ethrpc.Dial(endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial Ethereum RPC node %s: %w", endpoint, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", endpoint)
+		members = append(members, provider.QuorumMember{Name: endpoint, Provider: provider.NewEVMProvider(rpcClient)})
+	}
+
+	return members, nil
+}
+
+// newHeaderSource returns the source of the headers the receipt proofs are checked against: a dedicated endpoint if
+// one is given, otherwise a quorum of the cross-checking endpoints.
+func newHeaderSource(
+	logger zerolog.Logger,
+	headerRPCEndpoint string,
+	quorumMembers []provider.QuorumMember,
+	quorum int,
+) (receiptproof.HeaderReader, error) {
+	if headerRPCEndpoint != "" {
+		rpcClient, err := ethrpc.Dial(headerRPCEndpoint)
+		if err != nil {
+			return nil, fmt.Errorf("failed to dial Ethereum RPC node %s: %w", headerRPCEndpoint, err)
+		}
+
+		fmt.Fprintf(os.Stderr, "Connected to Ethereum header RPC: %s\n", headerRPCEndpoint)
+		return provider.NewEVMProvider(rpcClient), nil
+	}
+
+	if len(quorumMembers) > 0 {
+		return provider.NewQuorumHeaderReader(logger, quorumMembers, quorum)
+	}
+
+	return nil, fmt.Errorf(
+		"--%s needs an independent header source, set --%s or --%s",
+		flagEthVerifyReceipts,
+		flagEthHeaderRPC,
+		flagEthQuorumRPCs,
+	)
+}
+
+// newPrivateTxSender returns the sender of the Ethereum transactions to the private relay given by --eth-private-rpc.
+func newPrivateTxSender(konfig *koanf.Koanf, endpoint string) (committer.PrivateTxSender, error) {
+	var authKey *ecdsa.PrivateKey
+	if authKeyHex := konfig.String(flagEthPrivateRPCAuthKey); authKeyHex != "" {
+		var err error
+		if authKey, err = ethcrypto.HexToECDSA(strings.TrimPrefix(authKeyHex, "0x")); err != nil {
+			return nil, fmt.Errorf("failed to parse --%s: %w", flagEthPrivateRPCAuthKey, err)
+		}
+	}
+
+	privateTxSender, err := committer.NewPrivateTxSender(endpoint, konfig.String(flagEthPrivateRPCMethod), authKey)
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Fprintf(os.Stderr, "Sending Ethereum transactions to private relay: %s\n", endpoint)
+	return privateTxSender, nil
+}
+
+// newMempoolSource returns the source of the pending transactions to the Gravity contract, nil if the mempool isn't
+// watched.
+func newMempoolSource(
+	logger zerolog.Logger,
+	konfig *koanf.Koanf,
+	ethRPCEndpoint string,
+) (gravity.MempoolSource, error) {
+	alchemyWS := konfig.String(flagEthAlchemyWS)
+
+	sourceName := konfig.String(flagEthMempoolSource)
+	if sourceName == "" {
+		if alchemyWS == "" {
+			return nil, nil
+		}
+
+		sourceName = gravity.MempoolSourceAlchemy
+	}
+
+	endpoint := konfig.String(flagEthMempoolRPC)
+	if endpoint == "" {
+		endpoint = ethRPCEndpoint
+	}
+
+	if sourceName == gravity.MempoolSourceAlchemy {
+		if alchemyWS == "" {
+			return nil, fmt.Errorf("the %s mempool source needs --%s", sourceName, flagEthAlchemyWS)
+		}
+
+		endpoint = alchemyWS
+	}
+
+	rpcClient, err := ethrpc.Dial(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial Ethereum mempool RPC node %s: %w", endpoint, err)
+	}
+
+	switch sourceName {
+	case gravity.MempoolSourceAlchemy:
+		fmt.Fprintf(os.Stderr, "Watching the Ethereum mempool with Alchemy: %s\n", endpoint)
+		return gravity.NewAlchemyMempoolSource(rpcClient), nil
+
+	case gravity.MempoolSourceSubscribe:
+		fmt.Fprintf(os.Stderr, "Watching the Ethereum mempool with a pending transactions subscription: %s\n", endpoint)
+		return gravity.NewSubscriptionMempoolSource(logger, rpcClient), nil
+
+	case gravity.MempoolSourceTxPool:
+		fmt.Fprintf(os.Stderr, "Watching the Ethereum mempool with txpool_content: %s\n", endpoint)
+		return gravity.NewTxPoolMempoolSource(rpcClient, konfig.Duration(flagEthMempoolPollInterval)), nil
+
+	default:
+		rpcClient.Close()
+		return nil, fmt.Errorf(
+			"unsupported mempool source %s, must be %s, %s or %s",
+			sourceName,
+			gravity.MempoolSourceAlchemy,
+			gravity.MempoolSourceSubscribe,
+			gravity.MempoolSourceTxPool,
+		)
+	}
+}
+
 func trapSignal(cancel context.CancelFunc) {
 	var sigCh = make(chan os.Signal, 1)
 
@@ -0,0 +1,161 @@
+// nolint: lll
+package loran
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethrpc "github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+	"github.com/cicizeo/loran/cmd/loran/client"
+	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
+	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+	"github.com/cicizeo/loran/orchestrator/relayer"
+	"github.com/cicizeo/loran/orchestrator/watchtower"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+func getWatchtowerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "watchtower [gravity-addr]",
+		Args:  cobra.ExactArgs(1),
+		Short: "Starts a bridge watchtower",
+		Long: `Starts a bridge watchtower, continuously comparing the valsets, batches and event
+nonces of the Gravity contract with what the Cosmos chain attests. It raises an
+alert on valset updates Cosmos didn't produce, batches executed with nonces Cosmos
+never created and stalls in attestation. No key is needed, only read access to a
+Cosmos node and an Ethereum node.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			konfig, err := parseServerConfig(cmd)
+			if err != nil {
+				return err
+			}
+
+			logger, err := getLogger(cmd)
+			if err != nil {
+				return err
+			}
+
+			clientCtx, err := client.NewClientContext(konfig.String(flagCosmosChainID), "", nil)
+			if err != nil {
+				return err
+			}
+
+			cosmosGRPC := konfig.String(flagCosmosGRPC)
+			daemonClient, err := client.NewCosmosClient(clientCtx, logger, cosmosGRPC)
+			if err != nil {
+				return err
+			}
+			defer daemonClient.Close()
+
+			fmt.Fprintln(os.Stderr, "Waiting for cosmos gRPC service...")
+			time.Sleep(time.Second)
+
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			defer cancel()
+
+			gRPCConn := daemonClient.QueryClient()
+			waitForService(ctx, gRPCConn)
+
+			gravityQuerier := gravitytypes.NewQueryClient(gRPCConn)
+
+			gravityParams, err := getGravityParams(gRPCConn)
+			if err != nil {
+				return fmt.Errorf("failed to query for Gravity params: %w", err)
+			}
+
+			ethRPCEndpoint := konfig.String(flagEthRPC)
+			ethRPC, err := ethrpc.Dial(ethRPCEndpoint)
+			if err != nil {
+				return fmt.Errorf("failed to dial Ethereum RPC node: %w", err)
+			}
+
+			fmt.Fprintf(os.Stderr, "Connected to Ethereum RPC: %s\n", ethRPCEndpoint)
+			ethProvider := provider.NewEVMProvider(ethRPC)
+
+			// The watchtower never sends a transaction, the committer is only needed to call the contract.
+			ethCommitter, err := committer.NewEthCommitter(logger, ethcmn.Address{}, 1.0, 1.0, nil, ethProvider)
+			if err != nil {
+				return fmt.Errorf("failed to create Ethereum committer: %w", err)
+			}
+
+			gravityAddr := ethcmn.HexToAddress(args[0])
+
+			ethGravity, err := wrappers.NewGravity(gravityAddr, ethCommitter.Provider())
+			if err != nil {
+				return fmt.Errorf("failed to create a new instance of Gravity: %w", err)
+			}
+
+			gravityContract, err := gravity.NewGravityContract(logger, ethCommitter, gravityAddr, ethGravity)
+			if err != nil {
+				return fmt.Errorf("failed to create Gravity contract: %w", err)
+			}
+
+			finalityProfiles, err := parseFinalityProfiles(konfig)
+			if err != nil {
+				return err
+			}
+
+			// Only used to find and compare the latest valset of the contract, nothing is relayed.
+			valsetFinder := relayer.NewGravityRelayer(
+				logger,
+				gravityQuerier,
+				gravityContract,
+				false,
+				false,
+				false,
+				0,
+				0,
+				0,
+			)
+
+			var watchtowerOpts []func(watchtower.Watchtower)
+			if alertsFile := konfig.String(flagAlertsFile); alertsFile != "" {
+				watchtowerOpts = append(watchtowerOpts, watchtower.SetAlertsFile(alertsFile))
+			}
+
+			if alertWebhook := konfig.String(flagAlertWebhook); alertWebhook != "" {
+				watchtowerOpts = append(watchtowerOpts, watchtower.SetAlertWebhook(alertWebhook))
+			}
+
+			w := watchtower.NewWatchtower(
+				logger,
+				gravityQuerier,
+				clientCtx.InterfaceRegistry,
+				gravityContract,
+				valsetFinder,
+				finalityProfiles.ForChain(gravityParams.BridgeChainId),
+				konfig.Duration(flagWatchtowerInterval),
+				konfig.Int64(flagEthBlocksPerLoop),
+				konfig.Duration(flagAttestationStallTimeout),
+				watchtowerOpts...,
+			)
+
+			ctx, cancel = context.WithCancel(context.Background())
+			defer cancel()
+
+			// listen for and trap any OS signal to gracefully shutdown and exit
+			trapSignal(cancel)
+
+			logger.Info().Msg("starting watchtower...")
+			return w.Start(ctx)
+		},
+	}
+
+	cmd.Flags().String(flagCosmosChainID, "", "The chain ID of the cosmos network")
+	cmd.Flags().String(flagCosmosGRPC, "tcp://localhost:9090", "The gRPC endpoint of a cosmos node")
+	cmd.Flags().String(flagEthRPC, "http://localhost:8545", "Specify the RPC address of an Ethereum node")
+	cmd.Flags().Int64(flagEthBlocksPerLoop, 2000, "Maximum number of Ethereum blocks to check per loop; shrunk automatically if the provider rejects the range")
+	cmd.Flags().Duration(flagWatchtowerInterval, time.Minute, "Time between two checks of the bridge")
+	cmd.Flags().Duration(flagAttestationStallTimeout, time.Hour, "Time Cosmos may go without observing a pending Ethereum event before an alert is raised")
+	cmd.Flags().String(flagAlertsFile, "", "Set an (optional) file to append the alerts to, one JSON object per line")
+	cmd.Flags().String(flagAlertWebhook, "", "Set an (optional) URL to post every alert to as a JSON object")
+	cmd.Flags().AddFlagSet(ethereumFinalityFlagSet())
+
+	return cmd
+}
@@ -13,6 +13,7 @@ import (
 	common "github.com/ethereum/go-ethereum/common"
 	types "github.com/ethereum/go-ethereum/core/types"
 	gomock "github.com/golang/mock/gomock"
+	provider "github.com/cicizeo/loran/orchestrator/ethereum/provider"
 )
 
 // MockEVMProviderWithRet is a mock of EVMProviderWithRet interface.
@@ -38,6 +39,21 @@ func (m *MockEVMProviderWithRet) EXPECT() *MockEVMProviderWithRetMockRecorder {
 	return m.recorder
 }
 
+// BlockByHash mocks base method.
+func (m *MockEVMProviderWithRet) BlockByHash(arg0 context.Context, arg1 common.Hash) (*types.Block, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BlockByHash", arg0, arg1)
+	ret0, _ := ret[0].(*types.Block)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BlockByHash indicates an expected call of BlockByHash.
+func (mr *MockEVMProviderWithRetMockRecorder) BlockByHash(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BlockByHash", reflect.TypeOf((*MockEVMProviderWithRet)(nil).BlockByHash), arg0, arg1)
+}
+
 // CallContract mocks base method.
 func (m *MockEVMProviderWithRet) CallContract(arg0 context.Context, arg1 ethereum.CallMsg, arg2 *big.Int) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -53,6 +69,21 @@ func (mr *MockEVMProviderWithRetMockRecorder) CallContract(arg0, arg1, arg2 inte
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CallContract", reflect.TypeOf((*MockEVMProviderWithRet)(nil).CallContract), arg0, arg1, arg2)
 }
 
+// ChainID mocks base method.
+func (m *MockEVMProviderWithRet) ChainID(arg0 context.Context) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChainID", arg0)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChainID indicates an expected call of ChainID.
+func (mr *MockEVMProviderWithRetMockRecorder) ChainID(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChainID", reflect.TypeOf((*MockEVMProviderWithRet)(nil).ChainID), arg0)
+}
+
 // CodeAt mocks base method.
 func (m *MockEVMProviderWithRet) CodeAt(arg0 context.Context, arg1 common.Address, arg2 *big.Int) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -83,6 +114,21 @@ func (mr *MockEVMProviderWithRetMockRecorder) EstimateGas(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EstimateGas", reflect.TypeOf((*MockEVMProviderWithRet)(nil).EstimateGas), arg0, arg1)
 }
 
+// FeeHistory mocks base method.
+func (m *MockEVMProviderWithRet) FeeHistory(arg0 context.Context, arg1 uint64, arg2 *big.Int, arg3 []float64) (*provider.FeeHistory, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FeeHistory", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(*provider.FeeHistory)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FeeHistory indicates an expected call of FeeHistory.
+func (mr *MockEVMProviderWithRetMockRecorder) FeeHistory(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FeeHistory", reflect.TypeOf((*MockEVMProviderWithRet)(nil).FeeHistory), arg0, arg1, arg2, arg3)
+}
+
 // FilterLogs mocks base method.
 func (m *MockEVMProviderWithRet) FilterLogs(arg0 context.Context, arg1 ethereum.FilterQuery) ([]types.Log, error) {
 	m.ctrl.T.Helper()
@@ -113,6 +159,21 @@ func (mr *MockEVMProviderWithRetMockRecorder) HeaderByNumber(arg0, arg1 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HeaderByNumber", reflect.TypeOf((*MockEVMProviderWithRet)(nil).HeaderByNumber), arg0, arg1)
 }
 
+// NonceAt mocks base method.
+func (m *MockEVMProviderWithRet) NonceAt(arg0 context.Context, arg1 common.Address, arg2 *big.Int) (uint64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NonceAt", arg0, arg1, arg2)
+	ret0, _ := ret[0].(uint64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NonceAt indicates an expected call of NonceAt.
+func (mr *MockEVMProviderWithRetMockRecorder) NonceAt(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NonceAt", reflect.TypeOf((*MockEVMProviderWithRet)(nil).NonceAt), arg0, arg1, arg2)
+}
+
 // PendingCodeAt mocks base method.
 func (m *MockEVMProviderWithRet) PendingCodeAt(arg0 context.Context, arg1 common.Address) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -13,6 +13,7 @@ import (
 	types "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	common "github.com/ethereum/go-ethereum/common"
 	gomock "github.com/golang/mock/gomock"
+	committer "github.com/cicizeo/loran/orchestrator/ethereum/committer"
 	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
 	provider "github.com/cicizeo/loran/orchestrator/ethereum/provider"
 )
@@ -54,6 +55,21 @@ func (mr *MockContractMockRecorder) Address() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Address", reflect.TypeOf((*MockContract)(nil).Address))
 }
 
+// EncodeLogicCall mocks base method.
+func (m *MockContract) EncodeLogicCall(arg0 context.Context, arg1 types.Valset, arg2 types.OutgoingLogicCall, arg3 []types.MsgConfirmLogicCall) ([]byte, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "EncodeLogicCall", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]byte)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// EncodeLogicCall indicates an expected call of EncodeLogicCall.
+func (mr *MockContractMockRecorder) EncodeLogicCall(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "EncodeLogicCall", reflect.TypeOf((*MockContract)(nil).EncodeLogicCall), arg0, arg1, arg2, arg3)
+}
+
 // EncodeTransactionBatch mocks base method.
 func (m *MockContract) EncodeTransactionBatch(arg0 context.Context, arg1 types.Valset, arg2 types.OutgoingTxBatch, arg3 []types.MsgConfirmBatch) ([]byte, error) {
 	m.ctrl.T.Helper()
@@ -159,6 +175,36 @@ func (mr *MockContractMockRecorder) GetGravityID(arg0, arg1 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetGravityID", reflect.TypeOf((*MockContract)(nil).GetGravityID), arg0, arg1)
 }
 
+// GetLastEventNonce mocks base method.
+func (m *MockContract) GetLastEventNonce(arg0 context.Context, arg1 common.Address, arg2 *big.Int) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLastEventNonce", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLastEventNonce indicates an expected call of GetLastEventNonce.
+func (mr *MockContractMockRecorder) GetLastEventNonce(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLastEventNonce", reflect.TypeOf((*MockContract)(nil).GetLastEventNonce), arg0, arg1, arg2)
+}
+
+// GetLogicCallNonce mocks base method.
+func (m *MockContract) GetLogicCallNonce(arg0 context.Context, arg1 []byte, arg2 common.Address) (*big.Int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLogicCallNonce", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*big.Int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLogicCallNonce indicates an expected call of GetLogicCallNonce.
+func (mr *MockContractMockRecorder) GetLogicCallNonce(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLogicCallNonce", reflect.TypeOf((*MockContract)(nil).GetLogicCallNonce), arg0, arg1, arg2)
+}
+
 // GetPendingTxInputList mocks base method.
 func (m *MockContract) GetPendingTxInputList() *gravity.PendingTxInputList {
 	m.ctrl.T.Helper()
@@ -231,6 +277,21 @@ func (mr *MockContractMockRecorder) Provider() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Provider", reflect.TypeOf((*MockContract)(nil).Provider))
 }
 
+// RevertReason mocks base method.
+func (m *MockContract) RevertReason(arg0 context.Context, arg1 *committer.TxOutcome) (*gravity.RevertError, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevertReason", arg0, arg1)
+	ret0, _ := ret[0].(*gravity.RevertError)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RevertReason indicates an expected call of RevertReason.
+func (mr *MockContractMockRecorder) RevertReason(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevertReason", reflect.TypeOf((*MockContract)(nil).RevertReason), arg0, arg1)
+}
+
 // SendTx mocks base method.
 func (m *MockContract) SendTx(arg0 context.Context, arg1 common.Address, arg2 []byte, arg3 uint64, arg4 *big.Int) (common.Hash, error) {
 	m.ctrl.T.Helper()
@@ -246,8 +307,23 @@ func (mr *MockContractMockRecorder) SendTx(arg0, arg1, arg2, arg3, arg4 interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SendTx", reflect.TypeOf((*MockContract)(nil).SendTx), arg0, arg1, arg2, arg3, arg4)
 }
 
+// SimulateTx mocks base method.
+func (m *MockContract) SimulateTx(arg0 context.Context, arg1 []byte, arg2 uint64) (*gravity.RevertError, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SimulateTx", arg0, arg1, arg2)
+	ret0, _ := ret[0].(*gravity.RevertError)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SimulateTx indicates an expected call of SimulateTx.
+func (mr *MockContractMockRecorder) SimulateTx(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SimulateTx", reflect.TypeOf((*MockContract)(nil).SimulateTx), arg0, arg1, arg2)
+}
+
 // SubscribeToPendingTxs mocks base method.
-func (m *MockContract) SubscribeToPendingTxs(arg0 context.Context, arg1 string) error {
+func (m *MockContract) SubscribeToPendingTxs(arg0 context.Context, arg1 gravity.MempoolSource) error {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "SubscribeToPendingTxs", arg0, arg1)
 	ret0, _ := ret[0].(error)
@@ -259,3 +335,32 @@ func (mr *MockContractMockRecorder) SubscribeToPendingTxs(arg0, arg1 interface{}
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeToPendingTxs", reflect.TypeOf((*MockContract)(nil).SubscribeToPendingTxs), arg0, arg1)
 }
+
+// TrackPendingTxs mocks base method.
+func (m *MockContract) TrackPendingTxs(arg0 context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TrackPendingTxs", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TrackPendingTxs indicates an expected call of TrackPendingTxs.
+func (mr *MockContractMockRecorder) TrackPendingTxs(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TrackPendingTxs", reflect.TypeOf((*MockContract)(nil).TrackPendingTxs), arg0)
+}
+
+// TxOutcome mocks base method.
+func (m *MockContract) TxOutcome(arg0 common.Hash) (*committer.TxOutcome, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TxOutcome", arg0)
+	ret0, _ := ret[0].(*committer.TxOutcome)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TxOutcome indicates an expected call of TxOutcome.
+func (mr *MockContractMockRecorder) TxOutcome(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TxOutcome", reflect.TypeOf((*MockContract)(nil).TxOutcome), arg0)
+}
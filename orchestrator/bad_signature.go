@@ -0,0 +1,186 @@
+package orchestrator
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+// batchNotFoundErr is the error the Cosmos chain returns for a batch it doesn't have.
+const batchNotFoundErr = "Can not find tx batch"
+
+// reportForgedCheckpoints looks at the valset updates and batches executed on Ethereum for checkpoints the Cosmos
+// chain never produced. Validators who signed such a checkpoint helped hijack the bridge, so evidence of their
+// signatures is submitted for them to be slashed. It never fails the oracle loop, the claims are sent regardless.
+func (p *gravityOrchestrator) reportForgedCheckpoints(
+	ctx context.Context,
+	withdraws []*wrappers.GravityTransactionBatchExecutedEvent,
+	valsetUpdates []*wrappers.GravityValsetUpdatedEvent,
+) {
+	if len(withdraws) == 0 && len(valsetUpdates) == 0 {
+		return
+	}
+
+	gravityID, err := p.gravityContract.GetGravityID(ctx, p.gravityContract.FromAddress())
+	if err != nil {
+		p.logger.Err(err).Msg("failed to get GravityID from Ethereum contract; forged checkpoints not checked")
+		return
+	}
+
+	for _, ev := range valsetUpdates {
+		if err := p.checkSubmission(ctx, gravityID, ev.Raw.TxHash); err != nil {
+			p.logger.Err(err).
+				Uint64("valset_nonce", ev.NewValsetNonce.Uint64()).
+				Str("tx_hash", ev.Raw.TxHash.Hex()).
+				Msg("failed to check the valset update for a forged checkpoint")
+		}
+	}
+
+	for _, ev := range withdraws {
+		if err := p.checkSubmission(ctx, gravityID, ev.Raw.TxHash); err != nil {
+			p.logger.Err(err).
+				Uint64("batch_nonce", ev.BatchNonce.Uint64()).
+				Str("tx_hash", ev.Raw.TxHash.Hex()).
+				Msg("failed to check the batch for a forged checkpoint")
+		}
+	}
+}
+
+// checkSubmission decodes the valset update or batch submitted by a transaction and reports the signatures over
+// its checkpoint if it is forged.
+func (p *gravityOrchestrator) checkSubmission(ctx context.Context, gravityID string, txHash ethcmn.Hash) error {
+	tx, _, err := p.ethProvider.TransactionByHash(ctx, txHash)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the transaction")
+	}
+
+	if tx.To() == nil || *tx.To() != p.gravityContract.Address() {
+		// Called through another contract, the signatures can't be told from the transaction input.
+		p.logger.Debug().Str("tx_hash", txHash.Hex()).Msg("Gravity contract not called directly, skipping")
+		return nil
+	}
+
+	submission, err := gravity.DecodeSubmission(tx.Data())
+	if err != nil {
+		return errors.Wrap(err, "failed to decode the transaction input")
+	}
+
+	var (
+		subject    types.EthereumSigned
+		checkpoint ethcmn.Hash
+		forged     bool
+	)
+
+	if submission.Valset != nil {
+		subject = submission.Valset
+		checkpoint = gravity.EncodeValsetConfirm(gravityID, *submission.Valset)
+
+		forged, err = p.isValsetForged(ctx, gravityID, *submission.Valset, checkpoint)
+	} else {
+		// The sender of a transfer isn't part of the checkpoint but the chain rejects a batch without a valid one.
+		for i := range submission.Batch.Transactions {
+			submission.Batch.Transactions[i].Sender = p.gravityBroadcastClient.AccFromAddress().String()
+		}
+
+		subject = submission.Batch
+		checkpoint = gravity.EncodeTxBatchConfirm(gravityID, *submission.Batch)
+
+		forged, err = p.isBatchForged(ctx, gravityID, *submission.Batch, checkpoint)
+	}
+
+	if err != nil || !forged || p.reportedCheckpoints[checkpoint] {
+		return err
+	}
+
+	p.logger.Error().
+		Str("tx_hash", txHash.Hex()).
+		Str("checkpoint", checkpoint.Hex()).
+		Int("num_signatures", len(submission.Signatures)).
+		Msg("CHECKPOINT FORGERY DETECTED: Ethereum executed a valset or batch the Cosmos chain never produced; " +
+			"submitting bad signature evidence")
+
+	for i, signature := range submission.Signatures {
+		// The contract stops checking once enough power signed, so only submit the signatures we can verify.
+		signer, err := types.EthAddressFromSignature(checkpoint.Bytes(), append([]byte(nil), signature...))
+		if err != nil || signer.GetAddress() != submission.Signers[i].Hex() {
+			p.logger.Warn().
+				Str("signer", submission.Signers[i].Hex()).
+				Msg("signature over the forged checkpoint doesn't match its signer, skipping")
+			continue
+		}
+
+		if err := p.gravityBroadcastClient.SendBadSignatureEvidence(ctx, subject, signature); err != nil {
+			return errors.Wrapf(err, "failed to submit the bad signature evidence of %s", signer.GetAddress())
+		}
+
+		p.logger.Info().Str("signer", signer.GetAddress()).Msg("submitted bad signature evidence")
+	}
+
+	p.reportedCheckpoints[checkpoint] = true
+
+	return nil
+}
+
+// isValsetForged tells if the Cosmos chain produced a different valset for the nonce, or if the nonce is beyond the
+// latest valset of the chain. Old valsets may be pruned, so a missing one below the latest nonce is not evidence.
+func (p *gravityOrchestrator) isValsetForged(
+	ctx context.Context,
+	gravityID string,
+	valset types.Valset,
+	checkpoint ethcmn.Hash,
+) (bool, error) {
+	resp, err := p.cosmosQueryClient.ValsetRequest(ctx, &types.QueryValsetRequestRequest{Nonce: valset.Nonce})
+	if err != nil {
+		return false, errors.Wrapf(err, "failed to get valset %d", valset.Nonce)
+	}
+
+	if resp != nil && resp.Valset != nil {
+		return gravity.EncodeValsetConfirm(gravityID, *resp.Valset) != checkpoint, nil
+	}
+
+	latest, err := p.cosmosQueryClient.LastValsetRequests(ctx, &types.QueryLastValsetRequestsRequest{})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to get the latest valsets")
+	}
+
+	for _, v := range latest.Valsets {
+		if v.Nonce >= valset.Nonce {
+			p.logger.Warn().Uint64("valset_nonce", valset.Nonce).Msg("valset pruned, can't check its checkpoint")
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+// isBatchForged tells if the Cosmos chain has a different batch for the nonce. A missing batch is not evidence: once
+// a batch is executed, Cosmos removes it together with all the older batches of the token.
+func (p *gravityOrchestrator) isBatchForged(
+	ctx context.Context,
+	gravityID string,
+	batch types.OutgoingTxBatch,
+	checkpoint ethcmn.Hash,
+) (bool, error) {
+	resp, err := p.cosmosQueryClient.BatchRequestByNonce(ctx, &types.QueryBatchRequestByNonceRequest{
+		Nonce:           batch.BatchNonce,
+		ContractAddress: batch.TokenContract,
+	})
+	if err != nil && !strings.Contains(err.Error(), batchNotFoundErr) {
+		return false, errors.Wrapf(err, "failed to get batch %d", batch.BatchNonce)
+	}
+
+	if err != nil || resp == nil || resp.Batch.BatchNonce != batch.BatchNonce {
+		p.logger.Debug().
+			Uint64("batch_nonce", batch.BatchNonce).
+			Str("token_contract", batch.TokenContract).
+			Msg("batch not on Cosmos, can't check its checkpoint")
+		return false, nil
+	}
+
+	return gravity.EncodeTxBatchConfirm(gravityID, resp.Batch) != checkpoint, nil
+}
@@ -0,0 +1,83 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+)
+
+func TestIsBatchForged(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	tokenContract := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	batch := types.OutgoingTxBatch{BatchNonce: 10, TokenContract: tokenContract, BatchTimeout: 100}
+	checkpoint := gravity.EncodeTxBatchConfirm("gravity", batch)
+
+	batchRequest := &types.QueryBatchRequestByNonceRequest{Nonce: 10, ContractAddress: tokenContract}
+	notFound := errors.New("rpc error: code = Unknown desc = Can not find tx batch: unknown request")
+
+	newOrch := func(mockCtrl *gomock.Controller) (*gravityOrchestrator, *mocks.MockQueryClient) {
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+
+		return &gravityOrchestrator{logger: logger, cosmosQueryClient: mockQClient}, mockQClient
+	}
+
+	t.Run("same batch on Cosmos", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockQClient := newOrch(mockCtrl)
+		mockQClient.EXPECT().BatchRequestByNonce(gomock.Any(), batchRequest).
+			Return(&types.QueryBatchRequestByNonceResponse{Batch: batch}, nil)
+
+		forged, err := orch.isBatchForged(context.Background(), "gravity", batch, checkpoint)
+		assert.NoError(t, err)
+		assert.False(t, forged)
+	})
+
+	t.Run("different batch on Cosmos", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockQClient := newOrch(mockCtrl)
+		other := batch
+		other.BatchTimeout = 200
+		mockQClient.EXPECT().BatchRequestByNonce(gomock.Any(), batchRequest).
+			Return(&types.QueryBatchRequestByNonceResponse{Batch: other}, nil)
+
+		forged, err := orch.isBatchForged(context.Background(), "gravity", batch, checkpoint)
+		assert.NoError(t, err)
+		assert.True(t, forged)
+	})
+
+	t.Run("executed batch removed, queue empty", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockQClient := newOrch(mockCtrl)
+		mockQClient.EXPECT().BatchRequestByNonce(gomock.Any(), batchRequest).Return(nil, notFound)
+
+		forged, err := orch.isBatchForged(context.Background(), "gravity", batch, checkpoint)
+		assert.NoError(t, err)
+		assert.False(t, forged)
+	})
+
+	t.Run("query failure", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockQClient := newOrch(mockCtrl)
+		mockQClient.EXPECT().BatchRequestByNonce(gomock.Any(), batchRequest).
+			Return(nil, errors.New("connection refused"))
+
+		_, err := orch.isBatchForged(context.Background(), "gravity", batch, checkpoint)
+		assert.EqualError(t, err, "failed to get batch 10: connection refused")
+	})
+}
@@ -0,0 +1,179 @@
+package blockrange
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// quietLogs is the number of logs under which a query is considered quiet. It is far below the limits of the
+	// hosted providers (usually 10000 logs).
+	quietLogs = 1000
+
+	// quietQueriesToGrow is the number of consecutive quiet queries after which the window is doubled.
+	quietQueriesToGrow = 3
+)
+
+// rangeErrors are (lowercase) fragments of the errors returned by the providers when a log query covers too many
+// blocks or returns too many logs.
+var rangeErrors = []string{
+	"query returned more than",  // Infura, Geth based providers
+	"response size exceeded",    // Alchemy
+	"log response size",         // Alchemy
+	"block range",               // Ankr, BSC, Polygon, QuickNode: "exceed maximum block range", "block range is too wide"
+	"range limit",               // "range limit exceeded"
+	"limited to a",              // QuickNode: "eth_getLogs is limited to a 10,000 range"
+	"too many results",          // Erigon, Nethermind
+	"query timeout exceeded",    // Nodes giving up on dense ranges
+	"exceeds the maximum range", // Cloudflare
+}
+
+// IsRangeErr returns true if the error means the provider rejected a log query because its range is too large.
+func IsRangeErr(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range rangeErrors {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// QueryFn returns the query for the logs emitted between fromBlock and toBlock (both inclusive).
+type QueryFn func(fromBlock, toBlock uint64) ethereum.FilterQuery
+
+// Sizer adapts the number of blocks covered by a single log query. The window shrinks when the provider rejects a
+// range and grows back, up to the configured maximum, during quiet periods.
+type Sizer struct {
+	logger zerolog.Logger
+
+	mtx          sync.Mutex
+	size         uint64
+	maxSize      uint64
+	quietQueries int
+}
+
+// NewSizer returns a Sizer starting at the maximum window size.
+func NewSizer(logger zerolog.Logger, maxSize uint64) *Sizer {
+	return &Sizer{
+		logger:  logger.With().Str("module", "block_range").Logger(),
+		size:    maxSize,
+		maxSize: maxSize,
+	}
+}
+
+// Size returns the number of blocks, on top of the first one, a query should cover.
+func (s *Sizer) Size() uint64 {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	return s.size
+}
+
+// FilterLogs fetches the logs emitted between fromBlock and toBlock. If the provider rejects the range, it is bisected
+// until every part is accepted (or a single block is rejected) and the window is shrunk for the next queries.
+func (s *Sizer) FilterLogs(
+	ctx context.Context,
+	filterer ethereum.LogFilterer,
+	query QueryFn,
+	fromBlock uint64,
+	toBlock uint64,
+) ([]ethtypes.Log, error) {
+	logs, err := filterer.FilterLogs(ctx, query(fromBlock, toBlock))
+	if err != nil {
+		return s.bisect(ctx, filterer, query, fromBlock, toBlock, err)
+	}
+
+	s.observe(len(logs))
+	return logs, nil
+}
+
+// bisect splits a rejected range in two halves and fetches them separately, bisecting them again if needed. The
+// queries made while bisecting don't count towards growing the window back.
+func (s *Sizer) bisect(
+	ctx context.Context,
+	filterer ethereum.LogFilterer,
+	query QueryFn,
+	fromBlock uint64,
+	toBlock uint64,
+	rangeErr error,
+) ([]ethtypes.Log, error) {
+	if !IsRangeErr(rangeErr) || fromBlock >= toBlock {
+		return nil, rangeErr
+	}
+
+	s.shrink(toBlock - fromBlock)
+
+	midBlock := fromBlock + (toBlock-fromBlock)/2
+
+	s.logger.Warn().
+		Err(rangeErr).
+		Uint64("start", fromBlock).
+		Uint64("end", toBlock).
+		Uint64("new_size", s.Size()).
+		Msg("log query range rejected by the provider; bisecting")
+
+	logs := []ethtypes.Log{}
+	for _, r := range [][2]uint64{{fromBlock, midBlock}, {midBlock + 1, toBlock}} {
+		part, err := filterer.FilterLogs(ctx, query(r[0], r[1]))
+		if err != nil {
+			part, err = s.bisect(ctx, filterer, query, r[0], r[1], err)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		logs = append(logs, part...)
+	}
+
+	return logs, nil
+}
+
+// shrink halves the window, based on the size of the range that got rejected.
+func (s *Sizer) shrink(rejectedSize uint64) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	s.quietQueries = 0
+
+	if newSize := rejectedSize / 2; newSize < s.size {
+		s.size = newSize
+	}
+}
+
+// observe grows the window after a few consecutive quiet queries.
+func (s *Sizer) observe(numLogs int) {
+	s.mtx.Lock()
+	defer s.mtx.Unlock()
+
+	if numLogs >= quietLogs {
+		s.quietQueries = 0
+		return
+	}
+
+	s.quietQueries++
+	if s.quietQueries < quietQueriesToGrow || s.size == s.maxSize {
+		return
+	}
+
+	s.quietQueries = 0
+	s.size *= 2
+	if s.size == 0 {
+		s.size = 1
+	}
+	if s.size > s.maxSize {
+		s.size = s.maxSize
+	}
+
+	s.logger.Debug().Uint64("new_size", s.size).Msg("growing log query range")
+}
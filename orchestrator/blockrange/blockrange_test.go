@@ -0,0 +1,134 @@
+package blockrange
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+)
+
+func testQuery(fromBlock, toBlock uint64) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+	}
+}
+
+func TestIsRangeErr(t *testing.T) {
+	assert.True(t, IsRangeErr(errors.New("query returned more than 10000 results")))
+	assert.True(t, IsRangeErr(errors.New("Log response size exceeded. You can make eth_getLogs requests with up to a 2K block range")))
+	assert.True(t, IsRangeErr(errors.New("exceed maximum block range: 5000")))
+	assert.True(t, IsRangeErr(errors.New("eth_getLogs is limited to a 10,000 range")))
+	assert.False(t, IsRangeErr(errors.New("unknown block")))
+	assert.False(t, IsRangeErr(nil))
+}
+
+func TestFilterLogs(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(0, 100)).Return([]ethtypes.Log{{BlockNumber: 1}}, nil)
+
+		s := NewSizer(logger, 100)
+
+		logs, err := s.FilterLogs(context.Background(), ethProvider, testQuery, 0, 100)
+		assert.Nil(t, err)
+		assert.Len(t, logs, 1)
+		assert.Equal(t, uint64(100), s.Size())
+	})
+
+	t.Run("bisect", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		tooManyResults := errors.New("query returned more than 10000 results")
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		gomock.InOrder(
+			ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(0, 100)).Return(nil, tooManyResults),
+			ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(0, 50)).Return(nil, tooManyResults),
+			ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(0, 25)).Return([]ethtypes.Log{{BlockNumber: 1}}, nil),
+			ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(26, 50)).Return([]ethtypes.Log{{BlockNumber: 30}}, nil),
+			ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(51, 100)).Return([]ethtypes.Log{{BlockNumber: 60}}, nil),
+		)
+
+		s := NewSizer(logger, 100)
+
+		logs, err := s.FilterLogs(context.Background(), ethProvider, testQuery, 0, 100)
+		assert.Nil(t, err)
+		assert.Equal(t, []ethtypes.Log{{BlockNumber: 1}, {BlockNumber: 30}, {BlockNumber: 60}}, logs)
+		assert.Equal(t, uint64(25), s.Size())
+	})
+
+	t.Run("single block rejected", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		tooManyResults := errors.New("query returned more than 10000 results")
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(0, 1)).Return(nil, tooManyResults)
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(0, 0)).Return(nil, tooManyResults)
+
+		s := NewSizer(logger, 100)
+
+		_, err := s.FilterLogs(context.Background(), ethProvider, testQuery, 0, 1)
+		assert.EqualError(t, err, "query returned more than 10000 results")
+	})
+
+	t.Run("other error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), testQuery(0, 100)).Return(nil, errors.New("some error"))
+
+		s := NewSizer(logger, 100)
+
+		_, err := s.FilterLogs(context.Background(), ethProvider, testQuery, 0, 100)
+		assert.EqualError(t, err, "some error")
+		assert.Equal(t, uint64(100), s.Size())
+	})
+}
+
+func TestSizerGrow(t *testing.T) {
+	s := NewSizer(zerolog.Nop(), 100)
+
+	s.shrink(10)
+	assert.Equal(t, uint64(5), s.Size())
+
+	// a busy query doesn't count as quiet
+	s.observe(quietLogs)
+	s.observe(0)
+	s.observe(0)
+	assert.Equal(t, uint64(5), s.Size())
+
+	s.observe(0)
+	assert.Equal(t, uint64(10), s.Size())
+
+	for i := 0; i < quietQueriesToGrow*10; i++ {
+		s.observe(0)
+	}
+	assert.Equal(t, uint64(100), s.Size())
+
+	// shrinking down to a single block still allows growing back
+	s.shrink(1)
+	assert.Equal(t, uint64(0), s.Size())
+
+	for i := 0; i < quietQueriesToGrow; i++ {
+		s.observe(0)
+	}
+	assert.Equal(t, uint64(1), s.Size())
+}
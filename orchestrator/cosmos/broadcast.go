@@ -6,8 +6,10 @@ import (
 	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/gogo/protobuf/proto"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 	"github.com/cicizeo/loran/cmd/loran/client"
@@ -43,6 +45,7 @@ type GravityBroadcastClient interface {
 		withdraws []*wrappers.GravityTransactionBatchExecutedEvent,
 		valsetUpdates []*wrappers.GravityValsetUpdatedEvent,
 		erc20Deployed []*wrappers.GravityERC20DeployedEvent,
+		logicCalls []*wrappers.GravityLogicCallEvent,
 		loopDuration time.Duration,
 	) error
 
@@ -51,6 +54,14 @@ type GravityBroadcastClient interface {
 		ctx context.Context,
 		denom string,
 	) error
+
+	// SendBadSignatureEvidence broadcasts a signature over a valset or a batch that was never produced on the chain, so
+	// that the validator who signed it gets slashed.
+	SendBadSignatureEvidence(
+		ctx context.Context,
+		subject types.EthereumSigned,
+		signature []byte,
+	) error
 }
 
 type (
@@ -71,6 +82,7 @@ type (
 		TransactionBatchExecutedEvent *wrappers.GravityTransactionBatchExecutedEvent
 		ValsetUpdateEvent             *wrappers.GravityValsetUpdatedEvent
 		ERC20DeployedEvent            *wrappers.GravityERC20DeployedEvent
+		LogicCallEvent                *wrappers.GravityLogicCallEvent
 	}
 )
 
@@ -183,6 +195,7 @@ func (s *gravityBroadcastClient) SendEthereumClaims(
 	withdraws []*wrappers.GravityTransactionBatchExecutedEvent,
 	valsetUpdates []*wrappers.GravityValsetUpdatedEvent,
 	erc20Deployed []*wrappers.GravityERC20DeployedEvent,
+	logicCalls []*wrappers.GravityLogicCallEvent,
 	cosmosBlockTime time.Duration,
 ) error {
 	allevents := []sortableEvent{}
@@ -225,6 +238,15 @@ func (s *gravityBroadcastClient) SendEthereumClaims(
 		}
 	}
 
+	for _, ev := range logicCalls {
+		if ev.EventNonce.Uint64() > lastClaimEvent {
+			allevents = append(allevents, sortableEvent{
+				EventNonce:     ev.EventNonce.Uint64(),
+				LogicCallEvent: ev,
+			})
+		}
+	}
+
 	return s.broadcastEthereumEvents(allevents)
 }
 
@@ -254,6 +276,41 @@ func (s *gravityBroadcastClient) SendRequestBatch(
 	return nil
 }
 
+func (s *gravityBroadcastClient) SendBadSignatureEvidence(
+	ctx context.Context,
+	subject types.EthereumSigned,
+	signature []byte,
+) error {
+	// MsgSubmitBadSignatureEvidence
+	// this is a message anyone can send with a signature over a checkpoint of a
+	// valset, a batch or a logic call that the chain never produced, usually seen
+	// on Ethereum. The handler rejects it if the checkpoint ever existed,
+	// otherwise it recovers the signer and slashes the validator behind it.
+	// -------------
+	protoSubject, ok := subject.(proto.Message)
+	if !ok {
+		return errors.Errorf("unsupported evidence subject %T", subject)
+	}
+
+	anyWithValue, err := codectypes.NewAnyWithValue(protoSubject)
+	if err != nil {
+		err = errors.Wrap(err, "failed to pack the evidence subject")
+		return err
+	}
+
+	msg := &types.MsgSubmitBadSignatureEvidence{
+		Subject:   anyWithValue,
+		Signature: ethcmn.Bytes2Hex(signature),
+		Sender:    s.AccFromAddress().String(),
+	}
+	if err = s.broadcastClient.QueueBroadcastMsg(msg); err != nil {
+		err = errors.Wrap(err, "broadcasting MsgSubmitBadSignatureEvidence failed")
+		return err
+	}
+
+	return nil
+}
+
 func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent) error {
 	msgs := []sdk.Msg{}
 
@@ -267,6 +324,7 @@ func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent)
 		"withdraw":      0,
 		"valset_update": 0,
 		"erc20_deploy":  0,
+		"logic_call":    0,
 	}
 
 	// iterate through events and send them sequentially.
@@ -328,6 +386,16 @@ func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent)
 			})
 			evCounter["erc20_deploy"]++
 
+		case ev.LogicCallEvent != nil:
+			msgs = append(msgs, &types.MsgLogicCallExecutedClaim{
+				EventNonce:        ev.LogicCallEvent.EventNonce.Uint64(),
+				BlockHeight:       ev.LogicCallEvent.Raw.BlockNumber,
+				InvalidationId:    ev.LogicCallEvent.InvalidationId[:],
+				InvalidationNonce: ev.LogicCallEvent.InvalidationNonce.Uint64(),
+				Orchestrator:      s.AccFromAddress().String(),
+			})
+			evCounter["logic_call"]++
+
 		}
 	}
 
@@ -336,6 +404,7 @@ func (s *gravityBroadcastClient) broadcastEthereumEvents(events []sortableEvent)
 		Int("num_transaction_batch_executed", evCounter["transaction_batch_executed"]).
 		Int("num_valset_update", evCounter["valset_update"]).
 		Int("num_erc20_deploy", evCounter["erc20_deploy"]).
+		Int("num_logic_call", evCounter["logic_call"]).
 		Int("num_total_claims", len(events)).
 		Msg("oracle observed events; sending claims")
 
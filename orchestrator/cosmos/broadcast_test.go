@@ -225,6 +225,14 @@ func (m *hasBiggerNonce) Matches(input interface{}) bool {
 		}
 	}
 
+	logicCall, ok := input.(*types.MsgLogicCallExecutedClaim)
+	if ok {
+		if logicCall.EventNonce > m.currentNonce {
+			m.currentNonce = logicCall.EventNonce
+			return true
+		}
+	}
+
 	return false
 }
 
@@ -301,12 +309,20 @@ func TestSendEthereumClaims(t *testing.T) {
 		},
 	}
 
+	logicCalls := []*wrappers.GravityLogicCallEvent{
+		{
+			EventNonce:        big.NewInt(9),
+			InvalidationNonce: big.NewInt(1),
+		},
+	}
+
 	s.SendEthereumClaims(context.Background(),
 		0,
 		deposits,
 		withdraws,
 		valsetUpdates,
 		erc20Deployed,
+		logicCalls,
 		time.Microsecond,
 	)
 }
@@ -380,6 +396,7 @@ func TestSendEthereumClaimsIgnoreNonSequentialNonces(t *testing.T) {
 		withdraws,
 		valsetUpdates,
 		erc20Deployed,
+		nil,
 		time.Microsecond,
 	)
 }
@@ -0,0 +1,107 @@
+package lightclient
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	storetypes "github.com/cosmos/cosmos-sdk/store/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/tendermint/tendermint/light"
+	lrpc "github.com/tendermint/tendermint/light/rpc"
+	dbs "github.com/tendermint/tendermint/light/store/db"
+	rpcclient "github.com/tendermint/tendermint/rpc/client"
+	rpchttp "github.com/tendermint/tendermint/rpc/client/http"
+	dbm "github.com/tendermint/tm-db"
+)
+
+// StateProver reads values of the committed Cosmos state together with their proofs.
+type StateProver interface {
+	// LatestHeight returns the latest height whose state can be proven right away.
+	LatestHeight(ctx context.Context) (int64, error)
+
+	// Prove returns the value of a key of a store at the given height, or nil if the key is proven to be absent.
+	Prove(ctx context.Context, storeName string, key []byte, height int64) ([]byte, error)
+}
+
+type lightProver struct {
+	lc  *light.Client
+	rpc *lrpc.Client
+}
+
+// NewLightProver returns a StateProver checking every ABCI query proof of the primary Tendermint RPC endpoint
+// against the app hash of a header verified by a light client. The light client starts from the trusted header and
+// cross-checks the headers of the primary with the witnesses; if none is given, the primary is its own witness.
+func NewLightProver(
+	ctx context.Context,
+	logger zerolog.Logger,
+	chainID string,
+	primary string,
+	witnesses []string,
+	trustOptions light.TrustOptions,
+) (StateProver, error) {
+	if len(witnesses) == 0 {
+		logger.Warn().Msg("no light client witness given, headers are only verified against the primary")
+		witnesses = []string{primary}
+	}
+
+	lc, err := light.NewHTTPClient(
+		ctx,
+		chainID,
+		trustOptions,
+		primary,
+		witnesses,
+		dbs.New(dbm.NewMemDB(), chainID),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create the light client")
+	}
+
+	next, err := rpchttp.New(primary, "/websocket")
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create Tendermint RPC client")
+	}
+
+	rpc := lrpc.NewClient(next, lc, lrpc.KeyPathFn(lrpc.DefaultMerkleKeyPathFn()))
+	rpc.RegisterOpDecoder(storetypes.ProofOpIAVLCommitment, storetypes.CommitmentOpDecoder)
+	rpc.RegisterOpDecoder(storetypes.ProofOpSimpleMerkleCommitment, storetypes.CommitmentOpDecoder)
+
+	return &lightProver{
+		lc:  lc,
+		rpc: rpc,
+	}, nil
+}
+
+func (p *lightProver) LatestHeight(ctx context.Context) (int64, error) {
+	if _, err := p.lc.Update(ctx, time.Now()); err != nil {
+		return 0, errors.Wrap(err, "failed to update the light client")
+	}
+
+	height, err := p.lc.LastTrustedHeight()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get the last trusted height")
+	}
+
+	// The app hash of a height is in the header of the next one, so the state of the last trusted height can't be
+	// proven until another block is verified.
+	return height - 1, nil
+}
+
+func (p *lightProver) Prove(ctx context.Context, storeName string, key []byte, height int64) ([]byte, error) {
+	res, err := p.rpc.ABCIQueryWithOptions(
+		ctx,
+		fmt.Sprintf("/store/%s/key", storeName),
+		key,
+		rpcclient.ABCIQueryOptions{Height: height, Prove: true},
+	)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to prove key %X of store %s", key, storeName)
+	}
+
+	if res.Response.Height != height {
+		return nil, errors.Errorf("asked for a proof at height %d but got one at %d", height, res.Response.Height)
+	}
+
+	return res.Response.Value, nil
+}
@@ -0,0 +1,212 @@
+package lightclient
+
+import (
+	"bytes"
+	"context"
+	"strconv"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	paramstypes "github.com/cosmos/cosmos-sdk/x/params/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+var _ types.QueryClient = (*QueryClient)(nil)
+
+// QueryClient is a Gravity query client that proves the pending valsets, the pending batches and the params it
+// returns to be in the committed state, so the signer never signs what a lying node makes up. Every query is pinned
+// to the latest height the prover can prove, and each returned item is compared with the value proven at that
+// height. The items a node holds back can't be detected this way. Any other query is passed through unverified.
+type QueryClient struct {
+	types.QueryClient
+
+	logger zerolog.Logger
+	prover StateProver
+	amino  *codec.LegacyAmino
+}
+
+func NewQueryClient(logger zerolog.Logger, queryClient types.QueryClient, prover StateProver) *QueryClient {
+	return &QueryClient{
+		QueryClient: queryClient,
+		logger:      logger.With().Str("module", "light_client").Logger(),
+		prover:      prover,
+		amino:       codec.NewLegacyAmino(),
+	}
+}
+
+func (q *QueryClient) Params(
+	ctx context.Context,
+	in *types.QueryParamsRequest,
+	opts ...grpc.CallOption,
+) (*types.QueryParamsResponse, error) {
+	ctx, height, err := q.pinHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := q.QueryClient.Params(ctx, in, opts...)
+	if err != nil || res == nil {
+		return res, err
+	}
+
+	// Start from a deep copy of what was returned, as the fields out of the param set are not in the state.
+	var proven types.Params
+	if bz, err := res.Params.Marshal(); err != nil {
+		return nil, err
+	} else if err := proven.Unmarshal(bz); err != nil {
+		return nil, err
+	}
+
+	for _, pair := range proven.ParamSetPairs() {
+		key := append([]byte(types.DefaultParamspace+"/"), pair.Key...)
+
+		value, err := q.prove(ctx, paramstypes.StoreKey, key, height)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prove param %s", pair.Key)
+		}
+
+		if err := q.amino.UnmarshalJSON(value, pair.Value); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode param %s", pair.Key)
+		}
+	}
+
+	if err := q.compare(&proven, &res.Params); err != nil {
+		return nil, errors.Wrapf(err, "params at height %d", height)
+	}
+
+	return res, nil
+}
+
+func (q *QueryClient) LastPendingValsetRequestByAddr(
+	ctx context.Context,
+	in *types.QueryLastPendingValsetRequestByAddrRequest,
+	opts ...grpc.CallOption,
+) (*types.QueryLastPendingValsetRequestByAddrResponse, error) {
+	ctx, height, err := q.pinHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := q.QueryClient.LastPendingValsetRequestByAddr(ctx, in, opts...)
+	if err != nil || res == nil {
+		return res, err
+	}
+
+	for i := range res.Valsets {
+		valset := &res.Valsets[i]
+
+		value, err := q.prove(ctx, types.StoreKey, []byte(types.GetValsetKey(valset.Nonce)), height)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prove valset %d", valset.Nonce)
+		}
+
+		var proven types.Valset
+		if err := proven.Unmarshal(value); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode valset %d", valset.Nonce)
+		}
+
+		if err := q.compare(&proven, valset); err != nil {
+			return nil, errors.Wrapf(err, "valset %d at height %d", valset.Nonce, height)
+		}
+	}
+
+	return res, nil
+}
+
+func (q *QueryClient) LastPendingBatchRequestByAddr(
+	ctx context.Context,
+	in *types.QueryLastPendingBatchRequestByAddrRequest,
+	opts ...grpc.CallOption,
+) (*types.QueryLastPendingBatchRequestByAddrResponse, error) {
+	ctx, height, err := q.pinHeight(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := q.QueryClient.LastPendingBatchRequestByAddr(ctx, in, opts...)
+	if err != nil || res == nil {
+		return res, err
+	}
+
+	for i := range res.Batch {
+		batch := &res.Batch[i]
+
+		tokenContract, err := types.NewEthAddress(batch.TokenContract)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid token contract of batch %d", batch.BatchNonce)
+		}
+
+		value, err := q.prove(ctx, types.StoreKey, []byte(types.GetOutgoingTxBatchKey(*tokenContract, batch.BatchNonce)), height)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to prove batch %d of %s", batch.BatchNonce, batch.TokenContract)
+		}
+
+		var proven types.OutgoingTxBatch
+		if err := proven.Unmarshal(value); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode batch %d of %s", batch.BatchNonce, batch.TokenContract)
+		}
+
+		if err := q.compare(&proven, batch); err != nil {
+			return nil, errors.Wrapf(err, "batch %d of %s at height %d", batch.BatchNonce, batch.TokenContract, height)
+		}
+	}
+
+	return res, nil
+}
+
+// pinHeight asks the node to answer the query at the latest height we can prove.
+func (q *QueryClient) pinHeight(ctx context.Context) (context.Context, int64, error) {
+	height, err := q.prover.LatestHeight(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return metadata.AppendToOutgoingContext(ctx, grpctypes.GRPCBlockHeightHeader, strconv.FormatInt(height, 10)), height, nil
+}
+
+// prove returns the proven value of a key that must be in the committed state.
+func (q *QueryClient) prove(ctx context.Context, storeName string, key []byte, height int64) ([]byte, error) {
+	value, err := q.prover.Prove(ctx, storeName, key, height)
+	if err != nil {
+		return nil, err
+	}
+
+	if value == nil {
+		q.logger.Error().
+			Int64("height", height).
+			Str("store", storeName).
+			Hex("key", key).
+			Msg("LIGHT CLIENT VERIFICATION FAILED: the node returned something absent from the committed state")
+
+		return nil, errors.New("not in the committed state")
+	}
+
+	return value, nil
+}
+
+type message interface {
+	Marshal() ([]byte, error)
+}
+
+func (q *QueryClient) compare(proven, returned message) error {
+	provenBz, err := proven.Marshal()
+	if err != nil {
+		return err
+	}
+
+	returnedBz, err := returned.Marshal()
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(provenBz, returnedBz) {
+		q.logger.Error().Msg("LIGHT CLIENT VERIFICATION FAILED: the node returned something else than the committed state")
+		return errors.New("does not match the committed state")
+	}
+
+	return nil
+}
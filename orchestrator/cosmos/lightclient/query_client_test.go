@@ -0,0 +1,233 @@
+package lightclient_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cosmos/cosmos-sdk/codec"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	grpctypes "github.com/cosmos/cosmos-sdk/types/grpc"
+	paramstypes "github.com/cosmos/cosmos-sdk/x/params/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/cosmos/lightclient"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// fakeProver proves the values of an in-memory state at a single height.
+type fakeProver struct {
+	height int64
+	state  map[string][]byte
+}
+
+func (p *fakeProver) LatestHeight(_ context.Context) (int64, error) {
+	return p.height, nil
+}
+
+func (p *fakeProver) Prove(_ context.Context, storeName string, key []byte, height int64) ([]byte, error) {
+	if height != p.height {
+		return nil, fmt.Errorf("no proof at height %d", height)
+	}
+
+	return p.state[storeName+"/"+string(key)], nil
+}
+
+func (p *fakeProver) set(storeName string, key []byte, value []byte) {
+	p.state[storeName+"/"+string(key)] = value
+}
+
+func TestQueryClient(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	orchAddress := "gravity1ahx7f8wyertuus9r20284ej0asrs085ceqtfnm"
+	tokenContract := "0xC783df8a850f42e7F7e57013759C285caa701eB6"
+
+	valset := types.Valset{
+		Nonce: 3,
+		Members: []types.BridgeValidator{
+			{Power: 2863311530, EthereumAddress: "0x3bdf8428734244c9e5d82c95d125081939d6d42d"},
+			{Power: 1431655765, EthereumAddress: "0xd8da6bf26964af9d7eed9e03e53415d37aa96045"},
+		},
+		Height:       100,
+		RewardAmount: sdk.NewInt(0),
+	}
+
+	batch := types.OutgoingTxBatch{
+		BatchNonce:    7,
+		BatchTimeout:  1000,
+		TokenContract: tokenContract,
+		Block:         90,
+		Transactions: []types.OutgoingTransferTx{
+			{
+				Id:          1,
+				Sender:      orchAddress,
+				DestAddress: "0xd8da6bf26964af9d7eed9e03e53415d37aa96045",
+				Erc20Token:  types.ERC20Token{Contract: tokenContract, Amount: sdk.NewInt(100)},
+				Erc20Fee:    types.ERC20Token{Contract: tokenContract, Amount: sdk.NewInt(1)},
+			},
+		},
+	}
+
+	params := *types.DefaultParams()
+	params.GravityId = "gravity-test"
+	params.BridgeChainId = 5
+
+	newProver := func(t *testing.T) *fakeProver {
+		prover := &fakeProver{height: 42, state: map[string][]byte{}}
+
+		valsetBz, err := valset.Marshal()
+		assert.NoError(t, err)
+		prover.set(types.StoreKey, []byte(types.GetValsetKey(valset.Nonce)), valsetBz)
+
+		batchBz, err := batch.Marshal()
+		assert.NoError(t, err)
+		tokenAddress, err := types.NewEthAddress(tokenContract)
+		assert.NoError(t, err)
+		prover.set(types.StoreKey, []byte(types.GetOutgoingTxBatchKey(*tokenAddress, batch.BatchNonce)), batchBz)
+
+		amino := codec.NewLegacyAmino()
+		for _, pair := range params.ParamSetPairs() {
+			bz, err := amino.MarshalJSON(pair.Value)
+			assert.NoError(t, err)
+			prover.set(paramstypes.StoreKey, append([]byte(types.DefaultParamspace+"/"), pair.Key...), bz)
+		}
+
+		return prover
+	}
+
+	// assertPinned checks that the query is asked at the height of the prover.
+	assertPinned := func(t *testing.T, ctx context.Context) {
+		md, ok := metadata.FromOutgoingContext(ctx)
+		assert.True(t, ok)
+		assert.Equal(t, []string{"42"}, md.Get(grpctypes.GRPCBlockHeightHeader))
+	}
+
+	t.Run("committed valsets", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockQClient.EXPECT().LastPendingValsetRequestByAddr(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *types.QueryLastPendingValsetRequestByAddrRequest, _ ...grpc.CallOption) (*types.QueryLastPendingValsetRequestByAddrResponse, error) {
+				assertPinned(t, ctx)
+				return &types.QueryLastPendingValsetRequestByAddrResponse{Valsets: []types.Valset{valset}}, nil
+			},
+		)
+
+		qc := lightclient.NewQueryClient(logger, mockQClient, newProver(t))
+		res, err := qc.LastPendingValsetRequestByAddr(
+			context.Background(),
+			&types.QueryLastPendingValsetRequestByAddrRequest{Address: orchAddress},
+		)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.Valset{valset}, res.Valsets)
+	})
+
+	t.Run("forged valset", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		forged := valset
+		forged.Members = []types.BridgeValidator{
+			{Power: 4294967295, EthereumAddress: "0x000000000000000000000000000000000000dEaD"},
+		}
+
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockQClient.EXPECT().LastPendingValsetRequestByAddr(gomock.Any(), gomock.Any()).
+			Return(&types.QueryLastPendingValsetRequestByAddrResponse{Valsets: []types.Valset{forged}}, nil)
+
+		qc := lightclient.NewQueryClient(logger, mockQClient, newProver(t))
+		_, err := qc.LastPendingValsetRequestByAddr(
+			context.Background(),
+			&types.QueryLastPendingValsetRequestByAddrRequest{Address: orchAddress},
+		)
+		assert.EqualError(t, err, "valset 3 at height 42: does not match the committed state")
+	})
+
+	t.Run("made up valset", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		madeUp := valset
+		madeUp.Nonce = 4
+
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockQClient.EXPECT().LastPendingValsetRequestByAddr(gomock.Any(), gomock.Any()).
+			Return(&types.QueryLastPendingValsetRequestByAddrResponse{Valsets: []types.Valset{valset, madeUp}}, nil)
+
+		qc := lightclient.NewQueryClient(logger, mockQClient, newProver(t))
+		_, err := qc.LastPendingValsetRequestByAddr(
+			context.Background(),
+			&types.QueryLastPendingValsetRequestByAddrRequest{Address: orchAddress},
+		)
+		assert.EqualError(t, err, "failed to prove valset 4: not in the committed state")
+	})
+
+	t.Run("batches", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		forged := batch
+		forged.Transactions = []types.OutgoingTransferTx{batch.Transactions[0]}
+		forged.Transactions[0].DestAddress = "0x000000000000000000000000000000000000dEaD"
+
+		forgedFee := batch
+		forgedFee.Transactions = []types.OutgoingTransferTx{batch.Transactions[0]}
+		forgedFee.Transactions[0].Erc20Fee.Contract = "0x000000000000000000000000000000000000dEaD"
+
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockQClient.EXPECT().LastPendingBatchRequestByAddr(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *types.QueryLastPendingBatchRequestByAddrRequest, _ ...grpc.CallOption) (*types.QueryLastPendingBatchRequestByAddrResponse, error) {
+				assertPinned(t, ctx)
+				return &types.QueryLastPendingBatchRequestByAddrResponse{Batch: []types.OutgoingTxBatch{batch}}, nil
+			},
+		)
+		mockQClient.EXPECT().LastPendingBatchRequestByAddr(gomock.Any(), gomock.Any()).
+			Return(&types.QueryLastPendingBatchRequestByAddrResponse{Batch: []types.OutgoingTxBatch{forged}}, nil)
+		mockQClient.EXPECT().LastPendingBatchRequestByAddr(gomock.Any(), gomock.Any()).
+			Return(&types.QueryLastPendingBatchRequestByAddrResponse{Batch: []types.OutgoingTxBatch{forgedFee}}, nil)
+
+		qc := lightclient.NewQueryClient(logger, mockQClient, newProver(t))
+		req := &types.QueryLastPendingBatchRequestByAddrRequest{Address: orchAddress}
+
+		res, err := qc.LastPendingBatchRequestByAddr(context.Background(), req)
+		assert.NoError(t, err)
+		assert.Equal(t, []types.OutgoingTxBatch{batch}, res.Batch)
+
+		_, err = qc.LastPendingBatchRequestByAddr(context.Background(), req)
+		assert.EqualError(t, err, "batch 7 of "+tokenContract+" at height 42: does not match the committed state")
+		_, err = qc.LastPendingBatchRequestByAddr(context.Background(), req)
+		assert.EqualError(t, err, "batch 7 of "+tokenContract+" at height 42: does not match the committed state")
+	})
+
+	t.Run("params", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		forged := params
+		forged.BridgeChainId = 1
+
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockQClient.EXPECT().Params(gomock.Any(), gomock.Any()).DoAndReturn(
+			func(ctx context.Context, _ *types.QueryParamsRequest, _ ...grpc.CallOption) (*types.QueryParamsResponse, error) {
+				assertPinned(t, ctx)
+				return &types.QueryParamsResponse{Params: params}, nil
+			},
+		)
+		mockQClient.EXPECT().Params(gomock.Any(), gomock.Any()).Return(&types.QueryParamsResponse{Params: forged}, nil)
+
+		qc := lightclient.NewQueryClient(logger, mockQClient, newProver(t))
+
+		res, err := qc.Params(context.Background(), &types.QueryParamsRequest{})
+		assert.NoError(t, err)
+		assert.Equal(t, params, res.Params)
+
+		_, err = qc.Params(context.Background(), &types.QueryParamsRequest{})
+		assert.EqualError(t, err, "params at height 42: does not match the committed state")
+	})
+}
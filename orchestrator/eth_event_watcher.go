@@ -4,10 +4,13 @@ import (
 	"context"
 	"strings"
 
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/finality"
 	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
 )
 
@@ -16,24 +19,21 @@ import (
 func (p *gravityOrchestrator) CheckForEvents(
 	ctx context.Context,
 	startingBlock uint64,
-	ethBlockConfirmationDelay uint64,
+	finalityStrategy finality.Strategy,
 ) (currentBlock uint64, err error) {
 
-	latestHeader, err := p.ethProvider.HeaderByNumber(ctx, nil)
+	// only scan blocks that are final, to ensure minimum confirmations are received
+	currentBlock, err = finalityStrategy.LatestFinalBlock(ctx, p.ethProvider)
 	if err != nil {
-		err = errors.Wrap(err, "failed to get latest header")
 		return 0, err
 	}
 
-	// add delay to ensure minimum confirmations are received and block is finalized
-	currentBlock = latestHeader.Number.Uint64() - ethBlockConfirmationDelay
-
 	if currentBlock < startingBlock {
 		return currentBlock, nil
 	}
 
-	if (currentBlock - startingBlock) > p.ethBlocksPerLoop {
-		currentBlock = startingBlock + p.ethBlocksPerLoop
+	if blocksPerLoop := p.blockRange.Size(); (currentBlock - startingBlock) > blocksPerLoop {
+		currentBlock = startingBlock + blocksPerLoop
 	}
 
 	gravityFilterer, err := wrappers.NewGravityFilterer(p.gravityContract.Address(), p.ethProvider)
@@ -42,139 +42,42 @@ func (p *gravityOrchestrator) CheckForEvents(
 		return 0, err
 	}
 
-	var erc20DeployedEvents []*wrappers.GravityERC20DeployedEvent
-	{
-		iter, err := gravityFilterer.FilterERC20DeployedEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
-		}, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past ERC20Deployed events from Ethereum")
-
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past ERC20Deployed events from Ethereum")
-				return 0, err
-			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
-			}
-		}
-
-		for iter.Next() {
-			erc20DeployedEvents = append(erc20DeployedEvents, iter.Event)
-		}
-
-		iter.Close()
-	}
-
-	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(erc20DeployedEvents)).
-		Msg("scanned ERC20Deployed events from Ethereum")
-
-	var sendToCosmosEvents []*wrappers.GravitySendToCosmosEvent
-	{
-
-		iter, err := gravityFilterer.FilterSendToCosmosEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
-		}, nil, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past SendToCosmos events from Ethereum")
-
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past SendToCosmos events from Ethereum")
-				return 0, err
-			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
-			}
-		}
-
-		for iter.Next() {
-			sendToCosmosEvents = append(sendToCosmosEvents, iter.Event)
-		}
-
-		iter.Close()
+	var events eventindex.Events
+	fromIndex := eventindex.Covers(p.eventIndex, startingBlock, currentBlock)
+	if fromIndex {
+		events, err = p.indexedEvents(gravityFilterer, startingBlock, currentBlock)
+	} else {
+		events, err = p.filterEvents(ctx, gravityFilterer, startingBlock, currentBlock)
 	}
 
-	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(sendToCosmosEvents)).
-		Msg("scanned SendToCosmos events from Ethereum")
-
-	var transactionBatchExecutedEvents []*wrappers.GravityTransactionBatchExecutedEvent
-	{
-		iter, err := gravityFilterer.FilterTransactionBatchExecutedEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
-		}, nil, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past TransactionBatchExecuted events from Ethereum")
-
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past TransactionBatchExecuted events from Ethereum")
-				return 0, err
-			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
-			}
-		}
-
-		for iter.Next() {
-			transactionBatchExecutedEvents = append(transactionBatchExecutedEvents, iter.Event)
-		}
-
-		iter.Close()
+	if err != nil {
+		return 0, err
 	}
 
-	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(transactionBatchExecutedEvents)).
-		Msg("scanned TransactionBatchExecuted events from Ethereum")
-
-	var valsetUpdatedEvents []*wrappers.GravityValsetUpdatedEvent
-	{
-		iter, err := gravityFilterer.FilterValsetUpdatedEvent(&bind.FilterOpts{
-			Start: startingBlock,
-			End:   &currentBlock,
-		}, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", startingBlock).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past ValsetUpdatedEvent events from Ethereum")
-
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past ValsetUpdatedEvent events from Ethereum")
-				return 0, err
-			} else if iter == nil {
-				return 0, errors.New("no iterator returned")
+	// Make sure we don't build any claim from the logs of an orphaned block. If the chain reorganised under us we
+	// don't move forward, so the range gets scanned again on the next loop.
+	currentHeader, err := p.checkCanonicalLogs(ctx, startingBlock, currentBlock, events.Logs())
+	if orphanedErr, ok := err.(*orphanedLogError); ok {
+		p.logger.Error().
+			Err(orphanedErr).
+			Uint64("start", startingBlock).
+			Uint64("end", currentBlock).
+			Msg("CHAIN REORGANISATION DETECTED: found logs of an orphaned block; no claims sent, the range will be " +
+				"scanned again")
+
+		// The index would keep handing us the same orphaned logs, drop them so the range is read from Ethereum
+		// until the indexer catches up again.
+		if fromIndex {
+			if err := p.eventIndex.Truncate(orphanedErr.blockNumber); err != nil {
+				return 0, errors.Wrapf(err, "failed to drop the events of block %d from the event index", orphanedErr.blockNumber)
 			}
 		}
 
-		for iter.Next() {
-			valsetUpdatedEvents = append(valsetUpdatedEvents, iter.Event)
-		}
-
-		iter.Close()
+		return startingBlock, nil
+	} else if err != nil {
+		return 0, err
 	}
 
-	p.logger.Debug().
-		Uint64("start", startingBlock).
-		Uint64("end", currentBlock).
-		Int("num_events", len(valsetUpdatedEvents)).
-		Msg("scanned ValsetUpdatedEvents events from Ethereum")
-
 	// note that starting block overlaps with our last checked block, because we have to deal with
 	// the possibility that the relayer was killed after relaying only one of multiple events in a single
 	// block, so we also need this routine so make sure we don't send in the first event in this hypothetical
@@ -193,15 +96,28 @@ func (p *gravityOrchestrator) CheckForEvents(
 		return 0, errors.New("no last event response returned")
 	}
 
-	deposits := filterSendToCosmosEventsByNonce(sendToCosmosEvents, lastEventResp.EventNonce)
+	deposits := filterSendToCosmosEventsByNonce(events.SendToCosmos, lastEventResp.EventNonce)
 	withdraws := filterTransactionBatchExecutedEventsByNonce(
-		transactionBatchExecutedEvents,
+		events.TransactionBatchExecuted,
 		lastEventResp.EventNonce,
 	)
-	valsetUpdates := filterValsetUpdateEventsByNonce(valsetUpdatedEvents, lastEventResp.EventNonce)
-	deployedERC20Updates := filterERC20DeployedEventsByNonce(erc20DeployedEvents, lastEventResp.EventNonce)
+	valsetUpdates := filterValsetUpdateEventsByNonce(events.ValsetUpdated, lastEventResp.EventNonce)
+	deployedERC20Updates := filterERC20DeployedEventsByNonce(events.ERC20Deployed, lastEventResp.EventNonce)
+	logicCalls := filterLogicCallEventsByNonce(events.LogicCall, lastEventResp.EventNonce)
 
-	if len(deposits) > 0 || len(withdraws) > 0 || len(valsetUpdates) > 0 || len(deployedERC20Updates) > 0 {
+	p.recordObservedNonces(events, lastEventResp.EventNonce)
+
+	if err := p.verifyReceiptProofs(ctx, deposits, withdraws); err != nil {
+		return 0, err
+	}
+
+	p.reportForgedCheckpoints(ctx, withdraws, valsetUpdates)
+
+	if len(deposits) > 0 ||
+		len(withdraws) > 0 ||
+		len(valsetUpdates) > 0 ||
+		len(deployedERC20Updates) > 0 ||
+		len(logicCalls) > 0 {
 
 		if err := p.gravityBroadcastClient.SendEthereumClaims(
 			ctx,
@@ -210,6 +126,7 @@ func (p *gravityOrchestrator) CheckForEvents(
 			withdraws,
 			valsetUpdates,
 			deployedERC20Updates,
+			logicCalls,
 			p.cosmosBlockTime,
 		); err != nil {
 			err = errors.Wrap(err, "failed to send ethereum claims to Cosmos chain")
@@ -217,9 +134,111 @@ func (p *gravityOrchestrator) CheckForEvents(
 		}
 	}
 
+	p.recordScannedRange(startingBlock, currentBlock, currentHeader.Hash())
+
 	return currentBlock, nil
 }
 
+// verifyReceiptProofs makes sure the deposits and batch executions we're about to claim are part of a block the
+// independent header source knows about, so a lying provider can't make us attest to funds that were never sent.
+func (p *gravityOrchestrator) verifyReceiptProofs(
+	ctx context.Context,
+	deposits []*wrappers.GravitySendToCosmosEvent,
+	withdraws []*wrappers.GravityTransactionBatchExecutedEvent,
+) error {
+	if p.receiptVerifier == nil {
+		return nil
+	}
+
+	logs := make([]ethtypes.Log, 0, len(deposits)+len(withdraws))
+	for _, ev := range deposits {
+		logs = append(logs, ev.Raw)
+	}
+
+	for _, ev := range withdraws {
+		logs = append(logs, ev.Raw)
+	}
+
+	if err := p.receiptVerifier.VerifyLogs(ctx, logs); err != nil {
+		p.logger.Error().
+			Err(err).
+			Int("num_deposits", len(deposits)).
+			Int("num_withdraws", len(withdraws)).
+			Msg("RECEIPT PROOF VERIFICATION FAILED: the Ethereum provider returned logs that can't be proven; " +
+				"no claims sent")
+		return errors.Wrap(err, "failed to verify the receipt proofs of the Gravity events")
+	}
+
+	return nil
+}
+
+// filterEvents scans the Gravity contract events emitted between fromBlock and toBlock on Ethereum. All the event
+// types are fetched with a single query (bisected if the provider rejects the range) and then dispatched by topic.
+func (p *gravityOrchestrator) filterEvents(
+	ctx context.Context,
+	gravityFilterer *wrappers.GravityFilterer,
+	fromBlock uint64,
+	toBlock uint64,
+) (eventindex.Events, error) {
+	logs, err := p.blockRange.FilterLogs(ctx, p.ethProvider, func(fromBlock, toBlock uint64) ethereum.FilterQuery {
+		return eventindex.FilterQuery(p.gravityContract.Address(), fromBlock, toBlock)
+	}, fromBlock, toBlock)
+	if err != nil {
+		// The provider may lag behind the head it reported, the window is then scanned again on the next loop rather
+		// than skipped.
+		if isUnknownBlockErr(err) {
+			err = errors.Wrapf(err, "Ethereum provider doesn't know blocks %d to %d yet", fromBlock, toBlock)
+			return eventindex.Events{}, err
+		}
+
+		err = errors.Wrap(err, "failed to scan past Gravity events from Ethereum")
+		return eventindex.Events{}, err
+	}
+
+	events, err := eventindex.DecodeEvents(gravityFilterer, logs)
+	if err != nil {
+		return eventindex.Events{}, err
+	}
+
+	p.logger.Debug().
+		Uint64("start", fromBlock).
+		Uint64("end", toBlock).
+		Int("num_erc20_deployed", len(events.ERC20Deployed)).
+		Int("num_send_to_cosmos", len(events.SendToCosmos)).
+		Int("num_transaction_batch_executed", len(events.TransactionBatchExecuted)).
+		Int("num_valset_updated", len(events.ValsetUpdated)).
+		Int("num_logic_call", len(events.LogicCall)).
+		Msg("scanned Gravity events from Ethereum")
+
+	return events, nil
+}
+
+// indexedEvents reads the Gravity contract events emitted between fromBlock and toBlock from the local event index.
+func (p *gravityOrchestrator) indexedEvents(
+	gravityFilterer *wrappers.GravityFilterer,
+	fromBlock uint64,
+	toBlock uint64,
+) (eventindex.Events, error) {
+	logs, err := p.eventIndex.EventsInRange(fromBlock, toBlock)
+	if err != nil {
+		err = errors.Wrap(err, "failed to read Gravity events from the event index")
+		return eventindex.Events{}, err
+	}
+
+	events, err := eventindex.DecodeEvents(gravityFilterer, logs)
+	if err != nil {
+		return eventindex.Events{}, err
+	}
+
+	p.logger.Debug().
+		Uint64("start", fromBlock).
+		Uint64("end", toBlock).
+		Int("num_events", len(logs)).
+		Msg("read Gravity events from the event index")
+
+	return events, nil
+}
+
 func filterSendToCosmosEventsByNonce(
 	events []*wrappers.GravitySendToCosmosEvent,
 	nonce uint64,
@@ -278,6 +297,20 @@ func filterERC20DeployedEventsByNonce(
 	return res
 }
 
+func filterLogicCallEventsByNonce(
+	events []*wrappers.GravityLogicCallEvent,
+	nonce uint64,
+) []*wrappers.GravityLogicCallEvent {
+	res := make([]*wrappers.GravityLogicCallEvent, 0, len(events))
+
+	for _, ev := range events {
+		if ev.EventNonce.Uint64() > nonce {
+			res = append(res, ev)
+		}
+	}
+	return res
+}
+
 func isUnknownBlockErr(err error) bool {
 	// Geth error
 	if strings.Contains(err.Error(), "unknown block") {
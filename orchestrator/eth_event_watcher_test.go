@@ -22,6 +22,8 @@ import (
 	"github.com/cicizeo/loran/orchestrator/cosmos"
 	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
 	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/finality"
 	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
 )
 
@@ -37,21 +39,25 @@ func TestCheckForEvents(t *testing.T) {
 		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
 
 		lastBlock := uint64(95)
+		lastBlockHeader := &ethtypes.Header{Number: big.NewInt(95)}
+		eventBlockHeader := &ethtypes.Header{Number: big.NewInt(3)}
 
 		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
 		ethProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(0), nil)
 		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
 			Number: big.NewInt(100),
 		}, nil)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(95)).Return(lastBlockHeader, nil)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(3)).Return(eventBlockHeader, nil)
 
-		// FilterERC20DeployedEvent
+		// Gravity events
 		ethProvider.EXPECT().FilterLogs(
 			gomock.Any(),
 			MatchFilterQuery(ethereum.FilterQuery{
 				FromBlock: new(big.Int).SetUint64(1),
 				ToBlock:   new(big.Int).SetUint64(lastBlock),
 				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7")}, {}},
+				Topics:    [][]ethcmn.Hash{eventindex.Topics()},
 			})).
 			Return(
 				// The test data is from a real tx: https://goerli.etherscan.io/tx/0x09310b8dcc615b0baab5c0c41e9e7633f513c23532d0f191509d65e5a28b4ed7#eventlog
@@ -63,7 +69,7 @@ func TestCheckForEvents(t *testing.T) {
 						BlockNumber: 3,
 						TxHash:      ethcmn.HexToHash("0x0"),
 						TxIndex:     2,
-						BlockHash:   ethcmn.HexToHash("0x0"),
+						BlockHash:   eventBlockHeader.Hash(),
 						Index:       1,
 						Removed:     false,
 					},
@@ -71,48 +77,6 @@ func TestCheckForEvents(t *testing.T) {
 				nil,
 			).Times(1)
 
-		// FilterSendToCosmosEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(95),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x9e9794dbf94b0a0aa31a480f5b38550eda7f89115ac8fbf4953fa4dd219900c9")}, {}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// TransactionBatchExecutedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x02c7e81975f8edb86e2a0c038b7b86a49c744236abf0f6177ff5afc6986ab708")}, {}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// FilterValsetUpdatedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x76d08978c024a4bf8cbb30c67fd78fcaa1827cbc533e4e175f36d07e64ccf96a")}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
 		ethGasPriceAdjustment := 1.0
 		ethCommitter, _ := committer.NewEthCommitter(
 			logger,
@@ -165,12 +129,12 @@ func TestCheckForEvents(t *testing.T) {
 			0,
 		)
 
-		currentBlock, err := orch.CheckForEvents(context.Background(), 1, 5)
+		currentBlock, err := orch.CheckForEvents(context.Background(), 1, finality.FixedDepth(5))
 		assert.Nil(t, err)
 		assert.Equal(t, uint64(lastBlock), currentBlock)
 	})
 
-	t.Run("error on FilterERC20DeployedEvent", func(t *testing.T) {
+	t.Run("error on FilterLogs", func(t *testing.T) {
 
 		mockCtrl := gomock.NewController(t)
 		defer mockCtrl.Finish()
@@ -187,14 +151,14 @@ func TestCheckForEvents(t *testing.T) {
 			Number: big.NewInt(100),
 		}, nil)
 
-		// FilterERC20DeployedEvent
+		// Gravity events
 		ethProvider.EXPECT().FilterLogs(
 			gomock.Any(),
 			MatchFilterQuery(ethereum.FilterQuery{
 				FromBlock: new(big.Int).SetUint64(1),
 				ToBlock:   new(big.Int).SetUint64(lastBlock),
 				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7")}, {}},
+				Topics:    [][]ethcmn.Hash{eventindex.Topics()},
 			})).
 			Return(
 				nil,
@@ -245,12 +209,12 @@ func TestCheckForEvents(t *testing.T) {
 			0,
 		)
 
-		currentBlock, err := orch.CheckForEvents(context.Background(), 1, 5)
-		assert.EqualError(t, err, "failed to scan past ERC20Deployed events from Ethereum: some error")
+		currentBlock, err := orch.CheckForEvents(context.Background(), 1, finality.FixedDepth(5))
+		assert.EqualError(t, err, "failed to scan past Gravity events from Ethereum: some error")
 		assert.Equal(t, uint64(0), currentBlock)
 	})
 
-	t.Run("error on FilterSendToCosmosEvent", func(t *testing.T) {
+	t.Run("orphaned logs in the event index", func(t *testing.T) {
 
 		mockCtrl := gomock.NewController(t)
 		defer mockCtrl.Finish()
@@ -259,181 +223,40 @@ func TestCheckForEvents(t *testing.T) {
 		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
 		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
 
-		lastBlock := uint64(95)
+		lastBlockHeader := &ethtypes.Header{Number: big.NewInt(95)}
+		eventBlockHeader := &ethtypes.Header{Number: big.NewInt(3)}
 
 		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
 		ethProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(0), nil)
 		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
 			Number: big.NewInt(100),
 		}, nil)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(95)).Return(lastBlockHeader, nil)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(3)).Return(eventBlockHeader, nil)
 
-		// FilterERC20DeployedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7")}, {}},
-			})).
-			Return(
-				// The test data is from a real tx: https://goerli.etherscan.io/tx/0x09310b8dcc615b0baab5c0c41e9e7633f513c23532d0f191509d65e5a28b4ed7#eventlog
-				[]ethtypes.Log{
-					{
-						Address:     gravityAddress,
-						Topics:      []ethcmn.Hash{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7"), ethcmn.HexToHash("0x00000000000000000000000053cf531308195be45981e75d1c217a61358f2c27")},
-						Data:        hexutil.MustDecode("0x00000000000000000000000000000000000000000000000000000000000000a000000000000000000000000000000000000000000000000000000000000000e0000000000000000000000000000000000000000000000000000000000000012000000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000378000000000000000000000000000000000000000000000000000000000000000575756d65650000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d6565000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d656500000000000000000000000000000000000000000000000000000000"),
-						BlockNumber: 3,
-						TxHash:      ethcmn.HexToHash("0x0"),
-						TxIndex:     2,
-						BlockHash:   ethcmn.HexToHash("0x0"),
-						Index:       1,
-						Removed:     false,
-					},
-				},
-				nil,
-			).Times(1)
-
-		// FilterSendToCosmosEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(95),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x9e9794dbf94b0a0aa31a480f5b38550eda7f89115ac8fbf4953fa4dd219900c9")}, {}, {}},
-			})).
-			Return(
-				nil,
-				errors.New("some error"),
-			).Times(1)
+		// indexed before block 3 got reorganised
+		eventIndex, err := eventindex.NewBadgerIndex(logger, "")
+		assert.Nil(t, err)
+		defer eventIndex.Close()
+
+		assert.Nil(t, eventIndex.StoreEvents([]eventindex.Event{{
+			Nonce: 1,
+			Log: ethtypes.Log{
+				Address:     gravityAddress,
+				Topics:      []ethcmn.Hash{eventindex.TopicERC20Deployed, ethcmn.HexToHash("0x00000000000000000000000053cf531308195be45981e75d1c217a61358f2c27")},
+				Data:        hexutil.MustDecode("0x00000000000000000000000000000000000000000000000000000000000000a000000000000000000000000000000000000000000000000000000000000000e0000000000000000000000000000000000000000000000000000000000000012000000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000378000000000000000000000000000000000000000000000000000000000000000575756d65650000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d6565000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d656500000000000000000000000000000000000000000000000000000000"),
+				BlockNumber: 3,
+				TxHash:      ethcmn.HexToHash("0x0"),
+				TxIndex:     2,
+				BlockHash:   ethcmn.HexToHash("0x1"),
+				Index:       1,
+			},
+		}}, 1, 100))
 
-		ethGasPriceAdjustment := 1.0
 		ethCommitter, _ := committer.NewEthCommitter(
 			logger,
 			fromAddress,
-			ethGasPriceAdjustment,
 			1.0,
-			nil,
-			ethProvider,
-		)
-
-		gravityContract, _ := gravity.NewGravityContract(logger, ethCommitter, gravityAddress, nil)
-
-		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{}).AnyTimes()
-		mockPersonalSignFn := func(account ethcmn.Address, data []byte) (sig []byte, err error) {
-			return []byte{}, errors.New("some error during signing")
-		}
-
-		gravityBroadcastClient := cosmos.NewGravityBroadcastClient(
-			logger,
-			nil,
-			mockCosmos,
-			nil,
-			mockPersonalSignFn,
-			10,
-		)
-
-		mockQClient := mocks.NewMockQueryClient(mockCtrl)
-		orch := NewGravityOrchestrator(
-			logger,
-			mockQClient,
-			gravityBroadcastClient,
-			gravityContract,
-			fromAddress,
-			nil,
-			nil,
-			nil,
-			time.Second,
-			time.Second,
-			time.Second,
-			100,
-			0,
-		)
-
-		currentBlock, err := orch.CheckForEvents(context.Background(), 1, 5)
-		assert.EqualError(t, err, "failed to scan past SendToCosmos events from Ethereum: some error")
-		assert.Equal(t, uint64(0), currentBlock)
-	})
-
-	t.Run("error on FilterTransactionBatchExecutedEvent", func(t *testing.T) {
-
-		mockCtrl := gomock.NewController(t)
-		defer mockCtrl.Finish()
-
-		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
-		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
-		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
-
-		lastBlock := uint64(95)
-
-		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
-		ethProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(0), nil)
-		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
-			Number: big.NewInt(100),
-		}, nil)
-
-		// FilterERC20DeployedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7")}, {}},
-			})).
-			Return(
-				// The test data is from a real tx: https://goerli.etherscan.io/tx/0x09310b8dcc615b0baab5c0c41e9e7633f513c23532d0f191509d65e5a28b4ed7#eventlog
-				[]ethtypes.Log{
-					{
-						Address:     gravityAddress,
-						Topics:      []ethcmn.Hash{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7"), ethcmn.HexToHash("0x00000000000000000000000053cf531308195be45981e75d1c217a61358f2c27")},
-						Data:        hexutil.MustDecode("0x00000000000000000000000000000000000000000000000000000000000000a000000000000000000000000000000000000000000000000000000000000000e0000000000000000000000000000000000000000000000000000000000000012000000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000378000000000000000000000000000000000000000000000000000000000000000575756d65650000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d6565000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d656500000000000000000000000000000000000000000000000000000000"),
-						BlockNumber: 3,
-						TxHash:      ethcmn.HexToHash("0x0"),
-						TxIndex:     2,
-						BlockHash:   ethcmn.HexToHash("0x0"),
-						Index:       1,
-						Removed:     false,
-					},
-				},
-				nil,
-			).Times(1)
-
-		// FilterSendToCosmosEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(95),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x9e9794dbf94b0a0aa31a480f5b38550eda7f89115ac8fbf4953fa4dd219900c9")}, {}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// TransactionBatchExecutedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x02c7e81975f8edb86e2a0c038b7b86a49c744236abf0f6177ff5afc6986ab708")}, {}, {}},
-			})).
-			Return(
-				nil,
-				errors.New("some error"),
-			).Times(1)
-
-		ethGasPriceAdjustment := 1.0
-		ethCommitter, _ := committer.NewEthCommitter(
-			logger,
-			fromAddress,
-			ethGasPriceAdjustment,
 			1.0,
 			nil,
 			ethProvider,
@@ -457,141 +280,7 @@ func TestCheckForEvents(t *testing.T) {
 		)
 
 		mockQClient := mocks.NewMockQueryClient(mockCtrl)
-		orch := NewGravityOrchestrator(
-			logger,
-			mockQClient,
-			gravityBroadcastClient,
-			gravityContract,
-			fromAddress,
-			nil,
-			nil,
-			nil,
-			time.Second,
-			time.Second,
-			time.Second,
-			100,
-			0,
-		)
-
-		currentBlock, err := orch.CheckForEvents(context.Background(), 1, 5)
-		assert.EqualError(t, err, "failed to scan past TransactionBatchExecuted events from Ethereum: some error")
-		assert.Equal(t, uint64(0), currentBlock)
-	})
-
-	t.Run("error on FilterValsetUpdatedEvent", func(t *testing.T) {
-
-		mockCtrl := gomock.NewController(t)
-		defer mockCtrl.Finish()
-
-		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
-		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
-		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
-
-		lastBlock := uint64(95)
-
-		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
-		ethProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(0), nil)
-		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
-			Number: big.NewInt(100),
-		}, nil)
 
-		// FilterERC20DeployedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7")}, {}},
-			})).
-			Return(
-				// The test data is from a real tx: https://goerli.etherscan.io/tx/0x09310b8dcc615b0baab5c0c41e9e7633f513c23532d0f191509d65e5a28b4ed7#eventlog
-				[]ethtypes.Log{
-					{
-						Address:     gravityAddress,
-						Topics:      []ethcmn.Hash{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7"), ethcmn.HexToHash("0x00000000000000000000000053cf531308195be45981e75d1c217a61358f2c27")},
-						Data:        hexutil.MustDecode("0x00000000000000000000000000000000000000000000000000000000000000a000000000000000000000000000000000000000000000000000000000000000e0000000000000000000000000000000000000000000000000000000000000012000000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000378000000000000000000000000000000000000000000000000000000000000000575756d65650000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d6565000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d656500000000000000000000000000000000000000000000000000000000"),
-						BlockNumber: 3,
-						TxHash:      ethcmn.HexToHash("0x0"),
-						TxIndex:     2,
-						BlockHash:   ethcmn.HexToHash("0x0"),
-						Index:       1,
-						Removed:     false,
-					},
-				},
-				nil,
-			).Times(1)
-
-		// FilterSendToCosmosEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(95),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x9e9794dbf94b0a0aa31a480f5b38550eda7f89115ac8fbf4953fa4dd219900c9")}, {}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// TransactionBatchExecutedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x02c7e81975f8edb86e2a0c038b7b86a49c744236abf0f6177ff5afc6986ab708")}, {}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// FilterValsetUpdatedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(1),
-				ToBlock:   new(big.Int).SetUint64(lastBlock),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x76d08978c024a4bf8cbb30c67fd78fcaa1827cbc533e4e175f36d07e64ccf96a")}, {}},
-			})).
-			Return(
-				nil,
-				errors.New("some error"),
-			).Times(1)
-
-		ethGasPriceAdjustment := 1.0
-		ethCommitter, _ := committer.NewEthCommitter(
-			logger,
-			fromAddress,
-			ethGasPriceAdjustment,
-			1.0,
-			nil,
-			ethProvider,
-		)
-
-		gravityContract, _ := gravity.NewGravityContract(logger, ethCommitter, gravityAddress, nil)
-
-		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
-		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{}).AnyTimes()
-		mockPersonalSignFn := func(account ethcmn.Address, data []byte) (sig []byte, err error) {
-			return []byte{}, errors.New("some error during signing")
-		}
-
-		gravityBroadcastClient := cosmos.NewGravityBroadcastClient(
-			logger,
-			nil,
-			mockCosmos,
-			nil,
-			mockPersonalSignFn,
-			10,
-		)
-
-		mockQClient := mocks.NewMockQueryClient(mockCtrl)
 		orch := NewGravityOrchestrator(
 			logger,
 			mockQClient,
@@ -606,11 +295,19 @@ func TestCheckForEvents(t *testing.T) {
 			time.Second,
 			100,
 			0,
+			SetEventIndex(eventIndex),
 		)
 
-		currentBlock, err := orch.CheckForEvents(context.Background(), 1, 5)
-		assert.EqualError(t, err, "failed to scan past ValsetUpdatedEvent events from Ethereum: some error")
-		assert.Equal(t, uint64(0), currentBlock)
+		currentBlock, err := orch.CheckForEvents(context.Background(), 1, finality.FixedDepth(5))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(1), currentBlock)
+
+		// the orphaned block is dropped from the index, so the range is scanned on Ethereum next time
+		_, lastIndexedBlock, ok, err := eventIndex.IndexedRange()
+		assert.Nil(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(2), lastIndexedBlock)
+		assert.False(t, eventindex.Covers(eventIndex, 1, 95))
 	})
 }
 
@@ -666,6 +363,19 @@ func TestFilterERC20DeployedEventsByNonce(t *testing.T) {
 	assert.Len(t, filterERC20DeployedEventsByNonce(testEv, nonce), 2)
 }
 
+func TestFilterLogicCallEventsByNonce(t *testing.T) {
+	// In testEv we'll add 2 valid and 1 past event.
+	// This should result in only 2 events after the filter.
+	testEv := []*wrappers.GravityLogicCallEvent{
+		{EventNonce: big.NewInt(3)},
+		{EventNonce: big.NewInt(4)},
+		{EventNonce: big.NewInt(5)},
+	}
+	nonce := uint64(3)
+
+	assert.Len(t, filterLogicCallEventsByNonce(testEv, nonce), 2)
+}
+
 func TestIsUnknownBlockErr(t *testing.T) {
 	gethErr := errors.New("unknown block")
 	assert.True(t, isUnknownBlockErr(gethErr))
@@ -25,11 +25,52 @@ type EVMCommitter interface {
 		gasPrice *big.Int,
 	) (txHash ethcmn.Hash, err error)
 
+	// EstimateGas returns the gas limit and the gas price of a transaction. With dynamic fees, the gas price is the
+	// base fee of the next block plus the priority fee, which is what a unit of gas is expected to cost; SendTx sets
+	// the max fee itself.
 	EstimateGas(
 		ctx context.Context,
 		recipient ethcmn.Address,
 		txData []byte,
 	) (gasCost uint64, gasPrice *big.Int, err error)
+
+	// TrackPendingTxs follows the transactions sent until their nonce is used, replacing the ones stuck for too long
+	// with a higher gas price. It runs until the context is done.
+	TrackPendingTxs(ctx context.Context) error
+
+	// TxOutcome returns the outcome of a transaction sent by SendTx, looked up by its hash or the hash of any of its
+	// replacements. It returns ErrTxPending while the transaction isn't mined and ErrTxUnknown if the transaction
+	// wasn't sent by this committer or its outcome was forgotten.
+	TxOutcome(txHash ethcmn.Hash) (*TxOutcome, error)
+}
+
+var (
+	// ErrTxPending is returned by TxOutcome while the nonce of the transaction isn't used.
+	ErrTxPending = errors.New("transaction pending")
+
+	// ErrTxUnknown is returned by TxOutcome for a transaction the committer doesn't know about.
+	ErrTxUnknown = errors.New("unknown transaction")
+)
+
+// FeeMode is the kind of transactions sent by the committer.
+type FeeMode string
+
+const (
+	// FeeModeLegacy sends legacy transactions with a single gas price, for chains without EIP-1559.
+	FeeModeLegacy FeeMode = "legacy"
+
+	// FeeModeDynamic sends EIP-1559 dynamic-fee transactions, with a max fee and a priority fee.
+	FeeModeDynamic FeeMode = "dynamic"
+)
+
+// ParseFeeMode returns the fee mode with the given name.
+func ParseFeeMode(str string) (FeeMode, error) {
+	switch mode := FeeMode(str); mode {
+	case FeeModeLegacy, FeeModeDynamic:
+		return mode, nil
+	default:
+		return "", errors.Errorf("unsupported fee mode %s, must be %s or %s", str, FeeModeLegacy, FeeModeDynamic)
+	}
 }
 
 type EVMCommitterOption func(o *options) error
@@ -38,14 +79,34 @@ type options struct {
 	GasPrice   decimal.Decimal
 	GasLimit   uint64
 	RPCTimeout time.Duration
+	FeeMode    FeeMode
+
+	// TxPollInterval is the time between two checks of the pending transactions.
+	TxPollInterval time.Duration
+	// ResendAfterBlocks is the number of blocks a transaction may stay pending before it is replaced, zero disables
+	// the replacements.
+	ResendAfterBlocks uint64
+	// GasPriceBump is the percentage the gas price of a replacement is increased by.
+	GasPriceBump uint64
+	// MaxGasPrice caps the gas price of the replacements, nil for no cap.
+	MaxGasPrice *big.Int
+	// PrivateTxSender sends the transactions to a private relay, nil to send them to the public mempool.
+	PrivateTxSender PrivateTxSender
+	// PrivateTxFallbackBlocks is the number of blocks a private transaction may stay pending before it is sent to the
+	// public mempool.
+	PrivateTxFallbackBlocks uint64
 }
 
 func defaultOptions() *options {
 	v, _ := decimal.NewFromString("20")
 	return &options{
-		GasPrice:   v.Shift(9), // 20 gwei
-		GasLimit:   1500000,
-		RPCTimeout: 10 * time.Second,
+		GasPrice:          v.Shift(9), // 20 gwei
+		GasLimit:          1500000,
+		RPCTimeout:        10 * time.Second,
+		FeeMode:           FeeModeLegacy,
+		TxPollInterval:    15 * time.Second,
+		ResendAfterBlocks: 0,
+		GasPriceBump:      20,
 	}
 }
 
@@ -94,9 +155,65 @@ func OptionGasLimit(limit uint64) EVMCommitterOption {
 	}
 }
 
+func OptionFeeMode(mode FeeMode) EVMCommitterOption {
+	return func(o *options) error {
+		o.FeeMode = mode
+		return nil
+	}
+}
+
 func TxBroadcastTimeout(dur time.Duration) EVMCommitterOption {
 	return func(o *options) error {
 		o.RPCTimeout = dur
 		return nil
 	}
 }
+
+func OptionTxPollInterval(dur time.Duration) EVMCommitterOption {
+	return func(o *options) error {
+		if dur <= 0 {
+			return errors.New("tx poll interval must be positive")
+		}
+
+		o.TxPollInterval = dur
+		return nil
+	}
+}
+
+func OptionResendAfterBlocks(blocks uint64) EVMCommitterOption {
+	return func(o *options) error {
+		o.ResendAfterBlocks = blocks
+		return nil
+	}
+}
+
+func OptionGasPriceBump(percent uint64) EVMCommitterOption {
+	return func(o *options) error {
+		// nodes refuse to replace a transaction for less than a 10% increase
+		if percent < 10 {
+			return errors.Errorf("gas price bump must be at least 10%%, got %d%%", percent)
+		}
+
+		o.GasPriceBump = percent
+		return nil
+	}
+}
+
+func OptionMaxGasPrice(i *big.Int) EVMCommitterOption {
+	return func(o *options) error {
+		o.MaxGasPrice = i
+		return nil
+	}
+}
+
+func OptionPrivateTxSender(sender PrivateTxSender, fallbackBlocks uint64) EVMCommitterOption {
+	return func(o *options) error {
+		if fallbackBlocks == 0 {
+			return errors.New("private transactions fallback blocks must be positive")
+		}
+
+		o.PrivateTxSender = sender
+		o.PrivateTxFallbackBlocks = fallbackBlocks
+		return nil
+	}
+}
@@ -3,7 +3,9 @@ package committer
 import (
 	"context"
 	"math/big"
+	"sort"
 	"strings"
+	"sync"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
@@ -15,6 +17,21 @@ import (
 	"github.com/cicizeo/loran/orchestrator/ethereum/util"
 )
 
+const (
+	// feeHistoryBlocks is the number of blocks the priority fee of dynamic-fee transactions is computed from.
+	feeHistoryBlocks = 10
+
+	// feeHistoryRewardPercentile is the percentile of the priority fees paid in a block that is looked at.
+	feeHistoryRewardPercentile = 50
+
+	// maxTxOutcomes is the number of transaction hashes the outcome is remembered for.
+	maxTxOutcomes = 1000
+
+	// baseFeeMultiplier leaves room in the max fee for the base fee to grow while the transaction is pending. The base
+	// fee can grow by 12.5% per block, so doubling it covers about 6 full blocks.
+	baseFeeMultiplier = 2
+)
+
 // NewEthCommitter returns an instance of EVMCommitter, which
 // can be used to submit txns into Ethereum, Matic, and other EVM-compatible networks.
 func NewEthCommitter(
@@ -35,6 +52,8 @@ func NewEthCommitter(
 		fromSigner:            fromSigner,
 		evmProvider:           evmProvider,
 		nonceCache:            util.NewNonceCache(),
+		pendingTxs:            make(map[uint64]*pendingTx),
+		txOutcomes:            make(map[ethcmn.Hash]*TxOutcome),
 	}
 
 	if err := applyOptions(committer.committerOpts, committerOpts...); err != nil {
@@ -60,6 +79,15 @@ type ethCommitter struct {
 	ethGasLimitAdjustment float64
 	evmProvider           provider.EVMProviderWithRet
 	nonceCache            util.NonceCache
+
+	chainIDMux sync.Mutex
+	chainID    *big.Int
+
+	pendingTxsMux sync.Mutex
+	pendingTxs    map[uint64]*pendingTx // by nonce
+	txOutcomes    map[ethcmn.Hash]*TxOutcome
+	// txOutcomeHashes holds the hashes of txOutcomes in the order the outcomes were known, to forget the oldest ones.
+	txOutcomeHashes []ethcmn.Hash
 }
 
 func (e *ethCommitter) FromAddress() ethcmn.Address {
@@ -75,38 +103,106 @@ func (e *ethCommitter) EstimateGas(
 	recipient ethcmn.Address,
 	txData []byte,
 ) (gasCost uint64, gasPrice *big.Int, err error) {
+	msg := ethereum.CallMsg{From: e.fromAddress, To: &recipient, Value: nil, Data: txData}
 
-	opts := &bind.TransactOpts{
-		From:     e.fromAddress,
-		Signer:   e.fromSigner,
-		GasPrice: e.committerOpts.GasPrice.BigInt(),
-		GasLimit: e.committerOpts.GasLimit,
-		Context:  ctx, // with RPC timeout
+	if e.committerOpts.FeeMode == FeeModeDynamic {
+		var baseFee *big.Int
+		baseFee, msg.GasTipCap, msg.GasFeeCap, err = e.suggestFees(ctx)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		// The max fee is only an upper bound, the transaction is expected to pay the base fee plus the priority fee.
+		gasPrice = new(big.Int).Add(baseFee, msg.GasTipCap)
+	} else {
+		suggestedGasPrice, err := e.evmProvider.SuggestGasPrice(ctx)
+		if err != nil {
+			return 0, nil, errors.Errorf("failed to suggest gas price: %v", err)
+		}
+
+		// Suggested gas price may not be accurate, so we multiply the result by the gas price adjustment factor.
+		gasPrice = e.adjustGasPrice(suggestedGasPrice)
+		msg.GasPrice = gasPrice
 	}
 
-	suggestedGasPrice, err := e.evmProvider.SuggestGasPrice(opts.Context)
+	gasCost, err = e.evmProvider.EstimateGas(ctx, msg)
+
+	// Estimated gas cost may not be accurate, so we multiply the result by the gas limit adjustment factor.
+	gasCost = uint64(float64(gasCost) * e.ethGasLimitAdjustment)
+
+	return gasCost, gasPrice, err
+}
+
+// suggestFees returns the base fee of the next block, and the priority fee and the max fee per gas of a dynamic-fee
+// transaction. The priority fee is the median of the ones paid in the last blocks, and the max fee leaves room for the
+// base fee to double before the transaction is mined.
+func (e *ethCommitter) suggestFees(ctx context.Context) (baseFee, gasTipCap, gasFeeCap *big.Int, err error) {
+	history, err := e.evmProvider.FeeHistory(ctx, feeHistoryBlocks, nil, []float64{feeHistoryRewardPercentile})
 	if err != nil {
-		return 0, nil, errors.Errorf("failed to suggest gas price: %v", err)
+		return nil, nil, nil, errors.Wrap(err, "failed to get the fee history")
+	}
+
+	if len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1] == nil {
+		return nil, nil, nil, errors.New("no base fee in the fee history, use legacy fees on chains without EIP-1559")
+	}
+
+	// the last base fee is the one of the next block
+	baseFee = history.BaseFee[len(history.BaseFee)-1]
+
+	rewards := make([]*big.Int, 0, len(history.Reward))
+	for _, blockRewards := range history.Reward {
+		if len(blockRewards) > 0 && blockRewards[0] != nil {
+			rewards = append(rewards, blockRewards[0])
+		}
+	}
+
+	if len(rewards) > 0 {
+		sort.Slice(rewards, func(i, j int) bool { return rewards[i].Cmp(rewards[j]) < 0 })
+		gasTipCap = rewards[len(rewards)/2]
 	}
 
-	// Suggested gas price may not be accurate, so we multiply the result by the gas price adjustment factor.
+	// Empty blocks report no priority fee, fall back to the node suggestion.
+	if gasTipCap == nil || gasTipCap.Sign() == 0 {
+		gasTipCap, err = e.evmProvider.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, nil, errors.Errorf("failed to suggest gas tip cap: %v", err)
+		}
+	}
+
+	gasTipCap = e.adjustGasPrice(gasTipCap)
+	gasFeeCap = new(big.Int).Add(new(big.Int).Mul(baseFee, big.NewInt(baseFeeMultiplier)), gasTipCap)
+
+	return baseFee, gasTipCap, gasFeeCap, nil
+}
+
+// adjustGasPrice multiplies a gas price (or priority fee) by the gas price adjustment factor.
+func (e *ethCommitter) adjustGasPrice(gasPrice *big.Int) *big.Int {
 	incrementedPrice := big.NewFloat(0).Mul(
-		new(big.Float).SetInt(suggestedGasPrice),
+		new(big.Float).SetInt(gasPrice),
 		big.NewFloat(e.ethGasPriceAdjustment),
 	)
 
-	gasPrice = new(big.Int)
-	incrementedPrice.Int(gasPrice)
+	adjusted := new(big.Int)
+	incrementedPrice.Int(adjusted)
 
-	opts.GasPrice = gasPrice
-	msg := ethereum.CallMsg{From: opts.From, To: &recipient, GasPrice: gasPrice, Value: nil, Data: txData}
+	return adjusted
+}
 
-	gasCost, err = e.evmProvider.EstimateGas(ctx, msg)
+// getChainID returns the chain ID dynamic-fee transactions are signed for.
+func (e *ethCommitter) getChainID(ctx context.Context) (*big.Int, error) {
+	e.chainIDMux.Lock()
+	defer e.chainIDMux.Unlock()
 
-	// Estimated gas cost may not be accurate, so we multiply the result by the gas limit adjustment factor.
-	gasCost = uint64(float64(gasCost) * e.ethGasLimitAdjustment)
+	if e.chainID == nil {
+		chainID, err := e.evmProvider.ChainID(ctx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get the chain ID")
+		}
 
-	return gasCost, gasPrice, err
+		e.chainID = chainID
+	}
+
+	return e.chainID, nil
 }
 
 func (e *ethCommitter) SendTx(
@@ -116,6 +212,16 @@ func (e *ethCommitter) SendTx(
 	gasCost uint64,
 	gasPrice *big.Int,
 ) (txHash ethcmn.Hash, err error) {
+	// Sending the same call again would only queue it behind the pending one, the tracker replaces it instead.
+	if nonce, pendingTxHash, ok := e.findPendingTx(recipient, txData); ok {
+		e.logger.Info().
+			Uint64("nonce", nonce).
+			Str("tx_hash", pendingTxHash.Hex()).
+			Msg("same transaction already pending, not sending it again")
+
+		return pendingTxHash, nil
+	}
+
 	opts := &bind.TransactOpts{
 		From:   e.fromAddress,
 		Signer: e.fromSigner,
@@ -125,6 +231,24 @@ func (e *ethCommitter) SendTx(
 		Context:  ctx, // with RPC timeout
 	}
 
+	// With dynamic fees, the gas price is only the expected price the transaction was priced with, the fees are
+	// suggested again with a max fee leaving room for the base fee to grow.
+	var chainID *big.Int
+	if e.committerOpts.FeeMode == FeeModeDynamic {
+		if chainID, err = e.getChainID(ctx); err != nil {
+			return ethcmn.Hash{}, err
+		}
+
+		_, gasTipCap, gasFeeCap, err := e.suggestFees(ctx)
+		if err != nil {
+			return ethcmn.Hash{}, err
+		}
+
+		opts.GasPrice = nil
+		opts.GasFeeCap = gasFeeCap
+		opts.GasTipCap = gasTipCap
+	}
+
 	resyncNonces := func(from ethcmn.Address) {
 		e.nonceCache.Sync(from, func() (uint64, error) {
 			nonce, err := e.evmProvider.PendingNonceAt(context.TODO(), from)
@@ -146,7 +270,13 @@ func (e *ethCommitter) SendTx(
 			opts.Context, cancel = context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
 			defer cancel()
 
-			tx := types.NewTransaction(opts.Nonce.Uint64(), recipient, nil, opts.GasLimit, opts.GasPrice, txData)
+			var tx *types.Transaction
+			if e.committerOpts.FeeMode == FeeModeDynamic {
+				tx = newDynamicFeeTx(chainID, opts.Nonce.Uint64(), recipient, txData, opts.GasLimit, opts.GasFeeCap, opts.GasTipCap)
+			} else {
+				tx = types.NewTransaction(opts.Nonce.Uint64(), recipient, nil, opts.GasLimit, opts.GasPrice, txData)
+			}
+
 			signedTx, err := opts.Signer(opts.From, tx)
 			if err != nil {
 				err := errors.Wrap(err, "failed to sign transaction")
@@ -155,11 +285,37 @@ func (e *ethCommitter) SendTx(
 
 			txHash = signedTx.Hash()
 
+			// A private transaction that isn't included in time is sent to the public mempool by the tracker.
+			if privateUntil := e.sendPrivateTx(opts.Context, signedTx); privateUntil != 0 {
+				e.nonceCache.Incr(e.fromAddress)
+				e.trackPendingTx(&pendingTx{
+					nonce:        opts.Nonce.Uint64(),
+					recipient:    recipient,
+					txData:       txData,
+					gasCost:      opts.GasLimit,
+					gasPrice:     signedTx.GasFeeCap(),
+					gasTipCap:    signedTx.GasTipCap(),
+					txHashes:     []ethcmn.Hash{txHash},
+					privateTx:    signedTx,
+					privateUntil: privateUntil,
+				})
+				return nil
+			}
+
 			txHashRet, err := e.evmProvider.SendTransactionWithRet(opts.Context, signedTx)
 			if err == nil {
 				// override with a real hash from node resp
 				txHash = txHashRet
 				e.nonceCache.Incr(e.fromAddress)
+				e.trackPendingTx(&pendingTx{
+					nonce:     opts.Nonce.Uint64(),
+					recipient: recipient,
+					txData:    txData,
+					gasCost:   opts.GasLimit,
+					gasPrice:  signedTx.GasFeeCap(),
+					gasTipCap: signedTx.GasTipCap(),
+					txHashes:  []ethcmn.Hash{txHash},
+				})
 				return nil
 			}
 
@@ -218,3 +374,59 @@ func (e *ethCommitter) SendTx(
 
 	return txHash, nil
 }
+
+// sendPrivateTx sends a transaction to the private relay, if any, and returns the last block it may be included in
+// before it is sent to the public mempool. It returns zero if the transaction wasn't sent privately, so it goes to the
+// public mempool right away.
+func (e *ethCommitter) sendPrivateTx(ctx context.Context, signedTx *types.Transaction) uint64 {
+	sender := e.committerOpts.PrivateTxSender
+	if sender == nil {
+		return 0
+	}
+
+	header, err := e.evmProvider.HeaderByNumber(ctx, nil)
+	if err != nil {
+		e.logger.Err(err).
+			Str("tx_hash", signedTx.Hash().Hex()).
+			Msg("failed to get the latest header, sending the transaction to the public mempool")
+		return 0
+	}
+
+	head := header.Number.Uint64()
+	privateUntil := head + e.committerOpts.PrivateTxFallbackBlocks
+
+	if err := sender.SendPrivateTx(ctx, signedTx, head+1, privateUntil); err != nil {
+		e.logger.Err(err).
+			Str("tx_hash", signedTx.Hash().Hex()).
+			Msg("failed to send the transaction privately, sending it to the public mempool")
+		return 0
+	}
+
+	e.logger.Info().
+		Uint64("nonce", signedTx.Nonce()).
+		Str("tx_hash", signedTx.Hash().Hex()).
+		Uint64("private_until", privateUntil).
+		Msg("sent transaction privately")
+
+	return privateUntil
+}
+
+func newDynamicFeeTx(
+	chainID *big.Int,
+	nonce uint64,
+	recipient ethcmn.Address,
+	txData []byte,
+	gasCost uint64,
+	gasFeeCap *big.Int,
+	gasTipCap *big.Int,
+) *types.Transaction {
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     nonce,
+		GasTipCap: gasTipCap,
+		GasFeeCap: gasFeeCap,
+		Gas:       gasCost,
+		To:        &recipient,
+		Data:      txData,
+	})
+}
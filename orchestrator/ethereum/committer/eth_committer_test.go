@@ -0,0 +1,162 @@
+package committer
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+)
+
+func TestEthCommitterFees(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	recipient := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	txData := []byte{0x1, 0x2}
+
+	newCommitter := func(mockCtrl *gomock.Controller, mode FeeMode) (*ethCommitter, *mocks.MockEVMProviderWithRet) {
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(7), nil)
+
+		signerFn := func(from ethcmn.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		}
+
+		c, err := NewEthCommitter(logger, fromAddress, 1.5, 1.0, signerFn, ethProvider, OptionFeeMode(mode))
+		assert.Nil(t, err)
+
+		return c.(*ethCommitter), ethProvider
+	}
+
+	feeHistory := &provider.FeeHistory{
+		OldestBlock: big.NewInt(100),
+		Reward: [][]*big.Int{
+			{big.NewInt(3)},
+			{big.NewInt(1)},
+			{big.NewInt(2)},
+		},
+		BaseFee: []*big.Int{big.NewInt(90), big.NewInt(95), big.NewInt(100), big.NewInt(110)},
+	}
+
+	t.Run("legacy estimate", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl, FeeModeLegacy)
+		ethProvider.EXPECT().SuggestGasPrice(gomock.Any()).Return(big.NewInt(100), nil)
+		ethProvider.EXPECT().EstimateGas(gomock.Any(), ethereum.CallMsg{
+			From:     fromAddress,
+			To:       &recipient,
+			GasPrice: big.NewInt(150),
+			Data:     txData,
+		}).Return(uint64(50000), nil)
+
+		gasCost, gasPrice, err := c.EstimateGas(context.Background(), recipient, txData)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(50000), gasCost)
+		assert.Equal(t, big.NewInt(150), gasPrice)
+	})
+
+	t.Run("dynamic estimate", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl, FeeModeDynamic)
+		ethProvider.EXPECT().FeeHistory(gomock.Any(), uint64(feeHistoryBlocks), nil, []float64{50}).
+			Return(feeHistory, nil)
+
+		// median tip of 2, adjusted to 3; max fee of 2*110 + 3, expected to pay 110 + 3
+		ethProvider.EXPECT().EstimateGas(gomock.Any(), ethereum.CallMsg{
+			From:      fromAddress,
+			To:        &recipient,
+			GasTipCap: big.NewInt(3),
+			GasFeeCap: big.NewInt(223),
+			Data:      txData,
+		}).Return(uint64(50000), nil)
+
+		gasCost, gasPrice, err := c.EstimateGas(context.Background(), recipient, txData)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(50000), gasCost)
+		assert.Equal(t, big.NewInt(113), gasPrice)
+	})
+
+	t.Run("dynamic estimate with empty blocks", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl, FeeModeDynamic)
+		ethProvider.EXPECT().FeeHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&provider.FeeHistory{
+				Reward:  [][]*big.Int{{big.NewInt(0)}, {big.NewInt(0)}},
+				BaseFee: []*big.Int{big.NewInt(10), big.NewInt(10), big.NewInt(10)},
+			}, nil)
+		ethProvider.EXPECT().SuggestGasTipCap(gomock.Any()).Return(big.NewInt(4), nil)
+
+		baseFee, gasTipCap, gasFeeCap, err := c.suggestFees(context.Background())
+		assert.Nil(t, err)
+		assert.Equal(t, big.NewInt(10), baseFee)
+		assert.Equal(t, big.NewInt(6), gasTipCap)
+		assert.Equal(t, big.NewInt(26), gasFeeCap)
+	})
+
+	t.Run("dynamic fees without EIP-1559", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl, FeeModeDynamic)
+		ethProvider.EXPECT().FeeHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(&provider.FeeHistory{Reward: [][]*big.Int{{big.NewInt(1)}}}, nil)
+
+		_, _, err := c.EstimateGas(context.Background(), recipient, txData)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("dynamic send", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl, FeeModeDynamic)
+		ethProvider.EXPECT().ChainID(gomock.Any()).Return(big.NewInt(5), nil)
+		ethProvider.EXPECT().FeeHistory(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(feeHistory, nil)
+
+		var sentTx *types.Transaction
+		ethProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tx *types.Transaction) (ethcmn.Hash, error) {
+				sentTx = tx
+				return tx.Hash(), nil
+			})
+
+		// the max fee is not the expected price the transaction was priced with
+		txHash, err := c.SendTx(context.Background(), recipient, txData, 50000, big.NewInt(113))
+		assert.Nil(t, err)
+		assert.Equal(t, sentTx.Hash(), txHash)
+		assert.Equal(t, uint8(types.DynamicFeeTxType), sentTx.Type())
+		assert.Equal(t, big.NewInt(5), sentTx.ChainId())
+		assert.Equal(t, uint64(7), sentTx.Nonce())
+		assert.Equal(t, uint64(50000), sentTx.Gas())
+		assert.Equal(t, big.NewInt(223), sentTx.GasFeeCap())
+		assert.Equal(t, big.NewInt(3), sentTx.GasTipCap())
+	})
+}
+
+func TestParseFeeMode(t *testing.T) {
+	mode, err := ParseFeeMode("dynamic")
+	assert.Nil(t, err)
+	assert.Equal(t, FeeModeDynamic, mode)
+
+	mode, err = ParseFeeMode("legacy")
+	assert.Nil(t, err)
+	assert.Equal(t, FeeModeLegacy, mode)
+
+	_, err = ParseFeeMode("eip1559")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,402 @@
+package committer
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/cicizeo/loran/orchestrator/loops"
+)
+
+// TxStatus is the final outcome of a transaction sent by the committer.
+type TxStatus string
+
+const (
+	// TxStatusSucceeded means the transaction, or one of its replacements, was mined and succeeded.
+	TxStatusSucceeded TxStatus = "succeeded"
+
+	// TxStatusReverted means the transaction, or one of its replacements, was mined but reverted.
+	TxStatusReverted TxStatus = "reverted"
+
+	// TxStatusDropped means the nonce of the transaction was used by a transaction the committer didn't send.
+	TxStatusDropped TxStatus = "dropped"
+)
+
+// TxOutcome is the final outcome of a transaction sent by the committer, known once its nonce is used.
+type TxOutcome struct {
+	Nonce  uint64
+	Status TxStatus
+	// TxHash is the hash of the mined transaction, or the last one sent if it was dropped.
+	TxHash ethcmn.Hash
+	// Receipt is nil if the transaction was dropped.
+	Receipt      *types.Receipt
+	Replacements int
+
+	Recipient ethcmn.Address
+	TxData    []byte
+	GasCost   uint64
+}
+
+// pendingTx is a transaction sent by the committer whose nonce isn't used yet, along with everything needed to replace
+// it.
+type pendingTx struct {
+	nonce     uint64
+	recipient ethcmn.Address
+	txData    []byte
+	gasCost   uint64
+	gasPrice  *big.Int // the max fee per gas with dynamic fees
+	gasTipCap *big.Int // only used with dynamic fees
+
+	// txHashes holds the hashes of the transaction and all its replacements, the last one being the latest.
+	txHashes []ethcmn.Hash
+	// sentBlock is the block number the latest replacement was first seen pending at, zero until then.
+	sentBlock uint64
+	// capped is set once the gas price reached the max gas price, so there is no more replacement to send.
+	capped bool
+
+	// privateTx is the transaction sent to the private relay, nil once it was sent to the public mempool.
+	privateTx *types.Transaction
+	// privateUntil is the last block the private transaction may be included in before it is made public.
+	privateUntil uint64
+}
+
+func (tx *pendingTx) lastTxHash() ethcmn.Hash {
+	return tx.txHashes[len(tx.txHashes)-1]
+}
+
+func (e *ethCommitter) trackPendingTx(tx *pendingTx) {
+	e.pendingTxsMux.Lock()
+	defer e.pendingTxsMux.Unlock()
+
+	e.pendingTxs[tx.nonce] = tx
+}
+
+// findPendingTx returns the nonce and the latest hash of a pending transaction with the same recipient and data.
+func (e *ethCommitter) findPendingTx(recipient ethcmn.Address, txData []byte) (nonce uint64, txHash ethcmn.Hash, ok bool) {
+	e.pendingTxsMux.Lock()
+	defer e.pendingTxsMux.Unlock()
+
+	for _, tx := range e.pendingTxs {
+		if tx.recipient == recipient && bytes.Equal(tx.txData, txData) {
+			return tx.nonce, tx.lastTxHash(), true
+		}
+	}
+
+	return 0, ethcmn.Hash{}, false
+}
+
+// sortedPendingTxs returns the pending transactions, lowest nonce first.
+func (e *ethCommitter) sortedPendingTxs() []*pendingTx {
+	e.pendingTxsMux.Lock()
+	defer e.pendingTxsMux.Unlock()
+
+	txs := make([]*pendingTx, 0, len(e.pendingTxs))
+	for _, tx := range e.pendingTxs {
+		txs = append(txs, tx)
+	}
+
+	sort.Slice(txs, func(i, j int) bool { return txs[i].nonce < txs[j].nonce })
+
+	return txs
+}
+
+func (e *ethCommitter) TrackPendingTxs(ctx context.Context) error {
+	logger := e.logger.With().Str("loop", "TrackPendingTxs").Logger()
+
+	return loops.RunLoop(ctx, logger, e.committerOpts.TxPollInterval, func() error {
+		// A failed check is retried on the next loop, the pending transactions are kept until their nonce is used.
+		if err := e.checkPendingTxs(ctx); err != nil {
+			logger.Err(err).Msg("failed to check the pending transactions")
+		}
+
+		return nil
+	})
+}
+
+// checkPendingTxs reports the outcome of the transactions whose nonce was used, sends the private ones that weren't
+// included in time to the public mempool and replaces the ones pending for more than ResendAfterBlocks blocks.
+func (e *ethCommitter) checkPendingTxs(ctx context.Context) error {
+	txs := e.sortedPendingTxs()
+	if len(txs) == 0 {
+		return nil
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
+	defer cancel()
+
+	header, err := e.evmProvider.HeaderByNumber(rpcCtx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the latest header")
+	}
+
+	// The nonce is fetched before the receipts, so a transaction mined in between isn't mistaken for a dropped one.
+	confirmedNonce, err := e.evmProvider.NonceAt(rpcCtx, e.fromAddress, header.Number)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the confirmed nonce")
+	}
+
+	head := header.Number.Uint64()
+
+	for _, tx := range txs {
+		outcome, err := e.findTxOutcome(ctx, tx, confirmedNonce)
+		if err != nil {
+			e.logger.Err(err).Uint64("nonce", tx.nonce).Msg("failed to check pending transaction")
+			continue
+		}
+
+		if outcome != nil {
+			e.saveTxOutcome(tx, outcome)
+			e.reportTxOutcome(outcome)
+			continue
+		}
+
+		// A private transaction isn't replaced, the replacement would go to the public mempool.
+		if tx.privateTx != nil {
+			if err := e.publishIfNotIncluded(ctx, tx, head); err != nil {
+				e.logger.Err(err).
+					Uint64("nonce", tx.nonce).
+					Str("tx_hash", tx.lastTxHash().Hex()).
+					Msg("failed to send private transaction to the public mempool")
+			}
+
+			continue
+		}
+
+		if err := e.replaceIfStuck(ctx, tx, head); err != nil {
+			e.logger.Err(err).
+				Uint64("nonce", tx.nonce).
+				Str("tx_hash", tx.lastTxHash().Hex()).
+				Msg("failed to replace stuck transaction")
+		}
+	}
+
+	return nil
+}
+
+// findTxOutcome returns the outcome of a transaction, or nil if its nonce isn't used yet.
+func (e *ethCommitter) findTxOutcome(ctx context.Context, tx *pendingTx, confirmedNonce uint64) (*TxOutcome, error) {
+	if tx.nonce >= confirmedNonce {
+		return nil, nil
+	}
+
+	for _, txHash := range tx.txHashes {
+		rpcCtx, cancel := context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
+		receipt, err := e.evmProvider.TransactionReceipt(rpcCtx, txHash)
+		cancel()
+
+		if err == ethereum.NotFound {
+			continue
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the receipt of %s", txHash.Hex())
+		}
+
+		status := TxStatusSucceeded
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			status = TxStatusReverted
+		}
+
+		return &TxOutcome{
+			Nonce:        tx.nonce,
+			Status:       status,
+			TxHash:       txHash,
+			Receipt:      receipt,
+			Replacements: len(tx.txHashes) - 1,
+			Recipient:    tx.recipient,
+			TxData:       tx.txData,
+			GasCost:      tx.gasCost,
+		}, nil
+	}
+
+	return &TxOutcome{
+		Nonce:        tx.nonce,
+		Status:       TxStatusDropped,
+		TxHash:       tx.lastTxHash(),
+		Replacements: len(tx.txHashes) - 1,
+		Recipient:    tx.recipient,
+		TxData:       tx.txData,
+		GasCost:      tx.gasCost,
+	}, nil
+}
+
+// saveTxOutcome stops tracking a transaction and remembers its outcome under the hashes of all its replacements.
+func (e *ethCommitter) saveTxOutcome(tx *pendingTx, outcome *TxOutcome) {
+	e.pendingTxsMux.Lock()
+	defer e.pendingTxsMux.Unlock()
+
+	delete(e.pendingTxs, tx.nonce)
+
+	for _, txHash := range tx.txHashes {
+		e.txOutcomes[txHash] = outcome
+		e.txOutcomeHashes = append(e.txOutcomeHashes, txHash)
+	}
+
+	for len(e.txOutcomeHashes) > maxTxOutcomes {
+		delete(e.txOutcomes, e.txOutcomeHashes[0])
+		e.txOutcomeHashes = e.txOutcomeHashes[1:]
+	}
+}
+
+func (e *ethCommitter) TxOutcome(txHash ethcmn.Hash) (*TxOutcome, error) {
+	e.pendingTxsMux.Lock()
+	defer e.pendingTxsMux.Unlock()
+
+	if outcome, ok := e.txOutcomes[txHash]; ok {
+		return outcome, nil
+	}
+
+	for _, tx := range e.pendingTxs {
+		for _, pendingTxHash := range tx.txHashes {
+			if pendingTxHash == txHash {
+				return nil, ErrTxPending
+			}
+		}
+	}
+
+	return nil, ErrTxUnknown
+}
+
+func (e *ethCommitter) reportTxOutcome(outcome *TxOutcome) {
+	var event = e.logger.Info()
+	if outcome.Status != TxStatusSucceeded {
+		event = e.logger.Warn()
+	}
+
+	event.
+		Uint64("nonce", outcome.Nonce).
+		Str("tx_hash", outcome.TxHash.Hex()).
+		Str("status", string(outcome.Status)).
+		Int("replacements", outcome.Replacements).
+		Msg("transaction outcome")
+}
+
+// publishIfNotIncluded sends a private transaction to the public mempool once the private relay didn't get it
+// included for PrivateTxFallbackBlocks blocks. From then on, it is replaced like any other transaction if stuck.
+func (e *ethCommitter) publishIfNotIncluded(ctx context.Context, tx *pendingTx, head uint64) error {
+	if head <= tx.privateUntil {
+		return nil
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
+	defer cancel()
+
+	if _, err := e.evmProvider.SendTransactionWithRet(rpcCtx, tx.privateTx); err != nil &&
+		!strings.Contains(err.Error(), "known transaction") &&
+		!strings.Contains(err.Error(), "already known") {
+		return err
+	}
+
+	e.logger.Warn().
+		Uint64("nonce", tx.nonce).
+		Str("tx_hash", tx.lastTxHash().Hex()).
+		Uint64("private_until", tx.privateUntil).
+		Msg("private transaction not included in time, sent it to the public mempool")
+
+	e.pendingTxsMux.Lock()
+	defer e.pendingTxsMux.Unlock()
+
+	tx.privateTx = nil
+	tx.privateUntil = 0
+	tx.sentBlock = head
+
+	return nil
+}
+
+// replaceIfStuck sends a replacement of the transaction, with the same nonce and a higher gas price, once it has been
+// pending for ResendAfterBlocks blocks.
+func (e *ethCommitter) replaceIfStuck(ctx context.Context, tx *pendingTx, head uint64) error {
+	resendAfterBlocks := e.committerOpts.ResendAfterBlocks
+	if resendAfterBlocks == 0 || tx.capped {
+		return nil
+	}
+
+	if tx.sentBlock == 0 {
+		tx.sentBlock = head
+		return nil
+	}
+
+	if head < tx.sentBlock+resendAfterBlocks {
+		return nil
+	}
+
+	gasPrice := bumpGasPrice(tx.gasPrice, e.committerOpts.GasPriceBump)
+	gasTipCap := bumpGasPrice(tx.gasTipCap, e.committerOpts.GasPriceBump)
+
+	if maxGasPrice := e.committerOpts.MaxGasPrice; maxGasPrice != nil && gasPrice.Cmp(maxGasPrice) > 0 {
+		// A replacement must pay at least 10% more, so there is no point in sending one just under the cap.
+		if minReplacement := bumpGasPrice(tx.gasPrice, 10); minReplacement.Cmp(maxGasPrice) > 0 {
+			tx.capped = true
+
+			e.logger.Warn().
+				Uint64("nonce", tx.nonce).
+				Str("tx_hash", tx.lastTxHash().Hex()).
+				Str("gas_price", tx.gasPrice.String()).
+				Msg("transaction still pending at the max gas price, waiting for it to be mined")
+
+			return nil
+		}
+
+		gasPrice = new(big.Int).Set(maxGasPrice)
+		if gasTipCap.Cmp(gasPrice) > 0 {
+			gasTipCap = gasPrice
+		}
+	}
+
+	var unsignedTx *types.Transaction
+	if e.committerOpts.FeeMode == FeeModeDynamic {
+		chainID, err := e.getChainID(ctx)
+		if err != nil {
+			return err
+		}
+
+		unsignedTx = newDynamicFeeTx(chainID, tx.nonce, tx.recipient, tx.txData, tx.gasCost, gasPrice, gasTipCap)
+	} else {
+		unsignedTx = types.NewTransaction(tx.nonce, tx.recipient, nil, tx.gasCost, gasPrice, tx.txData)
+	}
+
+	signedTx, err := e.fromSigner(e.fromAddress, unsignedTx)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign transaction")
+	}
+
+	rpcCtx, cancel := context.WithTimeout(ctx, e.committerOpts.RPCTimeout)
+	defer cancel()
+
+	txHash, err := e.evmProvider.SendTransactionWithRet(rpcCtx, signedTx)
+	if err != nil && !strings.Contains(err.Error(), "known transaction") &&
+		!strings.Contains(err.Error(), "already known") {
+		return err
+	} else if err != nil {
+		txHash = signedTx.Hash()
+	}
+
+	e.logger.Info().
+		Uint64("nonce", tx.nonce).
+		Str("replaced_tx_hash", tx.lastTxHash().Hex()).
+		Str("tx_hash", txHash.Hex()).
+		Str("gas_price", gasPrice.String()).
+		Msg("replaced stuck transaction")
+
+	e.pendingTxsMux.Lock()
+	defer e.pendingTxsMux.Unlock()
+
+	tx.gasPrice = gasPrice
+	tx.gasTipCap = gasTipCap
+	tx.txHashes = append(tx.txHashes, txHash)
+	tx.sentBlock = head
+
+	return nil
+}
+
+// bumpGasPrice increases a gas price by the given percentage, rounding up.
+func bumpGasPrice(gasPrice *big.Int, percent uint64) *big.Int {
+	bumped := new(big.Int).Mul(gasPrice, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+
+	return bumped.Div(bumped, big.NewInt(100))
+}
@@ -0,0 +1,161 @@
+package committer
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+)
+
+func TestPendingTxs(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	recipient := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	txData := []byte{0x1, 0x2}
+
+	newCommitter := func(
+		mockCtrl *gomock.Controller,
+		opts ...EVMCommitterOption,
+	) (*ethCommitter, *mocks.MockEVMProviderWithRet) {
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(7), nil)
+
+		signerFn := func(from ethcmn.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		}
+
+		c, err := NewEthCommitter(logger, fromAddress, 1.0, 1.0, signerFn, ethProvider, opts...)
+		assert.Nil(t, err)
+
+		return c.(*ethCommitter), ethProvider
+	}
+
+	// expectHead sets the latest block and the nonce confirmed at that block.
+	expectHead := func(ethProvider *mocks.MockEVMProviderWithRet, head int64, confirmedNonce uint64) {
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(head)}, nil)
+		ethProvider.EXPECT().NonceAt(gomock.Any(), fromAddress, big.NewInt(head)).Return(confirmedNonce, nil)
+	}
+
+	sendTx := func(t *testing.T, c *ethCommitter, ethProvider *mocks.MockEVMProviderWithRet) ethcmn.Hash {
+		ethProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tx *types.Transaction) (ethcmn.Hash, error) {
+				return tx.Hash(), nil
+			})
+
+		txHash, err := c.SendTx(context.Background(), recipient, txData, 50000, big.NewInt(100))
+		assert.Nil(t, err)
+
+		return txHash
+	}
+
+	t.Run("same transaction is not sent twice", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl)
+		txHash := sendTx(t, c, ethProvider)
+
+		sameTxHash, err := c.SendTx(context.Background(), recipient, txData, 60000, big.NewInt(120))
+		assert.Nil(t, err)
+		assert.Equal(t, txHash, sameTxHash)
+	})
+
+	t.Run("mined", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl)
+		txHash := sendTx(t, c, ethProvider)
+
+		expectHead(ethProvider, 100, 8)
+		ethProvider.EXPECT().TransactionReceipt(gomock.Any(), txHash).
+			Return(&types.Receipt{Status: types.ReceiptStatusSuccessful}, nil)
+
+		_, err := c.TxOutcome(txHash)
+		assert.Equal(t, ErrTxPending, err)
+
+		assert.Nil(t, c.checkPendingTxs(context.Background()))
+		assert.Empty(t, c.pendingTxs)
+
+		outcome, err := c.TxOutcome(txHash)
+		assert.Nil(t, err)
+		assert.Equal(t, TxStatusSucceeded, outcome.Status)
+		assert.Equal(t, txData, outcome.TxData)
+
+		_, err = c.TxOutcome(ethcmn.HexToHash("0x1"))
+		assert.Equal(t, ErrTxUnknown, err)
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl)
+		txHash := sendTx(t, c, ethProvider)
+
+		ethProvider.EXPECT().TransactionReceipt(gomock.Any(), txHash).Return(nil, ethereum.NotFound)
+
+		outcome, err := c.findTxOutcome(context.Background(), c.pendingTxs[7], 8)
+		assert.Nil(t, err)
+		assert.Equal(t, TxStatusDropped, outcome.Status)
+		assert.Equal(t, txHash, outcome.TxHash)
+	})
+
+	t.Run("stuck transaction is replaced", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		c, ethProvider := newCommitter(mockCtrl, OptionResendAfterBlocks(3), OptionMaxGasPrice(big.NewInt(130)))
+		txHash := sendTx(t, c, ethProvider)
+
+		// first seen pending
+		expectHead(ethProvider, 100, 7)
+		assert.Nil(t, c.checkPendingTxs(context.Background()))
+
+		// not stuck for long enough
+		expectHead(ethProvider, 102, 7)
+		assert.Nil(t, c.checkPendingTxs(context.Background()))
+
+		var replacement *types.Transaction
+		expectHead(ethProvider, 103, 7)
+		ethProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tx *types.Transaction) (ethcmn.Hash, error) {
+				replacement = tx
+				return tx.Hash(), nil
+			})
+		assert.Nil(t, c.checkPendingTxs(context.Background()))
+
+		assert.Equal(t, uint64(7), replacement.Nonce())
+		assert.Equal(t, big.NewInt(120), replacement.GasPrice())
+		assert.Equal(t, []ethcmn.Hash{txHash, replacement.Hash()}, c.pendingTxs[7].txHashes)
+
+		// capped, a 10% bump over 120 is above the max gas price of 130
+		expectHead(ethProvider, 106, 7)
+		assert.Nil(t, c.checkPendingTxs(context.Background()))
+		assert.True(t, c.pendingTxs[7].capped)
+
+		// the replacement is mined
+		ethProvider.EXPECT().TransactionReceipt(gomock.Any(), txHash).Return(nil, ethereum.NotFound)
+		ethProvider.EXPECT().TransactionReceipt(gomock.Any(), replacement.Hash()).
+			Return(&types.Receipt{Status: types.ReceiptStatusFailed}, nil)
+
+		outcome, err := c.findTxOutcome(context.Background(), c.pendingTxs[7], 8)
+		assert.Nil(t, err)
+		assert.Equal(t, TxStatusReverted, outcome.Status)
+		assert.Equal(t, replacement.Hash(), outcome.TxHash)
+		assert.Equal(t, 1, outcome.Replacements)
+	})
+}
+
+func TestBumpGasPrice(t *testing.T) {
+	assert.Equal(t, big.NewInt(120), bumpGasPrice(big.NewInt(100), 20))
+	assert.Equal(t, big.NewInt(12), bumpGasPrice(big.NewInt(10), 12))
+}
@@ -0,0 +1,155 @@
+package committer
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"io"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// JSON-RPC methods a private transaction relay can receive transactions with.
+const (
+	// PrivateTxMethodSendPrivateTransaction sends a single transaction, kept private until a given block.
+	PrivateTxMethodSendPrivateTransaction = "eth_sendPrivateTransaction"
+
+	// PrivateTxMethodSendBundle sends a bundle of transactions targeting a single block.
+	PrivateTxMethodSendBundle = "eth_sendBundle"
+)
+
+// flashbotsSignatureHeader is the header private relays authenticate the requests with, it holds the address and the
+// signature of the request body.
+const flashbotsSignatureHeader = "X-Flashbots-Signature"
+
+// PrivateTxSender sends signed transactions to a private relay instead of the public mempool, so they can't be copied
+// by other relayers before they are mined.
+type PrivateTxSender interface {
+	// SendPrivateTx asks for the transaction to be included in a block between fromBlock and toBlock.
+	SendPrivateTx(ctx context.Context, signedTx *types.Transaction, fromBlock, toBlock uint64) error
+}
+
+type rpcPrivateTxSender struct {
+	rc     *rpc.Client
+	method string
+}
+
+// NewPrivateTxSender returns a PrivateTxSender using the given JSON-RPC method of a private relay. If authKey is set,
+// the requests are signed with it, as relays like Flashbots require; the key only identifies the sender, it doesn't
+// need to hold any funds.
+func NewPrivateTxSender(endpoint string, method string, authKey *ecdsa.PrivateKey) (PrivateTxSender, error) {
+	if method != PrivateTxMethodSendPrivateTransaction && method != PrivateTxMethodSendBundle {
+		return nil, errors.Errorf(
+			"unsupported private transaction method %s, must be %s or %s",
+			method,
+			PrivateTxMethodSendPrivateTransaction,
+			PrivateTxMethodSendBundle,
+		)
+	}
+
+	httpClient := &http.Client{Transport: http.DefaultTransport}
+	if authKey != nil {
+		httpClient.Transport = &signingTransport{key: authKey, base: http.DefaultTransport}
+	}
+
+	rc, err := rpc.DialHTTPWithClient(endpoint, httpClient)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to dial private transaction relay %s", endpoint)
+	}
+
+	return &rpcPrivateTxSender{
+		rc:     rc,
+		method: method,
+	}, nil
+}
+
+// sendPrivateTxArgs are the eth_sendPrivateTransaction arguments.
+type sendPrivateTxArgs struct {
+	Tx             hexutil.Bytes  `json:"tx"`
+	MaxBlockNumber hexutil.Uint64 `json:"maxBlockNumber"`
+}
+
+// sendBundleArgs are the eth_sendBundle arguments.
+type sendBundleArgs struct {
+	Txs         []hexutil.Bytes `json:"txs"`
+	BlockNumber hexutil.Uint64  `json:"blockNumber"`
+}
+
+func (s *rpcPrivateTxSender) SendPrivateTx(
+	ctx context.Context,
+	signedTx *types.Transaction,
+	fromBlock uint64,
+	toBlock uint64,
+) error {
+	rawTx, err := signedTx.MarshalBinary()
+	if err != nil {
+		return errors.Wrap(err, "failed to encode the transaction")
+	}
+
+	if s.method == PrivateTxMethodSendPrivateTransaction {
+		args := sendPrivateTxArgs{
+			Tx:             rawTx,
+			MaxBlockNumber: hexutil.Uint64(toBlock),
+		}
+
+		if err := s.rc.CallContext(ctx, nil, s.method, args); err != nil {
+			return errors.Wrapf(err, "%s failed", s.method)
+		}
+
+		return nil
+	}
+
+	// A bundle only targets a single block, so one is sent for each block of the range.
+	for block := fromBlock; block <= toBlock; block++ {
+		args := sendBundleArgs{
+			Txs:         []hexutil.Bytes{rawTx},
+			BlockNumber: hexutil.Uint64(block),
+		}
+
+		if err := s.rc.CallContext(ctx, nil, s.method, args); err != nil {
+			return errors.Wrapf(err, "%s failed for block %d", s.method, block)
+		}
+	}
+
+	return nil
+}
+
+// signingTransport adds the signature of the request body to each request, as <address>:<signature>. The signature is
+// a personal message signature of the hex encoded keccak256 hash of the body.
+type signingTransport struct {
+	key  *ecdsa.PrivateKey
+	base http.RoundTripper
+}
+
+func (t *signingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, errors.Wrap(err, "failed to read the request body")
+		}
+
+		req.Body.Close()
+	}
+
+	hash := crypto.Keccak256Hash(body).Hex()
+	sig, err := crypto.Sign(accounts.TextHash([]byte(hash)), t.key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign the request")
+	}
+
+	signedReq := req.Clone(req.Context())
+	signedReq.Body = io.NopCloser(bytes.NewReader(body))
+	signedReq.Header.Set(
+		flashbotsSignatureHeader,
+		crypto.PubkeyToAddress(t.key.PublicKey).Hex()+":"+hexutil.Encode(sig),
+	)
+
+	return t.base.RoundTrip(signedReq)
+}
@@ -0,0 +1,262 @@
+package committer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+)
+
+// privateRelayRequest is a request received by the stand-in private relay.
+type privateRelayRequest struct {
+	method    string
+	params    json.RawMessage
+	signer    ethcmn.Address
+	hasSigner bool
+}
+
+// stubPrivateRelay is a local stand-in for a private transaction relay, recording the requests it receives.
+type stubPrivateRelay struct {
+	*httptest.Server
+
+	mtx      sync.Mutex
+	requests []privateRelayRequest
+	fail     bool
+}
+
+func newStubPrivateRelay(t *testing.T) *stubPrivateRelay {
+	relay := &stubPrivateRelay{}
+	relay.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		assert.NoError(t, json.Unmarshal(body, &req))
+		assert.Len(t, req.Params, 1)
+
+		received := privateRelayRequest{method: req.Method, params: req.Params[0]}
+		if header := r.Header.Get(flashbotsSignatureHeader); header != "" {
+			parts := strings.SplitN(header, ":", 2)
+			assert.Len(t, parts, 2)
+
+			sig := hexutil.MustDecode(parts[1])
+			pubKey, err := crypto.SigToPub(accounts.TextHash([]byte(crypto.Keccak256Hash(body).Hex())), sig)
+			assert.NoError(t, err)
+			assert.Equal(t, ethcmn.HexToAddress(parts[0]), crypto.PubkeyToAddress(*pubKey))
+
+			received.signer = crypto.PubkeyToAddress(*pubKey)
+			received.hasSigner = true
+		}
+
+		relay.mtx.Lock()
+		relay.requests = append(relay.requests, received)
+		fail := relay.fail
+		relay.mtx.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if fail {
+			fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"error":{"code":-32000,"message":"relay unavailable"}}`, req.ID)
+			return
+		}
+
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"0x1"}`, req.ID)
+	}))
+	t.Cleanup(relay.Close)
+
+	return relay
+}
+
+func (r *stubPrivateRelay) received() []privateRelayRequest {
+	r.mtx.Lock()
+	defer r.mtx.Unlock()
+
+	return append([]privateRelayRequest(nil), r.requests...)
+}
+
+func TestPrivateTxSender(t *testing.T) {
+	tx := types.NewTransaction(7, ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d"), nil, 50000, big.NewInt(100), []byte{0x1})
+	rawTx, err := tx.MarshalBinary()
+	assert.NoError(t, err)
+
+	t.Run("eth_sendPrivateTransaction", func(t *testing.T) {
+		relay := newStubPrivateRelay(t)
+
+		authKey, err := crypto.GenerateKey()
+		assert.NoError(t, err)
+
+		sender, err := NewPrivateTxSender(relay.URL, PrivateTxMethodSendPrivateTransaction, authKey)
+		assert.NoError(t, err)
+		assert.NoError(t, sender.SendPrivateTx(context.Background(), tx, 101, 110))
+
+		requests := relay.received()
+		assert.Len(t, requests, 1)
+		assert.Equal(t, PrivateTxMethodSendPrivateTransaction, requests[0].method)
+		assert.True(t, requests[0].hasSigner)
+		assert.Equal(t, crypto.PubkeyToAddress(authKey.PublicKey), requests[0].signer)
+
+		var args sendPrivateTxArgs
+		assert.NoError(t, json.Unmarshal(requests[0].params, &args))
+		assert.Equal(t, hexutil.Bytes(rawTx), args.Tx)
+		assert.Equal(t, hexutil.Uint64(110), args.MaxBlockNumber)
+	})
+
+	t.Run("eth_sendBundle", func(t *testing.T) {
+		relay := newStubPrivateRelay(t)
+
+		sender, err := NewPrivateTxSender(relay.URL, PrivateTxMethodSendBundle, nil)
+		assert.NoError(t, err)
+		assert.NoError(t, sender.SendPrivateTx(context.Background(), tx, 101, 103))
+
+		// one bundle per block, unsigned without an auth key
+		requests := relay.received()
+		assert.Len(t, requests, 3)
+		for i, req := range requests {
+			assert.Equal(t, PrivateTxMethodSendBundle, req.method)
+			assert.False(t, req.hasSigner)
+
+			var args sendBundleArgs
+			assert.NoError(t, json.Unmarshal(req.params, &args))
+			assert.Equal(t, []hexutil.Bytes{rawTx}, args.Txs)
+			assert.Equal(t, hexutil.Uint64(101+i), args.BlockNumber)
+		}
+	})
+
+	t.Run("relay error", func(t *testing.T) {
+		relay := newStubPrivateRelay(t)
+		relay.fail = true
+
+		sender, err := NewPrivateTxSender(relay.URL, PrivateTxMethodSendPrivateTransaction, nil)
+		assert.NoError(t, err)
+		assert.EqualError(
+			t,
+			sender.SendPrivateTx(context.Background(), tx, 101, 110),
+			"eth_sendPrivateTransaction failed: relay unavailable",
+		)
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		_, err := NewPrivateTxSender("http://localhost:8545", "eth_sendRawTransaction", nil)
+		assert.EqualError(
+			t,
+			err,
+			"unsupported private transaction method eth_sendRawTransaction, must be eth_sendPrivateTransaction or eth_sendBundle",
+		)
+	})
+}
+
+func TestPrivateTxFallback(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	recipient := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	txData := []byte{0x1, 0x2}
+
+	newCommitter := func(
+		mockCtrl *gomock.Controller,
+		relay *stubPrivateRelay,
+	) (*ethCommitter, *mocks.MockEVMProviderWithRet) {
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().PendingNonceAt(gomock.Any(), fromAddress).Return(uint64(7), nil)
+
+		signerFn := func(from ethcmn.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		}
+
+		sender, err := NewPrivateTxSender(relay.URL, PrivateTxMethodSendPrivateTransaction, nil)
+		assert.NoError(t, err)
+
+		c, err := NewEthCommitter(
+			logger,
+			fromAddress,
+			1.0,
+			1.0,
+			signerFn,
+			ethProvider,
+			OptionPrivateTxSender(sender, 2),
+			OptionResendAfterBlocks(3),
+		)
+		assert.NoError(t, err)
+
+		return c.(*ethCommitter), ethProvider
+	}
+
+	expectHead := func(ethProvider *mocks.MockEVMProviderWithRet, head int64, confirmedNonce uint64) {
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(head)}, nil)
+		ethProvider.EXPECT().NonceAt(gomock.Any(), fromAddress, big.NewInt(head)).Return(confirmedNonce, nil)
+	}
+
+	t.Run("sent to the public mempool once not included in time", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relay := newStubPrivateRelay(t)
+		c, ethProvider := newCommitter(mockCtrl, relay)
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(100)}, nil)
+		txHash, err := c.SendTx(context.Background(), recipient, txData, 50000, big.NewInt(100))
+		assert.NoError(t, err)
+
+		assert.Len(t, relay.received(), 1)
+		assert.Equal(t, uint64(102), c.pendingTxs[7].privateUntil)
+
+		// still private, not replaced
+		expectHead(ethProvider, 102, 7)
+		assert.NoError(t, c.checkPendingTxs(context.Background()))
+		assert.NotNil(t, c.pendingTxs[7].privateTx)
+
+		var publicTx *types.Transaction
+		expectHead(ethProvider, 103, 7)
+		ethProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tx *types.Transaction) (ethcmn.Hash, error) {
+				publicTx = tx
+				return tx.Hash(), nil
+			})
+		assert.NoError(t, c.checkPendingTxs(context.Background()))
+
+		// the very same transaction is made public, and replaced from then on if stuck
+		assert.Equal(t, txHash, publicTx.Hash())
+		assert.Nil(t, c.pendingTxs[7].privateTx)
+		assert.Equal(t, uint64(103), c.pendingTxs[7].sentBlock)
+		assert.Equal(t, []ethcmn.Hash{txHash}, c.pendingTxs[7].txHashes)
+	})
+
+	t.Run("sent to the public mempool if the relay fails", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relay := newStubPrivateRelay(t)
+		relay.fail = true
+		c, ethProvider := newCommitter(mockCtrl, relay)
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&types.Header{Number: big.NewInt(100)}, nil)
+		ethProvider.EXPECT().SendTransactionWithRet(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, tx *types.Transaction) (ethcmn.Hash, error) {
+				return tx.Hash(), nil
+			})
+
+		_, err := c.SendTx(context.Background(), recipient, txData, 50000, big.NewInt(100))
+		assert.NoError(t, err)
+		assert.Nil(t, c.pendingTxs[7].privateTx)
+		assert.Zero(t, c.pendingTxs[7].privateUntil)
+	})
+}
@@ -0,0 +1,83 @@
+package gravity
+
+import (
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+// gravityFilterer only parses logs, so it needs neither an address nor a backend.
+var gravityFilterer, _ = wrappers.NewGravityFilterer(ethcmn.Address{}, nil)
+
+// BatchExecuted returns true if the receipt holds the TransactionBatchExecutedEvent emitted by the Gravity contract
+// for the batch.
+func BatchExecuted(receipt *ethtypes.Receipt, gravityAddress, tokenContract ethcmn.Address, batchNonce uint64) bool {
+	for _, log := range gravityLogs(receipt, gravityAddress) {
+		event, err := gravityFilterer.ParseTransactionBatchExecutedEvent(*log)
+		if err != nil {
+			continue
+		}
+
+		if event.Token == tokenContract && event.BatchNonce.Uint64() == batchNonce {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValsetUpdated returns true if the receipt holds the ValsetUpdatedEvent emitted by the Gravity contract for the
+// valset.
+func ValsetUpdated(receipt *ethtypes.Receipt, gravityAddress ethcmn.Address, valsetNonce uint64) bool {
+	for _, log := range gravityLogs(receipt, gravityAddress) {
+		event, err := gravityFilterer.ParseValsetUpdatedEvent(*log)
+		if err != nil {
+			continue
+		}
+
+		if event.NewValsetNonce.Uint64() == valsetNonce {
+			return true
+		}
+	}
+
+	return false
+}
+
+// LogicCallExecuted returns true if the receipt holds the LogicCallEvent emitted by the Gravity contract for the
+// logic call.
+func LogicCallExecuted(
+	receipt *ethtypes.Receipt,
+	gravityAddress ethcmn.Address,
+	invalidationID []byte,
+	invalidationNonce uint64,
+) bool {
+	for _, log := range gravityLogs(receipt, gravityAddress) {
+		event, err := gravityFilterer.ParseLogicCallEvent(*log)
+		if err != nil {
+			continue
+		}
+
+		if event.InvalidationId == toBytes32(invalidationID) && event.InvalidationNonce.Uint64() == invalidationNonce {
+			return true
+		}
+	}
+
+	return false
+}
+
+// gravityLogs returns the logs of the receipt emitted by the Gravity contract, leaving out the ones of the tokens it
+// called and the anonymous ones.
+func gravityLogs(receipt *ethtypes.Receipt, gravityAddress ethcmn.Address) []*ethtypes.Log {
+	if receipt == nil {
+		return nil
+	}
+
+	logs := make([]*ethtypes.Log, 0, len(receipt.Logs))
+	for _, log := range receipt.Logs {
+		if log.Address == gravityAddress && len(log.Topics) > 0 {
+			logs = append(logs, log)
+		}
+	}
+
+	return logs
+}
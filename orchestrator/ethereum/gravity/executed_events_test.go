@@ -0,0 +1,45 @@
+package gravity
+
+import (
+	"math/big"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchExecuted(t *testing.T) {
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	tokenContract := ethcmn.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7")
+
+	batchExecuted := gravityABI.Events["TransactionBatchExecutedEvent"]
+	data, err := batchExecuted.Inputs.NonIndexed().Pack(big.NewInt(42))
+	assert.Nil(t, err)
+
+	receipt := &ethtypes.Receipt{
+		Logs: []*ethtypes.Log{
+			// an ERC20 Transfer of the token
+			{
+				Address: tokenContract,
+				Topics:  []ethcmn.Hash{ethcmn.HexToHash("0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef")},
+			},
+			{
+				Address: gravityAddress,
+				Topics: []ethcmn.Hash{
+					batchExecuted.ID,
+					ethcmn.BigToHash(big.NewInt(7)),
+					tokenContract.Hash(),
+				},
+				Data: data,
+			},
+		},
+	}
+
+	assert.True(t, BatchExecuted(receipt, gravityAddress, tokenContract, 7))
+	assert.False(t, BatchExecuted(receipt, gravityAddress, tokenContract, 8))
+	assert.False(t, BatchExecuted(receipt, gravityAddress, gravityAddress, 7))
+	assert.False(t, BatchExecuted(receipt, tokenContract, tokenContract, 7))
+	assert.False(t, BatchExecuted(nil, gravityAddress, tokenContract, 7))
+	assert.False(t, ValsetUpdated(receipt, gravityAddress, 7))
+}
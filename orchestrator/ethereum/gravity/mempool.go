@@ -0,0 +1,192 @@
+package gravity
+
+import (
+	"context"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Names of the mempool sources.
+const (
+	MempoolSourceAlchemy   = "alchemy"
+	MempoolSourceSubscribe = "subscribe"
+	MempoolSourceTxPool    = "txpool"
+)
+
+// MempoolSource streams the pending transactions sent to the Gravity contract, so relayers can tell a batch, valset
+// update or logic call is already on its way.
+type MempoolSource interface {
+	// Subscribe sends the pending transactions to the Gravity contract on pendingTxs. It returns once ctx is done, or
+	// with an error if the source fails. It may also send other transactions, they are filtered out by the caller.
+	Subscribe(ctx context.Context, gravityAddress ethcmn.Address, pendingTxs chan<- *RPCTransaction) error
+}
+
+type alchemyMempoolSource struct {
+	client *rpc.Client
+}
+
+// NewAlchemyMempoolSource returns a mempool source using the alchemy_filteredNewFullPendingTransactions subscription
+// of Alchemy's websocket endpoint.
+func NewAlchemyMempoolSource(client *rpc.Client) MempoolSource {
+	return &alchemyMempoolSource{client: client}
+}
+
+func (m *alchemyMempoolSource) Subscribe(
+	ctx context.Context,
+	gravityAddress ethcmn.Address,
+	pendingTxs chan<- *RPCTransaction,
+) error {
+	args := map[string]interface{}{
+		"address": gravityAddress.Hex(),
+	}
+
+	ch := make(chan *RPCTransaction)
+	sub, err := m.client.EthSubscribe(ctx, ch, "alchemy_filteredNewFullPendingTransactions", args)
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to Alchemy pending transactions")
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case pendingTx := <-ch:
+			if !sendPendingTx(ctx, pendingTxs, pendingTx) {
+				return nil
+			}
+
+		case err := <-sub.Err():
+			return errors.Wrap(err, "Alchemy pending transactions subscription failed")
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+type subscriptionMempoolSource struct {
+	logger zerolog.Logger
+	client *rpc.Client
+}
+
+// NewSubscriptionMempoolSource returns a mempool source using the standard newPendingTransactions subscription, which
+// only gives the transaction hashes, and looking up each transaction. The client must be connected over websocket.
+func NewSubscriptionMempoolSource(logger zerolog.Logger, client *rpc.Client) MempoolSource {
+	return &subscriptionMempoolSource{
+		logger: logger.With().Str("module", "mempool_subscription").Logger(),
+		client: client,
+	}
+}
+
+func (m *subscriptionMempoolSource) Subscribe(
+	ctx context.Context,
+	gravityAddress ethcmn.Address,
+	pendingTxs chan<- *RPCTransaction,
+) error {
+	txHashes := make(chan ethcmn.Hash)
+	sub, err := m.client.EthSubscribe(ctx, txHashes, "newPendingTransactions")
+	if err != nil {
+		return errors.Wrap(err, "failed to subscribe to pending transactions")
+	}
+	defer sub.Unsubscribe()
+
+	for {
+		select {
+		case txHash := <-txHashes:
+			var pendingTx *RPCTransaction
+			if err := m.client.CallContext(ctx, &pendingTx, "eth_getTransactionByHash", txHash); err != nil {
+				m.logger.Debug().Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to get pending transaction")
+				continue
+			}
+
+			// The transaction may already be gone.
+			if pendingTx == nil || !isGravityTx(pendingTx, gravityAddress) {
+				continue
+			}
+
+			if !sendPendingTx(ctx, pendingTxs, pendingTx) {
+				return nil
+			}
+
+		case err := <-sub.Err():
+			return errors.Wrap(err, "pending transactions subscription failed")
+
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+type txPoolMempoolSource struct {
+	client       *rpc.Client
+	pollInterval time.Duration
+}
+
+// NewTxPoolMempoolSource returns a mempool source polling the txpool_content method of geth and erigon nodes.
+func NewTxPoolMempoolSource(client *rpc.Client, pollInterval time.Duration) MempoolSource {
+	return &txPoolMempoolSource{
+		client:       client,
+		pollInterval: pollInterval,
+	}
+}
+
+// txPoolContent is the result of txpool_content, the transactions by sender and nonce. Queued transactions can't be
+// mined yet, so they are left out.
+type txPoolContent struct {
+	Pending map[string]map[string]*RPCTransaction `json:"pending"`
+}
+
+func (m *txPoolMempoolSource) Subscribe(
+	ctx context.Context,
+	gravityAddress ethcmn.Address,
+	pendingTxs chan<- *RPCTransaction,
+) error {
+	ticker := time.NewTicker(m.pollInterval)
+	defer ticker.Stop()
+
+	for {
+		var content txPoolContent
+		if err := m.client.CallContext(ctx, &content, "txpool_content"); err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+
+			return errors.Wrap(err, "failed to get the txpool content")
+		}
+
+		for _, senderTxs := range content.Pending {
+			for _, pendingTx := range senderTxs {
+				if !isGravityTx(pendingTx, gravityAddress) {
+					continue
+				}
+
+				if !sendPendingTx(ctx, pendingTxs, pendingTx) {
+					return nil
+				}
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+func isGravityTx(tx *RPCTransaction, gravityAddress ethcmn.Address) bool {
+	return tx != nil && tx.To != nil && *tx.To == gravityAddress
+}
+
+// sendPendingTx returns false if ctx is done before the transaction could be sent.
+func sendPendingTx(ctx context.Context, pendingTxs chan<- *RPCTransaction, pendingTx *RPCTransaction) bool {
+	select {
+	case pendingTxs <- pendingTx:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,70 @@
+package gravity
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTxPoolMempoolSource(t *testing.T) {
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(t, "txpool_content", req.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":{
+			"pending":{
+				"0x0000000000000000000000000000000000000001":{
+					"1":{"hash":"%s","to":"%s","input":"0xaca6b1c100000000"},
+					"2":{"hash":"%s","to":"0x0000000000000000000000000000000000000002","input":"0xaca6b1c100000000"}
+				}
+			},
+			"queued":{
+				"0x0000000000000000000000000000000000000001":{
+					"4":{"hash":"%s","to":"%s","input":"0x8690ff9800000000"}
+				}
+			}
+		}}`,
+			req.ID,
+			ethcmn.HexToHash("0x1").Hex(), gravityAddress.Hex(),
+			ethcmn.HexToHash("0x2").Hex(),
+			ethcmn.HexToHash("0x4").Hex(), gravityAddress.Hex(),
+		)
+	}))
+	defer svr.Close()
+
+	client, err := rpc.DialHTTP(svr.URL)
+	assert.NoError(t, err)
+	defer client.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pendingTxs := make(chan *RPCTransaction)
+	done := make(chan error)
+	go func() {
+		done <- NewTxPoolMempoolSource(client, time.Hour).Subscribe(ctx, gravityAddress, pendingTxs)
+	}()
+
+	// only the pending tx to the Gravity contract is sent
+	pendingTx := <-pendingTxs
+	assert.Equal(t, ethcmn.HexToHash("0x1"), pendingTx.Hash)
+	assert.Equal(t, gravityAddress, *pendingTx.To)
+
+	cancel()
+	assert.NoError(t, <-done)
+}
@@ -50,6 +50,15 @@ type Contract interface {
 		confirms []types.MsgValsetConfirm,
 	) ([]byte, error)
 
+	// EncodeLogicCall encodes a logic call into a tx byte data. This is specially helpful for estimating gas and
+	// detecting identical transactions in the mempool.
+	EncodeLogicCall(
+		ctx context.Context,
+		currentValset types.Valset,
+		call types.OutgoingLogicCall,
+		confirms []types.MsgConfirmLogicCall,
+	) ([]byte, error)
+
 	GetTxBatchNonce(
 		ctx context.Context,
 		erc20ContractAddress ethcmn.Address,
@@ -61,11 +70,25 @@ type Contract interface {
 		callerAddress ethcmn.Address,
 	) (*big.Int, error)
 
+	GetLogicCallNonce(
+		ctx context.Context,
+		invalidationID []byte,
+		callerAddress ethcmn.Address,
+	) (*big.Int, error)
+
 	GetGravityID(
 		ctx context.Context,
 		callerAddress ethcmn.Address,
 	) (string, error)
 
+	// GetLastEventNonce returns the nonce of the last event emitted by the contract as of blockNumber (nil for the
+	// latest block).
+	GetLastEventNonce(
+		ctx context.Context,
+		callerAddress ethcmn.Address,
+		blockNumber *big.Int,
+	) (*big.Int, error)
+
 	GetERC20Symbol(
 		ctx context.Context,
 		erc20ContractAddress ethcmn.Address,
@@ -78,15 +101,23 @@ type Contract interface {
 		callerAddress ethcmn.Address,
 	) (decimals uint8, err error)
 
-	// SubscribeToPendingTxs starts a websocket connection to Alchemy's service that listens for new pending txs made
-	// to the Gravity contract.
-	SubscribeToPendingTxs(ctx context.Context, alchemyWebsocketURL string) error
+	// SubscribeToPendingTxs listens to the pending txs made to the Gravity contract from the mempool source, and
+	// forgets them once mined. It subscribes again if the source fails, and returns once ctx is done.
+	SubscribeToPendingTxs(ctx context.Context, source MempoolSource) error
 
 	// IsPendingTxInput returns true if the input data is found in the pending tx list. If the tx is found but the tx is
 	// older than pendingTxWaitDuration, we consider it stale and return false, so the validator re-sends it.
 	IsPendingTxInput(txData []byte, pendingTxWaitDuration time.Duration) bool
 
 	GetPendingTxInputList() *PendingTxInputList
+
+	// RevertReason replays a reverted transaction and returns the reason it reverted with, nil if the replay doesn't
+	// revert.
+	RevertReason(ctx context.Context, outcome *committer.TxOutcome) (*RevertError, error)
+
+	// SimulateTx runs a transaction to the Gravity contract against the pending block and returns the revert it would
+	// hit, nil if it would succeed.
+	SimulateTx(ctx context.Context, txData []byte, gasCost uint64) (*RevertError, error)
 }
 
 type gravityContract struct {
@@ -175,6 +206,26 @@ func (s *gravityContract) GetGravityID(
 	return string(gravityID[:]), nil
 }
 
+// Gets the nonce of the last event emitted as of the given block
+func (s *gravityContract) GetLastEventNonce(
+	ctx context.Context,
+	callerAddress ethcmn.Address,
+	blockNumber *big.Int,
+) (*big.Int, error) {
+
+	nonce, err := s.ethGravity.StateLastEventNonce(&bind.CallOpts{
+		From:        callerAddress,
+		Context:     ctx,
+		BlockNumber: blockNumber,
+	})
+
+	if err != nil {
+		return nil, errors.Wrap(err, "StateLastEventNonce call failed")
+	}
+
+	return nonce, nil
+}
+
 func (s *gravityContract) GetERC20Symbol(
 	ctx context.Context,
 	erc20ContractAddress ethcmn.Address,
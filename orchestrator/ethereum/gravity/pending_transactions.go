@@ -3,10 +3,20 @@ package gravity
 import (
 	"bytes"
 	"context"
+	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
-	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// pendingTxsPruneInterval is the time between two checks of the pending transactions, to forget the mined ones.
+	pendingTxsPruneInterval = 15 * time.Second
+
+	// mempoolResubscribeDelay is the time we wait before subscribing again to a mempool source that failed.
+	mempoolResubscribeDelay = 30 * time.Second
 )
 
 // PendingTxInput contains the data of a pending transaction and the time we first saw it.
@@ -15,15 +25,22 @@ type PendingTxInput struct {
 	ReceivedTime time.Time
 }
 
-type PendingTxInputList []PendingTxInput
+// PendingTxInputList holds the pending submitBatch, submitLogicCall and updateValset calls to the Gravity contract,
+// keyed by transaction hash, until they are mined.
+type PendingTxInputList struct {
+	mtx sync.Mutex
+	txs map[ethcmn.Hash]PendingTxInput
+}
 
 // RPCTransaction represents a transaction that will serialize to the RPC representation of a transaction
 type RPCTransaction struct {
-	Input hexutil.Bytes `json:"input"`
+	Hash  ethcmn.Hash     `json:"hash"`
+	To    *ethcmn.Address `json:"to"`
+	Input hexutil.Bytes   `json:"input"`
 }
 
-// AddPendingTxInput adds pending submitBatch and updateBatch calls to the Gravity contract to the list of pending
-// transactions, any other transaction is ignored.
+// AddPendingTxInput adds pending submitBatch, submitLogicCall and updateValset calls to the Gravity contract to the list of pending
+// transactions, any other transaction is ignored. A transaction seen again keeps the time it was first seen.
 func (p *PendingTxInputList) AddPendingTxInput(pendingTx *RPCTransaction) {
 	if len(pendingTx.Input) < 4 {
 		// Return if transaction doesn't contain enough data (method IDs are 4 bytes long).
@@ -32,76 +49,141 @@ func (p *PendingTxInputList) AddPendingTxInput(pendingTx *RPCTransaction) {
 
 	submitBatchMethod := gravityABI.Methods["submitBatch"]
 	valsetUpdateMethod := gravityABI.Methods["updateValset"]
+	logicCallMethod := gravityABI.Methods["submitLogicCall"]
 
-	// If it's not a submitBatch or updateValset transaction, ignore it.
+	// If it's not a submitBatch, submitLogicCall or updateValset transaction, ignore it.
 	// The first four bytes of the call data for a function call specifies the function to be called.
 	// Ref: https://docs.soliditylang.org/en/develop/abi-spec.html#function-selector
 	if !bytes.Equal(submitBatchMethod.ID, pendingTx.Input[:4]) &&
-		!bytes.Equal(valsetUpdateMethod.ID, pendingTx.Input[:4]) {
+		!bytes.Equal(valsetUpdateMethod.ID, pendingTx.Input[:4]) &&
+		!bytes.Equal(logicCallMethod.ID, pendingTx.Input[:4]) {
+		return
+	}
+
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	if p.txs == nil {
+		p.txs = map[ethcmn.Hash]PendingTxInput{}
+	}
+
+	if _, ok := p.txs[pendingTx.Hash]; ok {
 		return
 	}
 
-	pendingTxInput := PendingTxInput{
+	p.txs[pendingTx.Hash] = PendingTxInput{
 		InputData:    pendingTx.Input,
 		ReceivedTime: time.Now(),
 	}
+}
+
+// RemovePendingTxInput forgets about a transaction, once it is mined or dropped.
+func (p *PendingTxInputList) RemovePendingTxInput(txHash ethcmn.Hash) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	delete(p.txs, txHash)
+}
+
+// Len returns the number of pending transactions in the list.
+func (p *PendingTxInputList) Len() int {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
+
+	return len(p.txs)
+}
+
+// TxHashes returns the hashes of the pending transactions in the list.
+func (p *PendingTxInputList) TxHashes() []ethcmn.Hash {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
 
-	*p = append(*p, pendingTxInput)
-	// Persisting top 100 pending txs of Gravity contract only.
-	if len(*p) > 100 {
-		(*p)[0] = PendingTxInput{} // to avoid memory leak
-		// Dequeue pending tx input
-		*p = (*p)[1:]
+	hashes := make([]ethcmn.Hash, 0, len(p.txs))
+	for txHash := range p.txs {
+		hashes = append(hashes, txHash)
 	}
+
+	return hashes
 }
 
-func (s *gravityContract) IsPendingTxInput(txData []byte, pendingTxWaitDuration time.Duration) bool {
-	t := time.Now()
+// find returns the pending transaction with the given input data, if any.
+func (p *PendingTxInputList) find(txData []byte) (PendingTxInput, bool) {
+	p.mtx.Lock()
+	defer p.mtx.Unlock()
 
-	for _, pendingTxInput := range s.pendingTxInputList {
+	for _, pendingTxInput := range p.txs {
 		if bytes.Equal(pendingTxInput.InputData, txData) {
-			// If this tx was for too long in the pending list, consider it stale
-			return t.Before(pendingTxInput.ReceivedTime.Add(pendingTxWaitDuration))
+			return pendingTxInput, true
 		}
 	}
-	return false
+
+	return PendingTxInput{}, false
 }
 
-func (s *gravityContract) SubscribeToPendingTxs(ctx context.Context, alchemyWebsocketURL string) error {
-	args := map[string]interface{}{
-		"address": s.gravityAddress.Hex(),
+func (s *gravityContract) IsPendingTxInput(txData []byte, pendingTxWaitDuration time.Duration) bool {
+	pendingTxInput, ok := s.pendingTxInputList.find(txData)
+	if !ok {
+		return false
 	}
 
-	wsClient, err := rpc.Dial(alchemyWebsocketURL)
-	if err != nil {
-		s.logger.Fatal().
-			AnErr("err", err).
-			Str("endpoint", alchemyWebsocketURL).
-			Msg("failed to connect to Alchemy websocket")
-		return err
-	}
+	// If this tx was for too long in the pending list, consider it stale
+	return time.Now().Before(pendingTxInput.ReceivedTime.Add(pendingTxWaitDuration))
+}
 
-	ch := make(chan *RPCTransaction)
-	_, err = wsClient.EthSubscribe(ctx, ch, "alchemy_filteredNewFullPendingTransactions", args)
-	if err != nil {
-		s.logger.Fatal().
-			AnErr("err", err).
-			Str("endpoint", alchemyWebsocketURL).
-			Msg("Failed to subscribe to pending transactions")
-		return err
-	}
+func (s *gravityContract) SubscribeToPendingTxs(ctx context.Context, source MempoolSource) error {
+	pendingTxs := make(chan *RPCTransaction)
+
+	go func() {
+		for {
+			err := source.Subscribe(ctx, s.gravityAddress, pendingTxs)
+			if ctx.Err() != nil {
+				return
+			}
+
+			s.logger.Err(err).
+				Dur("retry_in", mempoolResubscribeDelay).
+				Msg("mempool subscription failed; relays already in the mempool may be sent again")
+
+			select {
+			case <-time.After(mempoolResubscribeDelay):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	pruneTicker := time.NewTicker(pendingTxsPruneInterval)
+	defer pruneTicker.Stop()
 
 	for {
 		select {
-		case pendingTransaction := <-ch:
+		case pendingTransaction := <-pendingTxs:
 			s.pendingTxInputList.AddPendingTxInput(pendingTransaction)
 
+		case <-pruneTicker.C:
+			s.prunePendingTxs(ctx)
+
 		case <-ctx.Done():
 			return nil
 		}
 	}
 }
 
+// prunePendingTxs removes from the pending list the transactions that were mined, or dropped from the mempool.
+func (s *gravityContract) prunePendingTxs(ctx context.Context) {
+	for _, txHash := range s.pendingTxInputList.TxHashes() {
+		_, isPending, err := s.Provider().TransactionByHash(ctx, txHash)
+		switch {
+		case err == ethereum.NotFound:
+			s.pendingTxInputList.RemovePendingTxInput(txHash)
+		case err != nil:
+			s.logger.Debug().Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to check pending transaction")
+		case !isPending:
+			s.pendingTxInputList.RemovePendingTxInput(txHash)
+		}
+	}
+}
+
 func (s *gravityContract) GetPendingTxInputList() *PendingTxInputList {
 	return &s.pendingTxInputList
 }
@@ -1,10 +1,13 @@
 package gravity
 
 import (
+	"context"
+	"math/big"
 	"os"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/golang/mock/gomock"
@@ -20,35 +23,54 @@ func TestAddPendingTxInput(t *testing.T) {
 
 	// add a submitBatch tx
 	txList.AddPendingTxInput(&RPCTransaction{
+		Hash:  ethcmn.HexToHash("0x1"),
 		Input: hexutil.MustDecode("0x8690ff9800000000"),
 	})
 
 	// add a updateValset tx
 	txList.AddPendingTxInput(&RPCTransaction{
+		Hash:  ethcmn.HexToHash("0x2"),
 		Input: hexutil.MustDecode("0xaca6b1c100000000"),
 	})
 
 	// add a tx with no data
 	txList.AddPendingTxInput(&RPCTransaction{
+		Hash:  ethcmn.HexToHash("0x3"),
 		Input: hexutil.MustDecode("0x00"),
 	})
 
 	// try to add a sendToCosmos tx
 	txList.AddPendingTxInput(&RPCTransaction{
+		Hash:  ethcmn.HexToHash("0x4"),
 		Input: hexutil.MustDecode("0x0f21235700000000"),
 	})
 
 	// Only the first 2 TXs should have been added
-	assert.Len(t, txList, 2)
+	assert.Equal(t, 2, txList.Len())
 
+	// The same tx seen again is only kept once
 	for i := 0; i < 110; i++ {
 		txList.AddPendingTxInput(&RPCTransaction{
+			Hash:  ethcmn.HexToHash("0x5"),
 			Input: hexutil.MustDecode("0x8690ff9880000000"),
 		})
 	}
 
-	// The list should be at full capacity now
-	assert.Len(t, txList, 100)
+	assert.Equal(t, 3, txList.Len())
+
+	// Every pending tx is kept until it's mined, there is no cap
+	for i := 0; i < 110; i++ {
+		txList.AddPendingTxInput(&RPCTransaction{
+			Hash:  ethcmn.BigToHash(big.NewInt(int64(100 + i))),
+			Input: hexutil.MustDecode("0x8690ff9880000000"),
+		})
+	}
+
+	assert.Equal(t, 113, txList.Len())
+
+	txList.RemovePendingTxInput(ethcmn.HexToHash("0x1"))
+	assert.Equal(t, 112, txList.Len())
+	assert.NotContains(t, txList.TxHashes(), ethcmn.HexToHash("0x1"))
 }
 
 func TestIsPendingTxInput(t *testing.T) {
@@ -74,6 +96,7 @@ func TestIsPendingTxInput(t *testing.T) {
 
 	// Add a TX
 	gravityContract.GetPendingTxInputList().AddPendingTxInput(&RPCTransaction{
+		Hash:  ethcmn.HexToHash("0x1"),
 		Input: hexutil.MustDecode("0xaca6b1c100000000"),
 	})
 
@@ -86,25 +109,40 @@ func TestIsPendingTxInput(t *testing.T) {
 
 }
 
-// TODO: check if we can actually test this. Maybe move the Fatal call to the caller.
-// func TestSubscribeToPendingTxs(t *testing.T) {
-// 	mockCtrl := gomock.NewController(t)
-// 	defer mockCtrl.Finish()
-// 	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
-// 	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress("0x0")).Return(uint64(0), nil)
+func TestPrunePendingTxs(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress("0x0")).Return(uint64(0), nil)
 
-// 	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
-// 	ethCommitter, _ := committer.NewEthCommitter(
-// 		logger,
-// 		ethcmn.Address{},
-// 		1.0,
-// 		nil,
-// 		mockEvmProvider,
-// 	)
-// 	gravityContract, _ := NewGravityContract(logger, ethCommitter, ethcmn.Address{})
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	ethCommitter, _ := committer.NewEthCommitter(
+		logger,
+		ethcmn.Address{},
+		1.0,
+		1.0,
+		nil,
+		mockEvmProvider,
+	)
 
-// 	err := gravityContract.SubscribeToPendingTxs(context.Background(), "invalidURL")
+	ethGravity, _ := wrappers.NewGravity(ethcmn.Address{}, ethCommitter.Provider())
+
+	contract, _ := NewGravityContract(logger, ethCommitter, ethcmn.Address{}, ethGravity)
+
+	pendingHash := ethcmn.HexToHash("0x1")
+	minedHash := ethcmn.HexToHash("0x2")
+	droppedHash := ethcmn.HexToHash("0x3")
+	for i, txHash := range []ethcmn.Hash{pendingHash, minedHash, droppedHash} {
+		contract.GetPendingTxInputList().AddPendingTxInput(&RPCTransaction{
+			Hash:  txHash,
+			Input: append(hexutil.MustDecode("0xaca6b1c1"), byte(i)),
+		})
+	}
 
-// 	assert.NotNil(t, err)
+	mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), pendingHash).Return(nil, true, nil)
+	mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), minedHash).Return(nil, false, nil)
+	mockEvmProvider.EXPECT().TransactionByHash(gomock.Any(), droppedHash).Return(nil, false, ethereum.NotFound)
 
-// }
+	contract.(*gravityContract).prunePendingTxs(context.Background())
+	assert.Equal(t, []ethcmn.Hash{pendingHash}, contract.GetPendingTxInputList().TxHashes())
+}
@@ -0,0 +1,149 @@
+package gravity
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+)
+
+// staleSubmissionErrors are the Gravity errors a submission reverts with once it is outdated, usually because another
+// relayer landed it, or a newer one, first.
+var staleSubmissionErrors = map[string]bool{
+	"InvalidValsetNonce":    true,
+	"InvalidBatchNonce":     true,
+	"InvalidLogicCallNonce": true,
+	"BatchTimedOut":         true,
+	"LogicCallTimedOut":     true,
+	"IncorrectCheckpoint":   true,
+}
+
+// RevertError is the reason a call to the Gravity contract reverted with, either one of the custom errors of
+// Gravity.sol or a revert reason string.
+type RevertError struct {
+	// Name is the name of the Gravity custom error, empty for a revert reason string.
+	Name   string
+	Args   []interface{}
+	Reason string
+}
+
+func (e *RevertError) Error() string {
+	if e.Name == "" {
+		return e.Reason
+	}
+
+	args := make([]string, 0, len(e.Args))
+	for _, arg := range e.Args {
+		args = append(args, fmt.Sprint(arg))
+	}
+
+	return fmt.Sprintf("%s(%s)", e.Name, strings.Join(args, ", "))
+}
+
+// IsStale returns true if the revert means the submission is outdated: its nonce was already used, it timed out or
+// it was signed by another valset than the one of the contract.
+func (e *RevertError) IsStale() bool {
+	return staleSubmissionErrors[e.Name]
+}
+
+// DecodeRevert returns the revert carried by the error of an eth_call or eth_estimateGas to the Gravity contract, or
+// nil if the error isn't a revert.
+func DecodeRevert(err error) *RevertError {
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return nil
+	}
+
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil
+	}
+
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return nil
+	}
+
+	return decodeRevertData(data)
+}
+
+func decodeRevertData(data []byte) *RevertError {
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return &RevertError{Reason: reason}
+	}
+
+	if len(data) >= 4 {
+		for _, gravityErr := range gravityABI.Errors {
+			if !bytes.Equal(data[:4], gravityErr.ID[:4]) {
+				continue
+			}
+
+			unpacked, err := gravityErr.Unpack(data)
+			if err != nil {
+				break
+			}
+
+			args, _ := unpacked.([]interface{})
+			return &RevertError{Name: gravityErr.Name, Args: args}
+		}
+	}
+
+	return &RevertError{Reason: fmt.Sprintf("unknown revert data %s", hexutil.Encode(data))}
+}
+
+// RevertReason replays a reverted transaction with eth_call on top of the block it was mined in and returns the
+// reason it reverted with. It returns nil if the replay doesn't revert, which usually means the transaction ran out of
+// gas.
+func (s *gravityContract) RevertReason(ctx context.Context, outcome *committer.TxOutcome) (*RevertError, error) {
+	if outcome.Receipt == nil {
+		return nil, errors.New("transaction wasn't mined")
+	}
+
+	msg := ethereum.CallMsg{
+		From: s.FromAddress(),
+		To:   &outcome.Recipient,
+		Gas:  outcome.GasCost,
+		Data: outcome.TxData,
+	}
+
+	_, err := s.Provider().CallContract(ctx, msg, outcome.Receipt.BlockNumber)
+	if err == nil {
+		return nil, nil
+	}
+
+	if revertErr := DecodeRevert(err); revertErr != nil {
+		return revertErr, nil
+	}
+
+	return nil, errors.Wrap(err, "failed to replay transaction")
+}
+
+// SimulateTx runs a transaction to the Gravity contract with eth_call against the pending block. It returns the revert
+// the transaction would hit, nil if it would succeed.
+func (s *gravityContract) SimulateTx(ctx context.Context, txData []byte, gasCost uint64) (*RevertError, error) {
+	msg := ethereum.CallMsg{
+		From: s.FromAddress(),
+		To:   &s.gravityAddress,
+		Gas:  gasCost,
+		Data: txData,
+	}
+
+	_, err := s.Provider().CallContract(ctx, msg, provider.PendingBlockNumber)
+	if err == nil {
+		return nil, nil
+	}
+
+	if revertErr := DecodeRevert(err); revertErr != nil {
+		return revertErr, nil
+	}
+
+	return nil, errors.Wrap(err, "failed to simulate transaction")
+}
@@ -0,0 +1,55 @@
+package gravity
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// dataError mimics the error returned by a node for a reverted call.
+type dataError struct {
+	data string
+}
+
+func (e dataError) Error() string          { return "execution reverted" }
+func (e dataError) ErrorData() interface{} { return e.data }
+
+func TestDecodeRevert(t *testing.T) {
+	t.Run("gravity error", func(t *testing.T) {
+		invalidBatchNonce := gravityABI.Errors["InvalidBatchNonce"]
+		args, err := invalidBatchNonce.Inputs.Pack(big.NewInt(5), big.NewInt(6))
+		assert.Nil(t, err)
+
+		data := append(append([]byte{}, invalidBatchNonce.ID[:4]...), args...)
+
+		revertErr := DecodeRevert(dataError{data: hexutil.Encode(data)})
+		assert.Equal(t, "InvalidBatchNonce", revertErr.Name)
+		assert.Equal(t, []interface{}{big.NewInt(5), big.NewInt(6)}, revertErr.Args)
+		assert.Equal(t, "InvalidBatchNonce(5, 6)", revertErr.Error())
+		assert.True(t, revertErr.IsStale())
+	})
+
+	t.Run("reason string", func(t *testing.T) {
+		// Error("SafeERC20: low-level call failed")
+		data := "0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"5361666545524332303a206c6f772d6c6576656c2063616c6c206661696c6564"
+
+		revertErr := DecodeRevert(dataError{data: data})
+		assert.Equal(t, "", revertErr.Name)
+		assert.Equal(t, "SafeERC20: low-level call failed", revertErr.Error())
+		assert.False(t, revertErr.IsStale())
+	})
+
+	t.Run("unknown revert data", func(t *testing.T) {
+		revertErr := DecodeRevert(dataError{data: "0x12345678"})
+		assert.Equal(t, "unknown revert data 0x12345678", revertErr.Error())
+	})
+
+	t.Run("not a revert", func(t *testing.T) {
+		assert.Nil(t, DecodeRevert(assert.AnError))
+	})
+}
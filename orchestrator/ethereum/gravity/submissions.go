@@ -0,0 +1,139 @@
+package gravity
+
+import (
+	"math/big"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+// Submission is a valset update or a batch submitted to the Gravity contract, as decoded from the transaction input.
+// Exactly one of Valset and Batch is set.
+type Submission struct {
+	// Valset is the new valset of an updateValset call.
+	Valset *types.Valset
+
+	// Batch is the batch of a submitBatch call. The transfers have no ID nor sender, as these are not part of the
+	// checkpoint.
+	Batch *types.OutgoingTxBatch
+
+	// Signers are the members of the current valset that signed, and Signatures their [R || S || V] signatures over
+	// the checkpoint of the valset or the batch. The contract stops checking signatures once enough power is reached,
+	// so the remaining ones may not be valid.
+	Signers    []ethcmn.Address
+	Signatures [][]byte
+}
+
+// DecodeSubmission decodes the input of an updateValset or a submitBatch call to the Gravity contract.
+func DecodeSubmission(input []byte) (*Submission, error) {
+	if len(input) < 4 {
+		return nil, errors.New("transaction input too short")
+	}
+
+	method, err := gravityABI.MethodById(input[:4])
+	if err != nil {
+		return nil, errors.Wrap(err, "not a Gravity contract call")
+	}
+
+	args, err := method.Inputs.Unpack(input[4:])
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to unpack %s call", method.Name)
+	}
+
+	switch method.Name {
+	case "updateValset":
+		newValset := *abi.ConvertType(args[0], new(wrappers.ValsetArgs)).(*wrappers.ValsetArgs)
+		currentValset := *abi.ConvertType(args[1], new(wrappers.ValsetArgs)).(*wrappers.ValsetArgs)
+		sigs := *abi.ConvertType(args[2], new([]wrappers.Signature)).(*[]wrappers.Signature)
+
+		if len(newValset.Powers) != len(newValset.Validators) {
+			return nil, errors.New("malformed updateValset call")
+		}
+
+		valset := &types.Valset{
+			Nonce:        newValset.ValsetNonce.Uint64(),
+			Members:      make([]types.BridgeValidator, 0, len(newValset.Validators)),
+			RewardAmount: sdk.NewIntFromBigInt(newValset.RewardAmount),
+			RewardToken:  newValset.RewardToken.Hex(),
+		}
+
+		for i, validator := range newValset.Validators {
+			valset.Members = append(valset.Members, types.BridgeValidator{
+				Power:           newValset.Powers[i].Uint64(),
+				EthereumAddress: validator.Hex(),
+			})
+		}
+
+		submission := &Submission{Valset: valset}
+		if err := submission.setSignatures(currentValset, sigs); err != nil {
+			return nil, err
+		}
+
+		return submission, nil
+
+	case "submitBatch":
+		currentValset := *abi.ConvertType(args[0], new(wrappers.ValsetArgs)).(*wrappers.ValsetArgs)
+		sigs := *abi.ConvertType(args[1], new([]wrappers.Signature)).(*[]wrappers.Signature)
+		amounts := args[2].([]*big.Int)
+		destinations := args[3].([]ethcmn.Address)
+		fees := args[4].([]*big.Int)
+		tokenContract := args[6].(ethcmn.Address).Hex()
+
+		if len(destinations) != len(amounts) || len(fees) != len(amounts) {
+			return nil, errors.New("malformed submitBatch call")
+		}
+
+		batch := &types.OutgoingTxBatch{
+			BatchNonce:    args[5].(*big.Int).Uint64(),
+			BatchTimeout:  args[7].(*big.Int).Uint64(),
+			TokenContract: tokenContract,
+			Transactions:  make([]types.OutgoingTransferTx, 0, len(amounts)),
+		}
+
+		for i, amount := range amounts {
+			batch.Transactions = append(batch.Transactions, types.OutgoingTransferTx{
+				DestAddress: destinations[i].Hex(),
+				Erc20Token:  types.ERC20Token{Contract: tokenContract, Amount: sdk.NewIntFromBigInt(amount)},
+				Erc20Fee:    types.ERC20Token{Contract: tokenContract, Amount: sdk.NewIntFromBigInt(fees[i])},
+			})
+		}
+
+		submission := &Submission{Batch: batch}
+		if err := submission.setSignatures(currentValset, sigs); err != nil {
+			return nil, err
+		}
+
+		return submission, nil
+
+	default:
+		return nil, errors.Errorf("not a valset update nor a batch: %s", method.Name)
+	}
+}
+
+// setSignatures keeps the signatures of the members that signed, in the format MsgValsetConfirm and MsgConfirmBatch
+// use; a zero V means the member didn't sign (see checkAndRepackSigs).
+func (s *Submission) setSignatures(currentValset wrappers.ValsetArgs, sigs []wrappers.Signature) error {
+	if len(sigs) != len(currentValset.Validators) {
+		return errors.New("malformed signatures")
+	}
+
+	for i, sig := range sigs {
+		if sig.V == 0 {
+			continue
+		}
+
+		bz := make([]byte, 0, 65)
+		bz = append(bz, sig.R[:]...)
+		bz = append(bz, sig.S[:]...)
+		bz = append(bz, sig.V)
+
+		s.Signers = append(s.Signers, currentValset.Validators[i])
+		s.Signatures = append(s.Signatures, bz)
+	}
+
+	return nil
+}
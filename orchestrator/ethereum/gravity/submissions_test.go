@@ -0,0 +1,89 @@
+package gravity
+
+import (
+	"math/big"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+func TestDecodeSubmission(t *testing.T) {
+	currentValset := wrappers.ValsetArgs{
+		Validators:   []ethcmn.Address{ethcmn.HexToAddress("0x1"), ethcmn.HexToAddress("0x2")},
+		Powers:       []*big.Int{big.NewInt(3000), big.NewInt(1000)},
+		ValsetNonce:  big.NewInt(1),
+		RewardAmount: big.NewInt(0),
+		RewardToken:  ethcmn.Address{},
+	}
+
+	sigs := []wrappers.Signature{
+		{V: 27, R: [32]byte{1}, S: [32]byte{2}},
+		{V: 0},
+	}
+
+	t.Run("updateValset", func(t *testing.T) {
+		newValset := wrappers.ValsetArgs{
+			Validators:   []ethcmn.Address{ethcmn.HexToAddress("0x3")},
+			Powers:       []*big.Int{big.NewInt(4000)},
+			ValsetNonce:  big.NewInt(2),
+			RewardAmount: big.NewInt(10),
+			RewardToken:  ethcmn.HexToAddress("0x4"),
+		}
+
+		input, err := gravityABI.Pack("updateValset", newValset, currentValset, sigs)
+		assert.Nil(t, err)
+
+		submission, err := DecodeSubmission(input)
+		assert.Nil(t, err)
+		assert.Nil(t, submission.Batch)
+		assert.Equal(t, uint64(2), submission.Valset.Nonce)
+		assert.Len(t, submission.Valset.Members, 1)
+		assert.Equal(t, uint64(4000), submission.Valset.Members[0].Power)
+		assert.Equal(t, ethcmn.HexToAddress("0x3").Hex(), submission.Valset.Members[0].EthereumAddress)
+		assert.Equal(t, ethcmn.HexToAddress("0x4").Hex(), submission.Valset.RewardToken)
+		assert.Equal(t, []ethcmn.Address{ethcmn.HexToAddress("0x1")}, submission.Signers)
+		assert.Len(t, submission.Signatures, 1)
+		assert.Len(t, submission.Signatures[0], 65)
+		assert.Equal(t, byte(27), submission.Signatures[0][64])
+	})
+
+	t.Run("submitBatch", func(t *testing.T) {
+		tokenContract := ethcmn.HexToAddress("0x5")
+
+		input, err := gravityABI.Pack("submitBatch",
+			currentValset,
+			sigs,
+			[]*big.Int{big.NewInt(100)},
+			[]ethcmn.Address{ethcmn.HexToAddress("0x6")},
+			[]*big.Int{big.NewInt(1)},
+			big.NewInt(7),
+			tokenContract,
+			big.NewInt(1000),
+		)
+		assert.Nil(t, err)
+
+		submission, err := DecodeSubmission(input)
+		assert.Nil(t, err)
+		assert.Nil(t, submission.Valset)
+		assert.Equal(t, uint64(7), submission.Batch.BatchNonce)
+		assert.Equal(t, uint64(1000), submission.Batch.BatchTimeout)
+		assert.Equal(t, tokenContract.Hex(), submission.Batch.TokenContract)
+		assert.Len(t, submission.Batch.Transactions, 1)
+		assert.Equal(t, int64(100), submission.Batch.Transactions[0].Erc20Token.Amount.Int64())
+		assert.Equal(t, int64(1), submission.Batch.Transactions[0].Erc20Fee.Amount.Int64())
+		assert.Equal(t, []ethcmn.Address{ethcmn.HexToAddress("0x1")}, submission.Signers)
+	})
+
+	t.Run("not a submission", func(t *testing.T) {
+		_, err := DecodeSubmission([]byte{0x1, 0x2})
+		assert.NotNil(t, err)
+
+		input, err := gravityABI.Pack("state_lastValsetNonce")
+		assert.Nil(t, err)
+
+		_, err = DecodeSubmission(input)
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,127 @@
+package gravity
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+func (s *gravityContract) EncodeLogicCall(
+	ctx context.Context,
+	currentValset types.Valset,
+	call types.OutgoingLogicCall,
+	confirms []types.MsgConfirmLogicCall,
+) ([]byte, error) {
+
+	sigs, err := checkLogicCallSigsAndRepack(currentValset, confirms)
+	if err != nil {
+		s.logger.Debug().
+			AnErr("err", err).
+			Msg("confirmations check failed")
+		return nil, nil
+	}
+
+	currentValsetArs := wrappers.ValsetArgs{
+		Validators:   sigs.validators,
+		Powers:       sigs.powers,
+		ValsetNonce:  new(big.Int).SetUint64(currentValset.Nonce),
+		RewardAmount: currentValset.RewardAmount.BigInt(),
+		RewardToken:  ethcmn.HexToAddress(currentValset.RewardToken),
+	}
+
+	sigArray := []wrappers.Signature{}
+	for i := range sigs.v {
+		sigArray = append(sigArray, wrappers.Signature{
+			V: sigs.v[i],
+			R: sigs.r[i],
+			S: sigs.s[i],
+		})
+	}
+
+	txData, err := gravityABI.Pack("submitLogicCall",
+		currentValsetArs,
+		sigArray,
+		getLogicCallArgs(call),
+	)
+	if err != nil {
+		s.logger.Err(err).Msg("ABI Pack (Gravity submitLogicCall) method")
+		return nil, err
+	}
+
+	return txData, nil
+}
+
+// Gets the latest logic call nonce for the given invalidation ID
+func (s *gravityContract) GetLogicCallNonce(
+	ctx context.Context,
+	invalidationID []byte,
+	callerAddress ethcmn.Address,
+) (*big.Int, error) {
+
+	nonce, err := s.ethGravity.LastLogicCallNonce(&bind.CallOpts{
+		From:    callerAddress,
+		Context: ctx,
+	}, toBytes32(invalidationID))
+
+	if err != nil {
+		return nil, errors.Wrap(err, "LastLogicCallNonce call failed")
+	}
+
+	return nonce, nil
+}
+
+func getLogicCallArgs(call types.OutgoingLogicCall) wrappers.LogicCallArgs {
+	args := wrappers.LogicCallArgs{
+		TransferAmounts:        make([]*big.Int, len(call.Transfers)),
+		TransferTokenContracts: make([]ethcmn.Address, len(call.Transfers)),
+		FeeAmounts:             make([]*big.Int, len(call.Fees)),
+		FeeTokenContracts:      make([]ethcmn.Address, len(call.Fees)),
+		LogicContractAddress:   ethcmn.HexToAddress(call.LogicContractAddress),
+		Payload:                call.Payload,
+		TimeOut:                new(big.Int).SetUint64(call.Timeout),
+		InvalidationId:         toBytes32(call.InvalidationId),
+		InvalidationNonce:      new(big.Int).SetUint64(call.InvalidationNonce),
+	}
+
+	for i, transfer := range call.Transfers {
+		args.TransferAmounts[i] = transfer.Amount.BigInt()
+		args.TransferTokenContracts[i] = ethcmn.HexToAddress(transfer.Contract)
+	}
+
+	for i, fee := range call.Fees {
+		args.FeeAmounts[i] = fee.Amount.BigInt()
+		args.FeeTokenContracts[i] = ethcmn.HexToAddress(fee.Contract)
+	}
+
+	return args
+}
+
+// toBytes32 copies b into a fixed size array, the same way the Cosmos module does when computing the logic call
+// checkpoint.
+func toBytes32(b []byte) (out [32]byte) {
+	copy(out[:], b)
+	return
+}
+
+// checkLogicCallSigsAndRepack checks all the signatures for a logic call (confirmations), assembles them into the
+// expected format and checks if the power of the signatures would be enough to send this call to Ethereum.
+func checkLogicCallSigsAndRepack(valset types.Valset, confirms []types.MsgConfirmLogicCall) (*RepackedSigs, error) {
+	if len(confirms) == 0 {
+		return nil, errors.New("no signatures in logic call confirmation")
+	}
+
+	genericConfirms := make([]genericConfirm, len(confirms))
+	for i, c := range confirms {
+		genericConfirms[i] = genericConfirm{
+			EthSigner: c.EthSigner,
+			Signature: c.Signature,
+		}
+	}
+
+	return checkAndRepackSigs(valset, genericConfirms)
+}
@@ -0,0 +1,213 @@
+package gravity
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+func TestEncodeLogicCall(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress("0x0")).Return(uint64(0), nil)
+
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	ethCommitter, _ := committer.NewEthCommitter(
+		logger,
+		ethcmn.Address{},
+		1.0,
+		1.0,
+		nil,
+		mockEvmProvider,
+	)
+
+	valset := types.Valset{
+		Nonce:  1,
+		Height: 1111,
+		Members: []types.BridgeValidator{
+			{
+				EthereumAddress: ethcmn.HexToAddress("0x0").Hex(),
+				Power:           1111111111,
+			},
+			{
+				EthereumAddress: ethcmn.HexToAddress("0x1").Hex(),
+				Power:           2212121212,
+			},
+			{
+				EthereumAddress: ethcmn.HexToAddress("0x2").Hex(),
+				Power:           123456,
+			},
+		},
+		RewardAmount: sdk.NewInt(0),
+	}
+
+	confirms := []types.MsgConfirmLogicCall{
+		{
+			EthSigner: ethcmn.HexToAddress("0x0").Hex(),
+			Signature: "0xaae54ee7e285fbb0275279143abc4c554e5314e7b417ecac83a5984a964facbaad68866a2841c3e83ddf125a2985566261c4014f9f960ec60253aebcda9513a9b4",
+		},
+		{
+			EthSigner: ethcmn.HexToAddress("0x1").Hex(),
+			Signature: "0xaae54ee7e285fbb0275279143abc4c554e5314e7b417ecac83a5984a964facbaad68866a2841c3e83ddf125a2985566261c4014f9f960ec60253aebcda9513a9b4",
+		},
+	}
+
+	call := types.OutgoingLogicCall{
+		Transfers: []types.ERC20Token{
+			{
+				Contract: ethcmn.HexToAddress("0x1").Hex(),
+				Amount:   sdk.NewInt(10000),
+			},
+		},
+		Fees: []types.ERC20Token{
+			{
+				Contract: ethcmn.HexToAddress("0x1").Hex(),
+				Amount:   sdk.NewInt(100),
+			},
+		},
+		LogicContractAddress: ethcmn.HexToAddress("0x3").Hex(),
+		Payload:              []byte{0x1, 0x2, 0x3},
+		Timeout:              11111,
+		InvalidationId:       []byte{0xa},
+		InvalidationNonce:    2,
+	}
+
+	ethGravity, _ := wrappers.NewGravity(ethcmn.Address{}, ethCommitter.Provider())
+	gravityContract, _ := NewGravityContract(logger, ethCommitter, ethcmn.Address{}, ethGravity)
+
+	t.Run("ok", func(t *testing.T) {
+		txData, err := gravityContract.EncodeLogicCall(context.Background(), valset, call, confirms)
+		assert.Nil(t, err)
+
+		method := gravityABI.Methods["submitLogicCall"]
+		assert.Equal(t, method.ID, txData[:4])
+
+		args, err := method.Inputs.Unpack(txData[4:])
+		assert.Nil(t, err)
+		assert.Len(t, args, 3)
+	})
+
+	t.Run("not enough signatures", func(t *testing.T) {
+		txData, err := gravityContract.EncodeLogicCall(context.Background(), valset, call, confirms[:1])
+		assert.Nil(t, err)
+		assert.Nil(t, txData)
+	})
+}
+
+func TestGetLogicCallNonce(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+
+	nonceHex := hexutil.MustDecode("0x0000000000000000000000000000000000000000000000000000000000000003")
+
+	mockEvmProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+	mockEvmProvider.EXPECT().PendingNonceAt(gomock.Any(), ethcmn.HexToAddress("0x0")).Return(uint64(0), nil)
+	mockEvmProvider.EXPECT().
+		CallContract(
+			gomock.Any(),
+			gomock.AssignableToTypeOf(ethereum.CallMsg{}),
+			nil,
+		).
+		Return(
+			nonceHex,
+			nil,
+		)
+
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	ethCommitter, _ := committer.NewEthCommitter(
+		logger,
+		ethcmn.Address{},
+		1.0,
+		1.0,
+		nil,
+		mockEvmProvider,
+	)
+
+	ethGravity, _ := wrappers.NewGravity(ethcmn.Address{}, ethCommitter.Provider())
+	gravityContract, _ := NewGravityContract(logger, ethCommitter, ethcmn.Address{}, ethGravity)
+	nonce, err := gravityContract.GetLogicCallNonce(context.Background(), []byte{0xa}, ethcmn.HexToAddress("0x0"))
+
+	assert.Nil(t, err)
+	assert.Equal(t, big.NewInt(3), nonce)
+}
+
+func TestGetLogicCallArgs(t *testing.T) {
+	call := types.OutgoingLogicCall{
+		Transfers: []types.ERC20Token{
+			{
+				Contract: ethcmn.HexToAddress("0x1").Hex(),
+				Amount:   sdk.NewInt(10000),
+			},
+		},
+		Fees: []types.ERC20Token{
+			{
+				Contract: ethcmn.HexToAddress("0x2").Hex(),
+				Amount:   sdk.NewInt(100),
+			},
+		},
+		LogicContractAddress: ethcmn.HexToAddress("0x3").Hex(),
+		Payload:              []byte{0x1, 0x2, 0x3},
+		Timeout:              11111,
+		InvalidationId:       []byte{0xa},
+		InvalidationNonce:    2,
+	}
+
+	args := getLogicCallArgs(call)
+	assert.Equal(t, []*big.Int{big.NewInt(10000)}, args.TransferAmounts)
+	assert.Equal(t, []ethcmn.Address{ethcmn.HexToAddress("0x1")}, args.TransferTokenContracts)
+	assert.Equal(t, []*big.Int{big.NewInt(100)}, args.FeeAmounts)
+	assert.Equal(t, []ethcmn.Address{ethcmn.HexToAddress("0x2")}, args.FeeTokenContracts)
+	assert.Equal(t, ethcmn.HexToAddress("0x3"), args.LogicContractAddress)
+	assert.Equal(t, big.NewInt(11111), args.TimeOut)
+	assert.Equal(t, [32]byte{0xa}, args.InvalidationId)
+	assert.Equal(t, big.NewInt(2), args.InvalidationNonce)
+}
+
+func TestCheckLogicCallSigsAndRepack(t *testing.T) {
+	valset := types.Valset{
+		Members: []types.BridgeValidator{
+			{
+				EthereumAddress: ethcmn.HexToAddress("0x0").Hex(),
+				Power:           1111111111,
+			},
+			{
+				EthereumAddress: ethcmn.HexToAddress("0x1").Hex(),
+				Power:           2212121212,
+			},
+		},
+	}
+
+	_, err := checkLogicCallSigsAndRepack(valset, []types.MsgConfirmLogicCall{})
+	assert.EqualError(t, err, "no signatures in logic call confirmation")
+
+	confirms := []types.MsgConfirmLogicCall{
+		{
+			EthSigner: ethcmn.HexToAddress("0x0").Hex(),
+			Signature: "0xaae54ee7e285fbb0275279143abc4c554e5314e7b417ecac83a5984a964facbaad68866a2841c3e83ddf125a2985566261c4014f9f960ec60253aebcda9513a9b4",
+		},
+		{
+			EthSigner: ethcmn.HexToAddress("0x1").Hex(),
+			Signature: "0xaae54ee7e285fbb0275279143abc4c554e5314e7b417ecac83a5984a964facbaad68866a2841c3e83ddf125a2985566261c4014f9f960ec60253aebcda9513a9b4",
+		},
+	}
+
+	repackedSigs, err := checkLogicCallSigsAndRepack(valset, confirms)
+	assert.Nil(t, err)
+	assert.Equal(t, []ethcmn.Address{ethcmn.HexToAddress("0x0"), ethcmn.HexToAddress("0x1")}, repackedSigs.validators)
+	assert.Equal(t, []*big.Int{big.NewInt(1111111111), big.NewInt(2212121212)}, repackedSigs.powers)
+}
@@ -0,0 +1,205 @@
+package keystore
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/pkg/errors"
+)
+
+// JSON-RPC methods a remote signer can sign personal messages with.
+const (
+	// PersonalSignMethod is supported by Web3Signer and most nodes.
+	PersonalSignMethod = "personal_sign"
+
+	// AccountSignDataMethod is supported by Clef.
+	AccountSignDataMethod = "account_signData"
+)
+
+const remoteSignerTimeout = 30 * time.Second
+
+// RemoteSigner sends signing requests to an external signer (Clef, Web3Signer...) over JSON-RPC, so the orchestrator
+// host never holds the key. Every signature it gets back is checked against the account and the request, so a
+// misbehaving signer can't make us broadcast something else.
+type RemoteSigner struct {
+	rc         *rpc.Client
+	account    ethcmn.Address
+	chainID    *big.Int
+	signMethod string
+}
+
+func NewRemoteSigner(rc *rpc.Client, account ethcmn.Address, chainID uint64, signMethod string) (*RemoteSigner, error) {
+	if signMethod != PersonalSignMethod && signMethod != AccountSignDataMethod {
+		return nil, errors.Errorf(
+			"unsupported remote signer method %s, must be %s or %s",
+			signMethod,
+			PersonalSignMethod,
+			AccountSignDataMethod,
+		)
+	}
+
+	return &RemoteSigner{
+		rc:         rc,
+		account:    account,
+		chainID:    new(big.Int).SetUint64(chainID),
+		signMethod: signMethod,
+	}, nil
+}
+
+// signTxArgs are the eth_signTransaction arguments.
+type signTxArgs struct {
+	From                 ethcmn.Address    `json:"from"`
+	To                   *ethcmn.Address   `json:"to"`
+	Gas                  hexutil.Uint64    `json:"gas"`
+	GasPrice             *hexutil.Big      `json:"gasPrice,omitempty"`
+	MaxFeePerGas         *hexutil.Big      `json:"maxFeePerGas,omitempty"`
+	MaxPriorityFeePerGas *hexutil.Big      `json:"maxPriorityFeePerGas,omitempty"`
+	Value                *hexutil.Big      `json:"value"`
+	Nonce                hexutil.Uint64    `json:"nonce"`
+	Data                 hexutil.Bytes     `json:"data"`
+	AccessList           *types.AccessList `json:"accessList,omitempty"`
+	ChainID              *hexutil.Big      `json:"chainId"`
+}
+
+// signTxResult is the eth_signTransaction result of signers that return the raw transaction together with its
+// decoded fields, like Clef and Geth. Others, like Web3Signer, only return the raw transaction.
+type signTxResult struct {
+	Raw hexutil.Bytes `json:"raw"`
+}
+
+func (s *RemoteSigner) SignerFn() SignerFn {
+	return func(from ethcmn.Address, tx *types.Transaction) (*types.Transaction, error) {
+		if from != s.account {
+			return nil, errors.New("from address mismatch")
+		}
+
+		args := signTxArgs{
+			From:    from,
+			To:      tx.To(),
+			Gas:     hexutil.Uint64(tx.Gas()),
+			Value:   (*hexutil.Big)(tx.Value()),
+			Nonce:   hexutil.Uint64(tx.Nonce()),
+			Data:    tx.Data(),
+			ChainID: (*hexutil.Big)(s.chainID),
+		}
+
+		if tx.Type() == types.DynamicFeeTxType {
+			args.MaxFeePerGas = (*hexutil.Big)(tx.GasFeeCap())
+			args.MaxPriorityFeePerGas = (*hexutil.Big)(tx.GasTipCap())
+		} else {
+			args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+		}
+
+		if tx.Type() != types.LegacyTxType {
+			accessList := tx.AccessList()
+			args.AccessList = &accessList
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), remoteSignerTimeout)
+		defer cancel()
+
+		var res json.RawMessage
+		if err := s.rc.CallContext(ctx, &res, "eth_signTransaction", args); err != nil {
+			return nil, errors.Wrap(err, "remote signer failed to sign the transaction")
+		}
+
+		raw, err := decodeSignTxResult(res)
+		if err != nil {
+			return nil, err
+		}
+
+		signedTx := new(types.Transaction)
+		if err := signedTx.UnmarshalBinary(raw); err != nil {
+			return nil, errors.Wrap(err, "failed to decode the transaction signed by the remote signer")
+		}
+
+		signer := types.LatestSignerForChainID(s.chainID)
+		if signer.Hash(signedTx) != signer.Hash(tx) {
+			return nil, errors.New("remote signer signed a different transaction")
+		}
+
+		sender, err := types.Sender(signer, signedTx)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to recover the sender of the transaction signed by the remote signer")
+		} else if sender != from {
+			return nil, errors.Errorf("remote signer signed the transaction with %s instead of %s", sender.Hex(), from.Hex())
+		}
+
+		return signedTx, nil
+	}
+}
+
+func decodeSignTxResult(res json.RawMessage) ([]byte, error) {
+	var raw hexutil.Bytes
+	if err := json.Unmarshal(res, &raw); err == nil {
+		return raw, nil
+	}
+
+	var result signTxResult
+	if err := json.Unmarshal(res, &result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode the remote signer response")
+	}
+
+	if len(result.Raw) == 0 {
+		return nil, errors.New("remote signer returned no transaction")
+	}
+
+	return result.Raw, nil
+}
+
+func (s *RemoteSigner) PersonalSignFn() PersonalSignFn {
+	return func(from ethcmn.Address, data []byte) (sig []byte, err error) {
+		if from != s.account {
+			return nil, errors.New("from address mismatch")
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), remoteSignerTimeout)
+		defer cancel()
+
+		var res hexutil.Bytes
+		switch s.signMethod {
+		case AccountSignDataMethod:
+			err = s.rc.CallContext(ctx, &res, AccountSignDataMethod, accounts.MimetypeTextPlain, from, hexutil.Bytes(data))
+		default:
+			err = s.rc.CallContext(ctx, &res, PersonalSignMethod, hexutil.Bytes(data), from)
+		}
+
+		if err != nil {
+			return nil, errors.Wrap(err, "remote signer failed to sign the message")
+		}
+
+		return checkPersonalSignature(from, data, res)
+	}
+}
+
+// checkPersonalSignature makes sure the signature returned by the remote signer is over data and from the account.
+// Remote signers return the recovery ID as 27 or 28, while we use 0 or 1 like crypto.Sign.
+func checkPersonalSignature(from ethcmn.Address, data []byte, sig []byte) ([]byte, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, errors.Errorf("remote signer returned a signature of %d bytes", len(sig))
+	}
+
+	sig = append([]byte(nil), sig...)
+	if sig[crypto.RecoveryIDOffset] >= 27 {
+		sig[crypto.RecoveryIDOffset] -= 27
+	}
+
+	pubKey, err := crypto.SigToPub(accounts.TextHash(data), sig)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to recover the signer of the remote signature")
+	}
+
+	if signer := crypto.PubkeyToAddress(*pubKey); signer != from {
+		return nil, errors.Errorf("remote signer signed the message with %s instead of %s", signer.Hex(), from.Hex())
+	}
+
+	return sig, nil
+}
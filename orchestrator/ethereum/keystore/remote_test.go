@@ -0,0 +1,195 @@
+package keystore
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/assert"
+)
+
+// stubSigner is a minimal remote signer holding a private key, answering the JSON-RPC methods of Clef and Web3Signer.
+type stubSigner struct {
+	key     *ecdsa.PrivateKey
+	chainID *big.Int
+
+	// rawOnly makes eth_signTransaction return only the raw transaction, like Web3Signer.
+	rawOnly bool
+
+	// tamper makes the signer sign something else than what it was asked to.
+	tamper bool
+}
+
+type stubEthService struct{ s *stubSigner }
+
+func (e *stubEthService) SignTransaction(args signTxArgs) (interface{}, error) {
+	nonce := uint64(args.Nonce)
+	if e.s.tamper {
+		nonce++
+	}
+
+	var txData types.TxData
+	if args.MaxFeePerGas != nil {
+		txData = &types.DynamicFeeTx{
+			ChainID:   e.s.chainID,
+			Nonce:     nonce,
+			GasTipCap: args.MaxPriorityFeePerGas.ToInt(),
+			GasFeeCap: args.MaxFeePerGas.ToInt(),
+			Gas:       uint64(args.Gas),
+			To:        args.To,
+			Value:     args.Value.ToInt(),
+			Data:      args.Data,
+		}
+	} else {
+		txData = &types.LegacyTx{
+			Nonce:    nonce,
+			GasPrice: args.GasPrice.ToInt(),
+			Gas:      uint64(args.Gas),
+			To:       args.To,
+			Value:    args.Value.ToInt(),
+			Data:     args.Data,
+		}
+	}
+
+	tx, err := types.SignNewTx(e.s.key, types.LatestSignerForChainID(e.s.chainID), txData)
+	if err != nil {
+		return nil, err
+	}
+
+	raw, err := tx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	if e.s.rawOnly {
+		return hexutil.Bytes(raw), nil
+	}
+
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": tx}, nil
+}
+
+func (s *stubSigner) signText(data []byte) (hexutil.Bytes, error) {
+	if s.tamper {
+		data = append(data, 0x00)
+	}
+
+	sig, err := crypto.Sign(accounts.TextHash(data), s.key)
+	if err != nil {
+		return nil, err
+	}
+
+	sig[crypto.RecoveryIDOffset] += 27
+	return sig, nil
+}
+
+type stubPersonalService struct{ s *stubSigner }
+
+func (p *stubPersonalService) Sign(data hexutil.Bytes, _ ethcmn.Address) (hexutil.Bytes, error) {
+	return p.s.signText(data)
+}
+
+type stubAccountService struct{ s *stubSigner }
+
+func (a *stubAccountService) SignData(_ string, _ ethcmn.Address, data hexutil.Bytes) (hexutil.Bytes, error) {
+	return a.s.signText(data)
+}
+
+func newStubSignerClient(t *testing.T, s *stubSigner) *rpc.Client {
+	server := rpc.NewServer()
+	assert.NoError(t, server.RegisterName("eth", &stubEthService{s}))
+	assert.NoError(t, server.RegisterName("personal", &stubPersonalService{s}))
+	assert.NoError(t, server.RegisterName("account", &stubAccountService{s}))
+
+	return rpc.DialInProc(server)
+}
+
+func TestRemoteSigner(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	to := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	chainID := big.NewInt(5)
+
+	legacyTx := types.NewTx(&types.LegacyTx{
+		Nonce:    1,
+		GasPrice: big.NewInt(100),
+		Gas:      21000,
+		To:       &to,
+		Value:    big.NewInt(0),
+		Data:     []byte{0x01},
+	})
+
+	dynamicFeeTx := types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainID,
+		Nonce:     2,
+		GasTipCap: big.NewInt(2),
+		GasFeeCap: big.NewInt(100),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(0),
+		Data:      []byte{0x01},
+	})
+
+	t.Run("sign transactions", func(t *testing.T) {
+		for _, rawOnly := range []bool{false, true} {
+			rc := newStubSignerClient(t, &stubSigner{key: key, chainID: chainID, rawOnly: rawOnly})
+
+			remoteSigner, err := NewRemoteSigner(rc, from, chainID.Uint64(), PersonalSignMethod)
+			assert.NoError(t, err)
+
+			for _, tx := range []*types.Transaction{legacyTx, dynamicFeeTx} {
+				signedTx, err := remoteSigner.SignerFn()(from, tx)
+				assert.NoError(t, err)
+
+				sender, err := types.Sender(types.LatestSignerForChainID(chainID), signedTx)
+				assert.NoError(t, err)
+				assert.Equal(t, from, sender)
+				assert.Equal(t, tx.Nonce(), signedTx.Nonce())
+			}
+		}
+	})
+
+	t.Run("sign messages", func(t *testing.T) {
+		privateKeySignFn, err := PrivateKeyPersonalSignFn(key)
+		assert.NoError(t, err)
+
+		expected, err := privateKeySignFn(from, []byte("checkpoint"))
+		assert.NoError(t, err)
+
+		for _, method := range []string{PersonalSignMethod, AccountSignDataMethod} {
+			rc := newStubSignerClient(t, &stubSigner{key: key, chainID: chainID})
+
+			remoteSigner, err := NewRemoteSigner(rc, from, chainID.Uint64(), method)
+			assert.NoError(t, err)
+
+			sig, err := remoteSigner.PersonalSignFn()(from, []byte("checkpoint"))
+			assert.NoError(t, err)
+			assert.Equal(t, expected, sig)
+		}
+	})
+
+	t.Run("tampering signer", func(t *testing.T) {
+		rc := newStubSignerClient(t, &stubSigner{key: key, chainID: chainID, tamper: true})
+
+		remoteSigner, err := NewRemoteSigner(rc, from, chainID.Uint64(), PersonalSignMethod)
+		assert.NoError(t, err)
+
+		_, err = remoteSigner.SignerFn()(from, legacyTx)
+		assert.EqualError(t, err, "remote signer signed a different transaction")
+
+		_, err = remoteSigner.PersonalSignFn()(from, []byte("checkpoint"))
+		assert.Error(t, err)
+	})
+
+	t.Run("unsupported method", func(t *testing.T) {
+		_, err := NewRemoteSigner(nil, from, chainID.Uint64(), "eth_sign")
+		assert.EqualError(t, err, "unsupported remote signer method eth_sign, must be personal_sign or account_signData")
+	})
+}
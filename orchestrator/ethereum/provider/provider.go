@@ -21,6 +21,7 @@ type EVMProvider interface {
 	bind.ContractFilterer
 
 	PendingNonceAt(ctx context.Context, account ethcmn.Address) (uint64, error)
+	NonceAt(ctx context.Context, account ethcmn.Address, blockNumber *big.Int) (uint64, error)
 	PendingCodeAt(ctx context.Context, account ethcmn.Address) ([]byte, error)
 	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
 	SuggestGasPrice(ctx context.Context) (*big.Int, error)
@@ -28,9 +29,39 @@ type EVMProvider interface {
 	TransactionReceipt(ctx context.Context, txHash ethcmn.Hash) (*types.Receipt, error)
 	SendTransaction(ctx context.Context, tx *types.Transaction) error
 	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	BlockByHash(ctx context.Context, hash ethcmn.Hash) (*types.Block, error)
 	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	ChainID(ctx context.Context) (*big.Int, error)
+
+	// FeeHistory returns the base fees and the priority fees paid at the given percentiles of the blockCount blocks
+	// up to lastBlock (nil for the latest block).
+	FeeHistory(
+		ctx context.Context,
+		blockCount uint64,
+		lastBlock *big.Int,
+		rewardPercentiles []float64,
+	) (*FeeHistory, error)
 }
 
+// FeeHistory is the result of eth_feeHistory. BaseFee has one more entry than the number of blocks, the base fee of
+// the block following the last one.
+type FeeHistory struct {
+	OldestBlock  *big.Int
+	Reward       [][]*big.Int
+	BaseFee      []*big.Int
+	GasUsedRatio []float64
+}
+
+// Block numbers standing for the post-merge "finalized" and "safe" block tags in HeaderByNumber. These match the values
+// used by newer versions of go-ethereum.
+var (
+	FinalizedBlockNumber = big.NewInt(-3)
+	SafeBlockNumber      = big.NewInt(-4)
+)
+
+// PendingBlockNumber stands for the "pending" block tag, to run calls against the pending state.
+var PendingBlockNumber = big.NewInt(-1)
+
 type EVMProviderWithRet interface {
 	EVMProvider
 
@@ -49,6 +80,75 @@ func NewEVMProvider(rc *rpc.Client) EVMProviderWithRet {
 	}
 }
 
+// HeaderByNumber returns a block header from the current canonical chain. On top of what the go-ethereum client
+// supports, FinalizedBlockNumber and SafeBlockNumber return the latest finalized and safe headers.
+func (p *evmProviderWithRet) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var tag string
+	switch {
+	case number != nil && number.Cmp(FinalizedBlockNumber) == 0:
+		tag = "finalized"
+	case number != nil && number.Cmp(SafeBlockNumber) == 0:
+		tag = "safe"
+	default:
+		return p.Client.HeaderByNumber(ctx, number)
+	}
+
+	var header *types.Header
+	err := p.rc.CallContext(ctx, &header, "eth_getBlockByNumber", tag, false)
+	if err == nil && header == nil {
+		err = ethereum.NotFound
+	}
+
+	return header, err
+}
+
+type feeHistoryResult struct {
+	OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+	Reward       [][]*hexutil.Big `json:"reward,omitempty"`
+	BaseFee      []*hexutil.Big   `json:"baseFeePerGas,omitempty"`
+	GasUsedRatio []float64        `json:"gasUsedRatio"`
+}
+
+func (p *evmProviderWithRet) FeeHistory(
+	ctx context.Context,
+	blockCount uint64,
+	lastBlock *big.Int,
+	rewardPercentiles []float64,
+) (*FeeHistory, error) {
+	lastBlockArg := "latest"
+	if lastBlock != nil {
+		lastBlockArg = hexutil.EncodeBig(lastBlock)
+	}
+
+	var res feeHistoryResult
+	err := p.rc.CallContext(ctx, &res, "eth_feeHistory", hexutil.Uint(blockCount), lastBlockArg, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &FeeHistory{
+		OldestBlock:  (*big.Int)(res.OldestBlock),
+		Reward:       make([][]*big.Int, 0, len(res.Reward)),
+		BaseFee:      make([]*big.Int, 0, len(res.BaseFee)),
+		GasUsedRatio: res.GasUsedRatio,
+	}
+
+	for _, blockRewards := range res.Reward {
+		rewards := make([]*big.Int, 0, len(blockRewards))
+		for _, reward := range blockRewards {
+			rewards = append(rewards, (*big.Int)(reward))
+		}
+
+		history.Reward = append(history.Reward, rewards)
+	}
+
+	for _, baseFee := range res.BaseFee {
+		history.BaseFee = append(history.BaseFee, (*big.Int)(baseFee))
+	}
+
+	return history, nil
+}
+
 func (p *evmProviderWithRet) SendTransactionWithRet(
 	ctx context.Context,
 	tx *types.Transaction,
@@ -0,0 +1,273 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// QuorumMember is one of the providers taking part in quorum reads.
+type QuorumMember struct {
+	Name     string
+	Provider EVMProvider
+}
+
+type quorumProvider struct {
+	EVMProviderWithRet
+
+	logger  zerolog.Logger
+	members []QuorumMember
+	quorum  int
+}
+
+// NewQuorumProvider returns a provider that sends every request to primary, except log queries. Logs are fetched
+// from all the members and the query fails unless every log is returned by at least quorum of them, so a single lying
+// or buggy provider can't make us attest to events that didn't happen.
+func NewQuorumProvider(
+	logger zerolog.Logger,
+	primary EVMProviderWithRet,
+	members []QuorumMember,
+	quorum int,
+) (EVMProviderWithRet, error) {
+	if quorum < 1 || quorum > len(members) {
+		return nil, errors.Errorf("quorum must be between 1 and %d, got %d", len(members), quorum)
+	}
+
+	return &quorumProvider{
+		EVMProviderWithRet: primary,
+		logger:             logger.With().Str("module", "quorum_provider").Logger(),
+		members:            members,
+		quorum:             quorum,
+	}, nil
+}
+
+type logTally struct {
+	log       types.Log
+	providers []string
+}
+
+func (p *quorumProvider) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]types.Log, error) {
+	var (
+		wg      sync.WaitGroup
+		results = make([][]types.Log, len(p.members))
+		errs    = make([]error, len(p.members))
+	)
+
+	for i, m := range p.members {
+		wg.Add(1)
+		go func(i int, m QuorumMember) {
+			defer wg.Done()
+			results[i], errs[i] = m.Provider.FilterLogs(ctx, q)
+		}(i, m)
+	}
+
+	wg.Wait()
+
+	var (
+		responded int
+		firstErr  error
+		tallies   = map[string]*logTally{}
+		keys      []string
+	)
+
+	for i, logs := range results {
+		if errs[i] != nil {
+			p.logger.Warn().
+				Err(errs[i]).
+				Str("provider", p.members[i].Name).
+				Interface("from_block", q.FromBlock).
+				Interface("to_block", q.ToBlock).
+				Msg("provider failed to return logs")
+
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+
+		responded++
+
+		seen := map[string]bool{}
+		for _, log := range logs {
+			key, err := logKey(log)
+			if err != nil {
+				return nil, err
+			}
+
+			// a provider repeating a log doesn't count twice
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			tally, ok := tallies[key]
+			if !ok {
+				tally = &logTally{log: log}
+				tallies[key] = tally
+				keys = append(keys, key)
+			}
+
+			tally.providers = append(tally.providers, p.members[i].Name)
+		}
+	}
+
+	if responded < p.quorum {
+		return nil, errors.Wrapf(
+			firstErr,
+			"only %d of %d providers returned logs, %d needed",
+			responded,
+			len(p.members),
+			p.quorum,
+		)
+	}
+
+	agreedLogs := make([]types.Log, 0, len(keys))
+	for _, key := range keys {
+		tally := tallies[key]
+
+		if len(tally.providers) < p.quorum {
+			p.logger.Error().
+				Strs("agreeing_providers", tally.providers).
+				Int("quorum", p.quorum).
+				Int("responded", responded).
+				Uint64("block_number", tally.log.BlockNumber).
+				Str("block_hash", tally.log.BlockHash.Hex()).
+				Str("tx_hash", tally.log.TxHash.Hex()).
+				Uint("log_index", tally.log.Index).
+				Str("log", key).
+				Msg("providers disagree on a log")
+
+			// Some provider may just be lagging and miss a real event, so the range must be queried again rather
+			// than skipped.
+			return nil, errors.Errorf(
+				"only %d of %d providers returned the log %d of block %d, %d needed",
+				len(tally.providers),
+				len(p.members),
+				tally.log.Index,
+				tally.log.BlockNumber,
+				p.quorum,
+			)
+		}
+
+		agreedLogs = append(agreedLogs, tally.log)
+	}
+
+	sort.SliceStable(agreedLogs, func(i, j int) bool {
+		if agreedLogs[i].BlockNumber != agreedLogs[j].BlockNumber {
+			return agreedLogs[i].BlockNumber < agreedLogs[j].BlockNumber
+		}
+
+		return agreedLogs[i].Index < agreedLogs[j].Index
+	})
+
+	return agreedLogs, nil
+}
+
+// logKey identifies a log by its whole content, so two providers only agree on a log if they return exactly the same
+// one.
+func logKey(log types.Log) (string, error) {
+	b, err := json.Marshal(log)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to marshal log")
+	}
+
+	return string(b), nil
+}
+
+// QuorumHeaderReader reads block headers from several providers and only returns a header once at least quorum of
+// them agree on its hash. It is meant to be used as a header source independent from the primary provider.
+type QuorumHeaderReader struct {
+	logger  zerolog.Logger
+	members []QuorumMember
+	quorum  int
+}
+
+func NewQuorumHeaderReader(logger zerolog.Logger, members []QuorumMember, quorum int) (*QuorumHeaderReader, error) {
+	if quorum < 1 || quorum > len(members) {
+		return nil, errors.Errorf("quorum must be between 1 and %d, got %d", len(members), quorum)
+	}
+
+	return &QuorumHeaderReader{
+		logger:  logger.With().Str("module", "quorum_header_reader").Logger(),
+		members: members,
+		quorum:  quorum,
+	}, nil
+}
+
+func (r *QuorumHeaderReader) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	var (
+		wg      sync.WaitGroup
+		headers = make([]*types.Header, len(r.members))
+		errs    = make([]error, len(r.members))
+	)
+
+	for i, m := range r.members {
+		wg.Add(1)
+		go func(i int, m QuorumMember) {
+			defer wg.Done()
+			headers[i], errs[i] = m.Provider.HeaderByNumber(ctx, number)
+		}(i, m)
+	}
+
+	wg.Wait()
+
+	var (
+		responded int
+		firstErr  error
+		votes     = map[ethcmn.Hash]int{}
+	)
+
+	for i, header := range headers {
+		if errs[i] == nil && header == nil {
+			errs[i] = ethereum.NotFound
+		}
+
+		if errs[i] != nil {
+			r.logger.Warn().
+				Err(errs[i]).
+				Str("provider", r.members[i].Name).
+				Interface("block_number", number).
+				Msg("provider failed to return the header")
+
+			if firstErr == nil {
+				firstErr = errs[i]
+			}
+			continue
+		}
+
+		responded++
+
+		hash := header.Hash()
+		votes[hash]++
+		if votes[hash] >= r.quorum {
+			return header, nil
+		}
+	}
+
+	if responded < r.quorum {
+		return nil, errors.Wrapf(
+			firstErr,
+			"only %d of %d providers returned the header, %d needed",
+			responded,
+			len(r.members),
+			r.quorum,
+		)
+	}
+
+	r.logger.Error().
+		Interface("block_number", number).
+		Int("quorum", r.quorum).
+		Int("responded", responded).
+		Int("distinct_headers", len(votes)).
+		Msg("providers disagree on a header")
+
+	return nil, errors.Errorf("providers disagree on the header of block %v", number)
+}
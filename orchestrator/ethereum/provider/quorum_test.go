@@ -0,0 +1,185 @@
+package provider_test
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+)
+
+func TestQuorumProviderFilterLogs(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	query := ethereum.FilterQuery{
+		FromBlock: big.NewInt(1),
+		ToBlock:   big.NewInt(10),
+	}
+
+	newLog := func(blockNumber uint64, data string) ethtypes.Log {
+		return ethtypes.Log{
+			Address:     ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d"),
+			Topics:      []ethcmn.Hash{ethcmn.HexToHash("0x01")},
+			Data:        []byte(data),
+			BlockNumber: blockNumber,
+			TxHash:      ethcmn.HexToHash("0x02"),
+			BlockHash:   ethcmn.HexToHash("0x03"),
+		}
+	}
+
+	newQuorumProvider := func(mockCtrl *gomock.Controller, quorum int) (
+		provider.EVMProviderWithRet,
+		[]*mocks.MockEVMProviderWithRet,
+	) {
+		ethProviders := []*mocks.MockEVMProviderWithRet{
+			mocks.NewMockEVMProviderWithRet(mockCtrl),
+			mocks.NewMockEVMProviderWithRet(mockCtrl),
+			mocks.NewMockEVMProviderWithRet(mockCtrl),
+		}
+
+		quorumProvider, err := provider.NewQuorumProvider(logger, ethProviders[0], []provider.QuorumMember{
+			{Name: "a", Provider: ethProviders[0]},
+			{Name: "b", Provider: ethProviders[1]},
+			{Name: "c", Provider: ethProviders[2]},
+		}, quorum)
+		assert.Nil(t, err)
+
+		return quorumProvider, ethProviders
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		quorumProvider, ethProviders := newQuorumProvider(mockCtrl, 2)
+
+		ethProviders[0].EXPECT().FilterLogs(gomock.Any(), query).
+			Return([]ethtypes.Log{newLog(5, "deposit"), newLog(3, "valset")}, nil)
+		ethProviders[1].EXPECT().FilterLogs(gomock.Any(), query).
+			Return([]ethtypes.Log{newLog(3, "valset")}, errors.New("some error"))
+		ethProviders[2].EXPECT().FilterLogs(gomock.Any(), query).
+			Return([]ethtypes.Log{newLog(3, "valset"), newLog(5, "deposit")}, nil)
+
+		logs, err := quorumProvider.FilterLogs(context.Background(), query)
+		assert.Nil(t, err)
+		assert.Equal(t, []ethtypes.Log{newLog(3, "valset"), newLog(5, "deposit")}, logs)
+	})
+
+	t.Run("no quorum on a log", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		quorumProvider, ethProviders := newQuorumProvider(mockCtrl, 2)
+
+		ethProviders[0].EXPECT().FilterLogs(gomock.Any(), query).Return([]ethtypes.Log{newLog(5, "fake deposit")}, nil)
+		ethProviders[1].EXPECT().FilterLogs(gomock.Any(), query).Return([]ethtypes.Log{}, nil)
+		ethProviders[2].EXPECT().FilterLogs(gomock.Any(), query).Return(nil, errors.New("some error"))
+
+		_, err := quorumProvider.FilterLogs(context.Background(), query)
+		assert.EqualError(t, err, "only 1 of 3 providers returned the log 0 of block 5, 2 needed")
+	})
+
+	t.Run("not enough providers answered", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		quorumProvider, ethProviders := newQuorumProvider(mockCtrl, 2)
+
+		ethProviders[0].EXPECT().FilterLogs(gomock.Any(), query).Return([]ethtypes.Log{newLog(5, "deposit")}, nil)
+		ethProviders[1].EXPECT().FilterLogs(gomock.Any(), query).Return(nil, errors.New("some error"))
+		ethProviders[2].EXPECT().FilterLogs(gomock.Any(), query).Return(nil, errors.New("some error"))
+
+		_, err := quorumProvider.FilterLogs(context.Background(), query)
+		assert.EqualError(t, err, "only 1 of 3 providers returned logs, 2 needed: some error")
+	})
+
+	t.Run("invalid quorum", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+		_, err := provider.NewQuorumProvider(logger, ethProvider, []provider.QuorumMember{
+			{Name: "a", Provider: ethProvider},
+		}, 2)
+		assert.EqualError(t, err, "quorum must be between 1 and 1, got 2")
+	})
+}
+
+func TestQuorumHeaderReader(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	newReader := func(mockCtrl *gomock.Controller, quorum int) (
+		*provider.QuorumHeaderReader,
+		[]*mocks.MockEVMProviderWithRet,
+	) {
+		ethProviders := []*mocks.MockEVMProviderWithRet{
+			mocks.NewMockEVMProviderWithRet(mockCtrl),
+			mocks.NewMockEVMProviderWithRet(mockCtrl),
+			mocks.NewMockEVMProviderWithRet(mockCtrl),
+		}
+
+		reader, err := provider.NewQuorumHeaderReader(logger, []provider.QuorumMember{
+			{Name: "a", Provider: ethProviders[0]},
+			{Name: "b", Provider: ethProviders[1]},
+			{Name: "c", Provider: ethProviders[2]},
+		}, quorum)
+		assert.Nil(t, err)
+
+		return reader, ethProviders
+	}
+
+	header := &ethtypes.Header{Number: big.NewInt(10), ReceiptHash: ethcmn.HexToHash("0x01")}
+	forgedHeader := &ethtypes.Header{Number: big.NewInt(10), ReceiptHash: ethcmn.HexToHash("0x02")}
+
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		reader, ethProviders := newReader(mockCtrl, 2)
+		ethProviders[0].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(header, nil)
+		ethProviders[1].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(forgedHeader, nil)
+		ethProviders[2].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(header, nil)
+
+		res, err := reader.HeaderByNumber(context.Background(), big.NewInt(10))
+		assert.Nil(t, err)
+		assert.Equal(t, header.Hash(), res.Hash())
+	})
+
+	t.Run("disagreement", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		reader, ethProviders := newReader(mockCtrl, 2)
+		ethProviders[0].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(header, nil)
+		ethProviders[1].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(forgedHeader, nil)
+		ethProviders[2].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(nil, errors.New("some error"))
+
+		res, err := reader.HeaderByNumber(context.Background(), big.NewInt(10))
+		assert.Nil(t, res)
+		assert.EqualError(t, err, "providers disagree on the header of block 10")
+	})
+
+	t.Run("not enough providers", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		reader, ethProviders := newReader(mockCtrl, 2)
+		ethProviders[0].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(header, nil)
+		ethProviders[1].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(nil, errors.New("some error"))
+		ethProviders[2].EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(nil, errors.New("some error"))
+
+		res, err := reader.HeaderByNumber(context.Background(), big.NewInt(10))
+		assert.Nil(t, res)
+		assert.EqualError(t, err, "only 1 of 3 providers returned the header, 2 needed: some error")
+	})
+}
@@ -0,0 +1,196 @@
+package receiptproof
+
+import (
+	"bytes"
+	"context"
+	"math/big"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// HeaderReader is the source of the block headers the receipts are checked against. It must be independent from the
+// provider the logs come from (e.g. another node or a quorum of them), otherwise a lying provider can forge the
+// header as easily as the log.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+}
+
+// BlockReceiptsReader returns the transactions of a block and their receipts.
+type BlockReceiptsReader interface {
+	BlockByHash(ctx context.Context, hash ethcmn.Hash) (*types.Block, error)
+	TransactionReceipt(ctx context.Context, txHash ethcmn.Hash) (*types.Receipt, error)
+}
+
+// Verifier proves that logs are part of the canonical chain: it fetches all the receipts of the block of a log,
+// rebuilds the receipts trie and checks that its root matches the ReceiptHash of the header returned by an
+// independent header source. Only consensus fields are trusted, so a provider can't forge a log without also forging
+// the header. The receipts are fetched one transaction at a time, so proving a log costs as many RPC calls as there
+// are transactions in its block.
+type Verifier struct {
+	logger   zerolog.Logger
+	receipts BlockReceiptsReader
+	headers  HeaderReader
+}
+
+func NewVerifier(logger zerolog.Logger, receipts BlockReceiptsReader, headers HeaderReader) *Verifier {
+	return &Verifier{
+		logger:   logger.With().Str("module", "receipt_proof").Logger(),
+		receipts: receipts,
+		headers:  headers,
+	}
+}
+
+// VerifyLogs checks the receipt proof of every log, fetching the receipts of each block only once.
+func (v *Verifier) VerifyLogs(ctx context.Context, logs []types.Log) error {
+	var (
+		blockHashes []ethcmn.Hash
+		byBlock     = map[ethcmn.Hash][]types.Log{}
+	)
+
+	for _, log := range logs {
+		if _, ok := byBlock[log.BlockHash]; !ok {
+			blockHashes = append(blockHashes, log.BlockHash)
+		}
+		byBlock[log.BlockHash] = append(byBlock[log.BlockHash], log)
+	}
+
+	for _, blockHash := range blockHashes {
+		if err := v.verifyBlockLogs(ctx, blockHash, byBlock[blockHash]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (v *Verifier) verifyBlockLogs(ctx context.Context, blockHash ethcmn.Hash, logs []types.Log) error {
+	blockNumber := logs[0].BlockNumber
+
+	header, err := v.headers.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
+	if err != nil {
+		return errors.Wrapf(err, "failed to get the header of block %d", blockNumber)
+	}
+
+	if header.Hash() != blockHash {
+		return errors.Errorf(
+			"block %d is %s according to the header source, but the logs are from %s",
+			blockNumber,
+			header.Hash().Hex(),
+			blockHash.Hex(),
+		)
+	}
+
+	block, err := v.receipts.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return errors.Wrapf(err, "failed to get block %s", blockHash.Hex())
+	}
+
+	txs := block.Transactions()
+	if root := types.DeriveSha(txs, trie.NewStackTrie(nil)); root != header.TxHash {
+		return errors.Errorf(
+			"transactions root of block %d doesn't match the header: got %s, expected %s",
+			blockNumber,
+			root.Hex(),
+			header.TxHash.Hex(),
+		)
+	}
+
+	receipts := make(types.Receipts, len(txs))
+	for i, tx := range txs {
+		receipt, err := v.receipts.TransactionReceipt(ctx, tx.Hash())
+		if err != nil {
+			return errors.Wrapf(err, "failed to get the receipt of tx %s", tx.Hash().Hex())
+		}
+		receipts[i] = receipt
+	}
+
+	if root := types.DeriveSha(receipts, trie.NewStackTrie(nil)); root != header.ReceiptHash {
+		return errors.Errorf(
+			"receipts root of block %d doesn't match the header: got %s, expected %s",
+			blockNumber,
+			root.Hex(),
+			header.ReceiptHash.Hex(),
+		)
+	}
+
+	// The log indexes and tx hashes returned alongside the receipts are not covered by the root, so the proven
+	// logs are looked up by their position in the block instead.
+	var (
+		blockLogs []*types.Log
+		logTxs    []int
+	)
+
+	for i, receipt := range receipts {
+		for _, log := range receipt.Logs {
+			blockLogs = append(blockLogs, log)
+			logTxs = append(logTxs, i)
+		}
+	}
+
+	for _, log := range logs {
+		if err := verifyLog(log, txs, blockLogs, logTxs); err != nil {
+			return err
+		}
+	}
+
+	v.logger.Debug().
+		Uint64("block_number", blockNumber).
+		Str("block_hash", blockHash.Hex()).
+		Int("num_logs", len(logs)).
+		Msg("verified the receipt proofs of the logs")
+
+	return nil
+}
+
+func verifyLog(log types.Log, txs types.Transactions, blockLogs []*types.Log, logTxs []int) error {
+	if int(log.Index) >= len(blockLogs) {
+		return errors.Errorf(
+			"log %d of tx %s is not in the receipts of block %d",
+			log.Index,
+			log.TxHash.Hex(),
+			log.BlockNumber,
+		)
+	}
+
+	proven := blockLogs[log.Index]
+	txIndex := logTxs[log.Index]
+
+	if uint(txIndex) != log.TxIndex || txs[txIndex].Hash() != log.TxHash {
+		return errors.Errorf(
+			"log %d of block %d belongs to tx %s, not to tx %s",
+			log.Index,
+			log.BlockNumber,
+			txs[txIndex].Hash().Hex(),
+			log.TxHash.Hex(),
+		)
+	}
+
+	if !sameLog(proven, &log) {
+		return errors.Errorf(
+			"log %d of tx %s doesn't match the receipts of block %d",
+			log.Index,
+			log.TxHash.Hex(),
+			log.BlockNumber,
+		)
+	}
+
+	return nil
+}
+
+func sameLog(a, b *types.Log) bool {
+	if a.Address != b.Address || !bytes.Equal(a.Data, b.Data) || len(a.Topics) != len(b.Topics) {
+		return false
+	}
+
+	for i := range a.Topics {
+		if a.Topics[i] != b.Topics[i] {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,145 @@
+package receiptproof_test
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/trie"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/ethereum/receiptproof"
+)
+
+func TestVerifyLogs(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+
+	txs := ethtypes.Transactions{
+		ethtypes.NewTransaction(0, gravityAddress, big.NewInt(0), 21000, big.NewInt(1), []byte{0x01}),
+		ethtypes.NewTransaction(1, gravityAddress, big.NewInt(0), 21000, big.NewInt(1), []byte{0x02}),
+	}
+
+	newReceipts := func() ethtypes.Receipts {
+		return ethtypes.Receipts{
+			{
+				Status:            ethtypes.ReceiptStatusSuccessful,
+				CumulativeGasUsed: 21000,
+				TxHash:            txs[0].Hash(),
+				Logs: []*ethtypes.Log{
+					{Address: gravityAddress, Topics: []ethcmn.Hash{ethcmn.HexToHash("0x01")}, Data: []byte("first")},
+				},
+			},
+			{
+				Status:            ethtypes.ReceiptStatusSuccessful,
+				CumulativeGasUsed: 42000,
+				TxHash:            txs[1].Hash(),
+				Logs: []*ethtypes.Log{
+					{Address: gravityAddress, Topics: []ethcmn.Hash{ethcmn.HexToHash("0x02")}, Data: []byte("second")},
+				},
+			},
+		}
+	}
+
+	block := ethtypes.NewBlock(
+		&ethtypes.Header{Number: big.NewInt(10)},
+		txs,
+		nil,
+		newReceipts(),
+		trie.NewStackTrie(nil),
+	)
+
+	provenLog := ethtypes.Log{
+		Address:     gravityAddress,
+		Topics:      []ethcmn.Hash{ethcmn.HexToHash("0x02")},
+		Data:        []byte("second"),
+		BlockNumber: 10,
+		TxHash:      txs[1].Hash(),
+		TxIndex:     1,
+		BlockHash:   block.Hash(),
+		Index:       1,
+	}
+
+	expectReceipts := func(ethProvider *mocks.MockEVMProviderWithRet, receipts ethtypes.Receipts) {
+		ethProvider.EXPECT().BlockByHash(gomock.Any(), block.Hash()).Return(block, nil)
+		for i, tx := range txs {
+			ethProvider.EXPECT().TransactionReceipt(gomock.Any(), tx.Hash()).Return(receipts[i], nil)
+		}
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		headerSource := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+		headerSource.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(block.Header(), nil)
+		expectReceipts(ethProvider, newReceipts())
+
+		verifier := receiptproof.NewVerifier(logger, ethProvider, headerSource)
+		assert.Nil(t, verifier.VerifyLogs(context.Background(), []ethtypes.Log{provenLog}))
+	})
+
+	t.Run("forged log", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		headerSource := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+		headerSource.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(block.Header(), nil)
+		expectReceipts(ethProvider, newReceipts())
+
+		forgedLog := provenLog
+		forgedLog.Data = []byte("forged")
+
+		verifier := receiptproof.NewVerifier(logger, ethProvider, headerSource)
+		err := verifier.VerifyLogs(context.Background(), []ethtypes.Log{forgedLog})
+		assert.EqualError(t, err, "log 1 of tx "+txs[1].Hash().Hex()+" doesn't match the receipts of block 10")
+	})
+
+	t.Run("forged receipts", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		headerSource := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+		receipts := newReceipts()
+		receipts[1].Logs[0].Data = []byte("forged")
+
+		headerSource.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(block.Header(), nil)
+		expectReceipts(ethProvider, receipts)
+
+		forgedLog := provenLog
+		forgedLog.Data = []byte("forged")
+
+		verifier := receiptproof.NewVerifier(logger, ethProvider, headerSource)
+		err := verifier.VerifyLogs(context.Background(), []ethtypes.Log{forgedLog})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "receipts root of block 10 doesn't match the header")
+	})
+
+	t.Run("block not known to the header source", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		headerSource := mocks.NewMockEVMProviderWithRet(mockCtrl)
+
+		otherHeader := block.Header()
+		otherHeader.Extra = []byte("other")
+		headerSource.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(otherHeader, nil)
+
+		verifier := receiptproof.NewVerifier(logger, ethProvider, headerSource)
+		err := verifier.VerifyLogs(context.Background(), []ethtypes.Log{provenLog})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "according to the header source")
+	})
+}
@@ -0,0 +1,343 @@
+package eventindex
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"sync"
+
+	badger "github.com/dgraph-io/badger/v3"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// KVStore key prefixes
+var (
+	KeyPrefixEventByNonce = []byte{0x01}
+	KeyPrefixEventByBlock = []byte{0x02}
+	KeyFirstIndexedBlock  = []byte{0x03}
+	KeyLastIndexedBlock   = []byte{0x04}
+)
+
+// truncateBatchSize is the number of events deleted per transaction when truncating the index, so dropping many blocks
+// doesn't exceed the size of a badger transaction.
+const truncateBatchSize = 1000
+
+type badgerIndex struct {
+	logger zerolog.Logger
+	db     *badger.DB
+
+	// writeMtx keeps a truncation from interleaving with the events being stored.
+	writeMtx sync.Mutex
+}
+
+// NewBadgerIndex opens (or creates) a badger backed index in dbDir. If dbDir is empty the index is kept in memory.
+func NewBadgerIndex(logger zerolog.Logger, dbDir string) (Index, error) {
+	opts := badger.DefaultOptions(dbDir).WithLogger(nil)
+	if dbDir == "" {
+		opts = opts.WithInMemory(true)
+	}
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open event index db")
+	}
+
+	return &badgerIndex{
+		logger: logger.With().Str("module", "event_index").Logger(),
+		db:     db,
+	}, nil
+}
+
+func (idx *badgerIndex) IndexedRange() (firstBlock, lastBlock uint64, ok bool, err error) {
+	err = idx.db.View(func(txn *badger.Txn) error {
+		firstBlock, ok, err = getUint64(txn, KeyFirstIndexedBlock)
+		if err != nil || !ok {
+			return err
+		}
+
+		lastBlock, ok, err = getUint64(txn, KeyLastIndexedBlock)
+		return err
+	})
+
+	return firstBlock, lastBlock, ok, err
+}
+
+func (idx *badgerIndex) StoreEvents(events []Event, fromBlock, toBlock uint64) error {
+	idx.writeMtx.Lock()
+	defer idx.writeMtx.Unlock()
+
+	return idx.db.Update(func(txn *badger.Txn) error {
+		_, ok, err := getUint64(txn, KeyFirstIndexedBlock)
+		if err != nil {
+			return err
+		} else if !ok {
+			if err := txn.Set(KeyFirstIndexedBlock, uint64ToBytes(fromBlock)); err != nil {
+				return err
+			}
+		} else {
+			lastBlock, _, err := getUint64(txn, KeyLastIndexedBlock)
+			if err != nil {
+				return err
+			} else if fromBlock != lastBlock+1 {
+				return ErrIndexMoved
+			}
+		}
+
+		for _, ev := range events {
+			value, err := json.Marshal(ev.Log)
+			if err != nil {
+				return errors.Wrap(err, "failed to marshal log")
+			}
+
+			if err := txn.Set(eventByNonceKey(ev.Nonce), value); err != nil {
+				return err
+			}
+
+			if err := txn.Set(eventByBlockKey(ev.Log.BlockNumber, ev.Log.Index), uint64ToBytes(ev.Nonce)); err != nil {
+				return err
+			}
+		}
+
+		return txn.Set(KeyLastIndexedBlock, uint64ToBytes(toBlock))
+	})
+}
+
+func (idx *badgerIndex) EventByNonce(nonce uint64) (*ethtypes.Log, error) {
+	var log *ethtypes.Log
+
+	err := idx.db.View(func(txn *badger.Txn) (err error) {
+		log, err = getEventByNonce(txn, nonce)
+		return err
+	})
+
+	return log, err
+}
+
+func (idx *badgerIndex) EventsInRange(fromBlock, toBlock uint64) ([]ethtypes.Log, error) {
+	logs := []ethtypes.Log{}
+
+	err := idx.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = KeyPrefixEventByBlock
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Seek(eventByBlockKey(fromBlock, 0)); it.Valid(); it.Next() {
+			key := it.Item().Key()
+			if binary.BigEndian.Uint64(key[1:9]) > toBlock {
+				break
+			}
+
+			var nonce uint64
+			err := it.Item().Value(func(v []byte) error {
+				nonce = binary.BigEndian.Uint64(v)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			log, err := getEventByNonce(txn, nonce)
+			if err != nil {
+				return err
+			}
+
+			logs = append(logs, *log)
+		}
+
+		return nil
+	})
+
+	return logs, err
+}
+
+func (idx *badgerIndex) LatestEventByTopic(topic ethcmn.Hash) (*ethtypes.Log, error) {
+	var log *ethtypes.Log
+
+	err := idx.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = KeyPrefixEventByNonce
+		opts.Reverse = true
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// Seeking in reverse lands on the greatest key lower than or equal to the one given.
+		for it.Seek(eventByNonceKey(^uint64(0))); it.Valid(); it.Next() {
+			l, err := unmarshalLog(it.Item())
+			if err != nil {
+				return err
+			}
+
+			if len(l.Topics) > 0 && l.Topics[0] == topic {
+				log = l
+				return nil
+			}
+		}
+
+		return ErrNotFound
+	})
+
+	return log, err
+}
+
+func (idx *badgerIndex) Truncate(fromBlock uint64) error {
+	idx.writeMtx.Lock()
+	defer idx.writeMtx.Unlock()
+
+	// The indexed range is moved back first, so the events are no longer covered while they are deleted.
+	var truncated bool
+	err := idx.db.Update(func(txn *badger.Txn) error {
+		firstBlock, ok, err := getUint64(txn, KeyFirstIndexedBlock)
+		if err != nil || !ok {
+			return err
+		}
+
+		lastBlock, _, err := getUint64(txn, KeyLastIndexedBlock)
+		if err != nil || fromBlock > lastBlock {
+			return err
+		}
+
+		truncated = true
+
+		// Nothing is left indexed, the next sync starts over from the start block.
+		if fromBlock <= firstBlock {
+			if err := txn.Delete(KeyFirstIndexedBlock); err != nil {
+				return err
+			}
+
+			return txn.Delete(KeyLastIndexedBlock)
+		}
+
+		return txn.Set(KeyLastIndexedBlock, uint64ToBytes(fromBlock-1))
+	})
+	if err != nil || !truncated {
+		return err
+	}
+
+	var numEvents int
+	for {
+		deleted, err := idx.deleteEventsFrom(fromBlock, truncateBatchSize)
+		if err != nil {
+			return err
+		}
+
+		numEvents += deleted
+		if deleted < truncateBatchSize {
+			break
+		}
+	}
+
+	idx.logger.Info().
+		Uint64("from_block", fromBlock).
+		Int("num_events", numEvents).
+		Msg("dropped events from the index")
+
+	return nil
+}
+
+// deleteEventsFrom deletes up to limit events emitted from fromBlock onwards, in a single transaction, and returns
+// the number of events deleted.
+func (idx *badgerIndex) deleteEventsFrom(fromBlock uint64, limit int) (int, error) {
+	var deleted int
+
+	err := idx.db.Update(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = KeyPrefixEventByBlock
+
+		it := txn.NewIterator(opts)
+
+		var keys [][]byte
+		for it.Seek(eventByBlockKey(fromBlock, 0)); it.Valid() && len(keys) < 2*limit; it.Next() {
+			keys = append(keys, it.Item().KeyCopy(nil))
+
+			err := it.Item().Value(func(v []byte) error {
+				keys = append(keys, eventByNonceKey(binary.BigEndian.Uint64(v)))
+				return nil
+			})
+			if err != nil {
+				it.Close()
+				return err
+			}
+		}
+		it.Close()
+
+		for _, key := range keys {
+			if err := txn.Delete(key); err != nil {
+				return err
+			}
+		}
+
+		deleted = len(keys) / 2
+		return nil
+	})
+
+	return deleted, err
+}
+
+func (idx *badgerIndex) Reset() error {
+	idx.logger.Info().Msg("dropping every event from the index")
+	return idx.db.DropAll()
+}
+
+func (idx *badgerIndex) Close() error {
+	return idx.db.Close()
+}
+
+func getUint64(txn *badger.Txn, key []byte) (value uint64, ok bool, err error) {
+	item, err := txn.Get(key)
+	if err == badger.ErrKeyNotFound {
+		return 0, false, nil
+	} else if err != nil {
+		return 0, false, err
+	}
+
+	err = item.Value(func(v []byte) error {
+		value = binary.BigEndian.Uint64(v)
+		return nil
+	})
+
+	return value, err == nil, err
+}
+
+func getEventByNonce(txn *badger.Txn, nonce uint64) (*ethtypes.Log, error) {
+	item, err := txn.Get(eventByNonceKey(nonce))
+	if err == badger.ErrKeyNotFound {
+		return nil, ErrNotFound
+	} else if err != nil {
+		return nil, err
+	}
+
+	return unmarshalLog(item)
+}
+
+func unmarshalLog(item *badger.Item) (*ethtypes.Log, error) {
+	log := &ethtypes.Log{}
+
+	err := item.Value(func(v []byte) error {
+		return json.Unmarshal(v, log)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal log")
+	}
+
+	return log, nil
+}
+
+func eventByNonceKey(nonce uint64) []byte {
+	return append(append([]byte{}, KeyPrefixEventByNonce...), uint64ToBytes(nonce)...)
+}
+
+func eventByBlockKey(blockNumber uint64, logIndex uint) []byte {
+	key := append(append([]byte{}, KeyPrefixEventByBlock...), uint64ToBytes(blockNumber)...)
+	return append(key, uint64ToBytes(uint64(logIndex))...)
+}
+
+func uint64ToBytes(v uint64) []byte {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint64(b, v)
+	return b
+}
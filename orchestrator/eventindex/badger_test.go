@@ -0,0 +1,173 @@
+package eventindex
+
+import (
+	"os"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func testLog(topic ethcmn.Hash, blockNumber uint64, index uint) ethtypes.Log {
+	return ethtypes.Log{
+		Address:     ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d"),
+		Topics:      []ethcmn.Hash{topic},
+		Data:        []byte{},
+		BlockNumber: blockNumber,
+		TxHash:      ethcmn.HexToHash("0x01"),
+		BlockHash:   ethcmn.HexToHash("0x02"),
+		Index:       index,
+	}
+}
+
+func TestBadgerIndex(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	idx, err := NewBadgerIndex(logger, "")
+	assert.NoError(t, err)
+	defer idx.Close()
+
+	t.Run("empty", func(t *testing.T) {
+		_, _, ok, err := idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		_, err = idx.EventByNonce(1)
+		assert.Equal(t, ErrNotFound, err)
+
+		_, err = idx.LatestEventByTopic(TopicValsetUpdated)
+		assert.Equal(t, ErrNotFound, err)
+
+		assert.False(t, Covers(idx, 1, 1))
+		assert.False(t, Covers(nil, 1, 1))
+	})
+
+	t.Run("store and query", func(t *testing.T) {
+		assert.NoError(t, idx.StoreEvents([]Event{
+			{Nonce: 1, Log: testLog(TopicValsetUpdated, 12, 0)},
+			{Nonce: 2, Log: testLog(TopicSendToCosmos, 12, 3)},
+		}, 10, 20))
+		assert.NoError(t, idx.StoreEvents([]Event{
+			{Nonce: 3, Log: testLog(TopicValsetUpdated, 25, 1)},
+			{Nonce: 4, Log: testLog(TopicSendToCosmos, 30, 0)},
+		}, 21, 30))
+
+		firstBlock, lastBlock, ok, err := idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(10), firstBlock)
+		assert.Equal(t, uint64(30), lastBlock)
+
+		assert.True(t, Covers(idx, 10, 30))
+		assert.True(t, Covers(idx, 15, 25))
+		assert.False(t, Covers(idx, 5, 25))
+		assert.False(t, Covers(idx, 15, 31))
+
+		log, err := idx.EventByNonce(2)
+		assert.NoError(t, err)
+		assert.Equal(t, TopicSendToCosmos, log.Topics[0])
+		assert.Equal(t, uint64(12), log.BlockNumber)
+		assert.Equal(t, uint(3), log.Index)
+
+		logs, err := idx.EventsInRange(12, 25)
+		assert.NoError(t, err)
+		assert.Len(t, logs, 3)
+		assert.Equal(t, uint64(12), logs[0].BlockNumber)
+		assert.Equal(t, uint(0), logs[0].Index)
+		assert.Equal(t, uint(3), logs[1].Index)
+		assert.Equal(t, uint64(25), logs[2].BlockNumber)
+
+		logs, err = idx.EventsInRange(13, 24)
+		assert.NoError(t, err)
+		assert.Empty(t, logs)
+
+		log, err = idx.LatestEventByTopic(TopicValsetUpdated)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(25), log.BlockNumber)
+
+		_, err = idx.LatestEventByTopic(TopicLogicCall)
+		assert.Equal(t, ErrNotFound, err)
+
+		// a gap or an overlap with the indexed range
+		assert.Equal(t, ErrIndexMoved, idx.StoreEvents(nil, 32, 40))
+		assert.Equal(t, ErrIndexMoved, idx.StoreEvents(nil, 25, 40))
+
+		_, lastBlock, _, err = idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(30), lastBlock)
+	})
+
+	t.Run("truncate", func(t *testing.T) {
+		assert.NoError(t, idx.Truncate(25))
+
+		firstBlock, lastBlock, ok, err := idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(10), firstBlock)
+		assert.Equal(t, uint64(24), lastBlock)
+
+		logs, err := idx.EventsInRange(10, 30)
+		assert.NoError(t, err)
+		assert.Len(t, logs, 2)
+
+		_, err = idx.EventByNonce(3)
+		assert.Equal(t, ErrNotFound, err)
+
+		log, err := idx.LatestEventByTopic(TopicValsetUpdated)
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(12), log.BlockNumber)
+
+		// beyond the indexed range
+		assert.NoError(t, idx.Truncate(40))
+		_, lastBlock, _, err = idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(24), lastBlock)
+
+		assert.NoError(t, idx.Truncate(5))
+		_, _, ok, err = idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		_, err = idx.EventByNonce(1)
+		assert.Equal(t, ErrNotFound, err)
+
+		// indexed again
+		assert.NoError(t, idx.StoreEvents([]Event{
+			{Nonce: 1, Log: testLog(TopicValsetUpdated, 12, 0)},
+			{Nonce: 2, Log: testLog(TopicSendToCosmos, 12, 3)},
+		}, 10, 20))
+	})
+
+	t.Run("truncate many events", func(t *testing.T) {
+		assert.NoError(t, idx.Truncate(0))
+
+		events := make([]Event, 0, 2*truncateBatchSize+1)
+		for i := 0; i < cap(events); i++ {
+			events = append(events, Event{Nonce: uint64(i + 1), Log: testLog(TopicSendToCosmos, 100+uint64(i), 0)})
+		}
+		assert.NoError(t, idx.StoreEvents(events, 100, 100+uint64(len(events))))
+
+		assert.NoError(t, idx.Truncate(101))
+
+		logs, err := idx.EventsInRange(0, 10000)
+		assert.NoError(t, err)
+		assert.Len(t, logs, 1)
+
+		_, lastBlock, _, err := idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(100), lastBlock)
+	})
+
+	t.Run("reset", func(t *testing.T) {
+		assert.NoError(t, idx.Reset())
+
+		_, _, ok, err := idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		_, err = idx.EventByNonce(2)
+		assert.Equal(t, ErrNotFound, err)
+	})
+}
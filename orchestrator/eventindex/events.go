@@ -0,0 +1,176 @@
+package eventindex
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+var (
+	gravityABI, _ = abi.JSON(strings.NewReader(wrappers.GravityABI))
+
+	// Topics of the Gravity contract events we care about.
+	TopicERC20Deployed            = gravityABI.Events["ERC20DeployedEvent"].ID
+	TopicSendToCosmos             = gravityABI.Events["SendToCosmosEvent"].ID
+	TopicTransactionBatchExecuted = gravityABI.Events["TransactionBatchExecutedEvent"].ID
+	TopicValsetUpdated            = gravityABI.Events["ValsetUpdatedEvent"].ID
+	TopicLogicCall                = gravityABI.Events["LogicCallEvent"].ID
+)
+
+// Events groups the decoded Gravity contract events found in a range of blocks.
+type Events struct {
+	ERC20Deployed            []*wrappers.GravityERC20DeployedEvent
+	SendToCosmos             []*wrappers.GravitySendToCosmosEvent
+	TransactionBatchExecuted []*wrappers.GravityTransactionBatchExecutedEvent
+	ValsetUpdated            []*wrappers.GravityValsetUpdatedEvent
+	LogicCall                []*wrappers.GravityLogicCallEvent
+}
+
+// Logs returns the raw logs of all the events.
+func (e Events) Logs() []ethtypes.Log {
+	logs := []ethtypes.Log{}
+
+	for _, ev := range e.ERC20Deployed {
+		logs = append(logs, ev.Raw)
+	}
+	for _, ev := range e.SendToCosmos {
+		logs = append(logs, ev.Raw)
+	}
+	for _, ev := range e.TransactionBatchExecuted {
+		logs = append(logs, ev.Raw)
+	}
+	for _, ev := range e.ValsetUpdated {
+		logs = append(logs, ev.Raw)
+	}
+	for _, ev := range e.LogicCall {
+		logs = append(logs, ev.Raw)
+	}
+
+	return logs
+}
+
+// BlocksByNonce returns the block number of every event, keyed by event nonce.
+func (e Events) BlocksByNonce() map[uint64]uint64 {
+	blocks := map[uint64]uint64{}
+
+	for _, ev := range e.ERC20Deployed {
+		blocks[ev.EventNonce.Uint64()] = ev.Raw.BlockNumber
+	}
+	for _, ev := range e.SendToCosmos {
+		blocks[ev.EventNonce.Uint64()] = ev.Raw.BlockNumber
+	}
+	for _, ev := range e.TransactionBatchExecuted {
+		blocks[ev.EventNonce.Uint64()] = ev.Raw.BlockNumber
+	}
+	for _, ev := range e.ValsetUpdated {
+		blocks[ev.EventNonce.Uint64()] = ev.Raw.BlockNumber
+	}
+	for _, ev := range e.LogicCall {
+		blocks[ev.EventNonce.Uint64()] = ev.Raw.BlockNumber
+	}
+
+	return blocks
+}
+
+// DecodeEvents decodes raw Gravity contract logs into their typed events. Logs that don't belong to any of the
+// events we care about are ignored.
+func DecodeEvents(gravityFilterer *wrappers.GravityFilterer, logs []ethtypes.Log) (Events, error) {
+	var events Events
+
+	for _, log := range logs {
+		if len(log.Topics) == 0 {
+			continue
+		}
+
+		switch log.Topics[0] {
+		case TopicERC20Deployed:
+			ev, err := gravityFilterer.ParseERC20DeployedEvent(log)
+			if err != nil {
+				return Events{}, errors.Wrap(err, "failed to parse ERC20Deployed event")
+			}
+			events.ERC20Deployed = append(events.ERC20Deployed, ev)
+
+		case TopicSendToCosmos:
+			ev, err := gravityFilterer.ParseSendToCosmosEvent(log)
+			if err != nil {
+				return Events{}, errors.Wrap(err, "failed to parse SendToCosmos event")
+			}
+			events.SendToCosmos = append(events.SendToCosmos, ev)
+
+		case TopicTransactionBatchExecuted:
+			ev, err := gravityFilterer.ParseTransactionBatchExecutedEvent(log)
+			if err != nil {
+				return Events{}, errors.Wrap(err, "failed to parse TransactionBatchExecuted event")
+			}
+			events.TransactionBatchExecuted = append(events.TransactionBatchExecuted, ev)
+
+		case TopicValsetUpdated:
+			ev, err := gravityFilterer.ParseValsetUpdatedEvent(log)
+			if err != nil {
+				return Events{}, errors.Wrap(err, "failed to parse ValsetUpdated event")
+			}
+			events.ValsetUpdated = append(events.ValsetUpdated, ev)
+
+		case TopicLogicCall:
+			ev, err := gravityFilterer.ParseLogicCallEvent(log)
+			if err != nil {
+				return Events{}, errors.Wrap(err, "failed to parse LogicCall event")
+			}
+			events.LogicCall = append(events.LogicCall, ev)
+		}
+	}
+
+	return events, nil
+}
+
+// EventNonce returns the event nonce of a raw Gravity contract log. The second return value is false if the log
+// doesn't belong to any of the events we care about.
+func EventNonce(gravityFilterer *wrappers.GravityFilterer, log ethtypes.Log) (uint64, bool, error) {
+	events, err := DecodeEvents(gravityFilterer, []ethtypes.Log{log})
+	if err != nil {
+		return 0, false, err
+	}
+
+	switch {
+	case len(events.ERC20Deployed) > 0:
+		return events.ERC20Deployed[0].EventNonce.Uint64(), true, nil
+	case len(events.SendToCosmos) > 0:
+		return events.SendToCosmos[0].EventNonce.Uint64(), true, nil
+	case len(events.TransactionBatchExecuted) > 0:
+		return events.TransactionBatchExecuted[0].EventNonce.Uint64(), true, nil
+	case len(events.ValsetUpdated) > 0:
+		return events.ValsetUpdated[0].EventNonce.Uint64(), true, nil
+	case len(events.LogicCall) > 0:
+		return events.LogicCall[0].EventNonce.Uint64(), true, nil
+	}
+
+	return 0, false, nil
+}
+
+// FilterQuery returns the query for all the Gravity contract events we care about emitted between fromBlock and
+// toBlock (both inclusive), so they can be fetched with a single eth_getLogs call.
+func FilterQuery(gravityAddress ethcmn.Address, fromBlock, toBlock uint64) ethereum.FilterQuery {
+	return ethereum.FilterQuery{
+		FromBlock: new(big.Int).SetUint64(fromBlock),
+		ToBlock:   new(big.Int).SetUint64(toBlock),
+		Addresses: []ethcmn.Address{gravityAddress},
+		Topics:    [][]ethcmn.Hash{Topics()},
+	}
+}
+
+// Topics returns the topics of all the Gravity contract events we index.
+func Topics() []ethcmn.Hash {
+	return []ethcmn.Hash{
+		TopicERC20Deployed,
+		TopicSendToCosmos,
+		TopicTransactionBatchExecuted,
+		TopicValsetUpdated,
+		TopicLogicCall,
+	}
+}
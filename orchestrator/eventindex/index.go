@@ -0,0 +1,65 @@
+package eventindex
+
+import (
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// ErrNotFound is returned when the requested event is not in the index (yet).
+var ErrNotFound = errors.New("event not found in index")
+
+// ErrIndexMoved is returned when storing events that don't start right after the last indexed block, as happens when
+// the index was truncated while they were fetched.
+var ErrIndexMoved = errors.New("events don't follow the last indexed block")
+
+// Event is a raw Gravity contract log together with its event nonce.
+type Event struct {
+	Nonce uint64
+	Log   ethtypes.Log
+}
+
+// Index is a persistent local index of the events emitted by the Gravity contract, keyed by event nonce and by
+// block. It lets the oracle, the resync logic and the valset finder avoid scanning the chain backwards.
+type Index interface {
+	// IndexedRange returns the first and last Ethereum blocks whose events are all stored in the index. The last
+	// return value is false if nothing has been indexed yet.
+	IndexedRange() (firstBlock, lastBlock uint64, ok bool, err error)
+
+	// StoreEvents stores the events found between fromBlock and toBlock (both inclusive) and moves the last indexed
+	// block forward. Unless the index is empty, fromBlock must follow the last indexed block or ErrIndexMoved is
+	// returned.
+	StoreEvents(events []Event, fromBlock, toBlock uint64) error
+
+	// EventByNonce returns the log of the event with the given nonce or ErrNotFound.
+	EventByNonce(nonce uint64) (*ethtypes.Log, error)
+
+	// EventsInRange returns the logs emitted between fromBlock and toBlock (both inclusive), in chain order.
+	EventsInRange(fromBlock, toBlock uint64) ([]ethtypes.Log, error)
+
+	// LatestEventByTopic returns the log with the highest nonce of the given event type or ErrNotFound.
+	LatestEventByTopic(topic ethcmn.Hash) (*ethtypes.Log, error)
+
+	// Truncate removes the events emitted from fromBlock onwards and moves the last indexed block back before it, so
+	// those blocks get indexed again.
+	Truncate(fromBlock uint64) error
+
+	// Reset removes every event from the index, so it can be rebuilt from scratch.
+	Reset() error
+
+	Close() error
+}
+
+// Covers returns true if every event emitted between fromBlock and toBlock (both inclusive) is in the index.
+func Covers(idx Index, fromBlock, toBlock uint64) bool {
+	if idx == nil {
+		return false
+	}
+
+	firstBlock, lastBlock, ok, err := idx.IndexedRange()
+	if err != nil || !ok {
+		return false
+	}
+
+	return firstBlock <= fromBlock && toBlock <= lastBlock
+}
@@ -0,0 +1,173 @@
+package eventindex
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/cicizeo/loran/orchestrator/blockrange"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+	"github.com/cicizeo/loran/orchestrator/finality"
+	"github.com/cicizeo/loran/orchestrator/loops"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+// Indexer fills an Index with the events emitted by the Gravity contract, from startBlock up to the latest block
+// considered final.
+type Indexer struct {
+	logger          zerolog.Logger
+	index           Index
+	ethProvider     provider.EVMProvider
+	gravityAddress  ethcmn.Address
+	gravityFilterer *wrappers.GravityFilterer
+	startBlock      uint64
+	blockRange      *blockrange.Sizer
+	finality        finality.Strategy
+	loopDuration    time.Duration
+}
+
+func NewIndexer(
+	logger zerolog.Logger,
+	index Index,
+	ethProvider provider.EVMProvider,
+	gravityAddress ethcmn.Address,
+	startBlock uint64,
+	blocksPerLoop uint64,
+	finalityStrategy finality.Strategy,
+	loopDuration time.Duration,
+) (*Indexer, error) {
+	gravityFilterer, err := wrappers.NewGravityFilterer(gravityAddress, ethProvider)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to init Gravity events filterer")
+	}
+
+	return &Indexer{
+		logger:          logger.With().Str("module", "event_indexer").Logger(),
+		index:           index,
+		ethProvider:     ethProvider,
+		gravityAddress:  gravityAddress,
+		gravityFilterer: gravityFilterer,
+		startBlock:      startBlock,
+		blockRange:      blockrange.NewSizer(logger, blocksPerLoop),
+		finality:        finalityStrategy,
+		loopDuration:    loopDuration,
+	}, nil
+}
+
+// Start keeps the index up to date in the background. Failures are logged and retried on the next loop, as the
+// consumers of the index fall back to querying Ethereum for anything that hasn't been indexed yet.
+func (i *Indexer) Start(ctx context.Context) error {
+	return loops.RunLoop(ctx, i.logger, i.loopDuration, func() error {
+		lastIndexedBlock, err := i.Sync(ctx)
+		if err != nil {
+			i.logger.Err(err).Msg("failed to index Gravity events; will retry")
+			return nil
+		}
+
+		i.logger.Debug().Uint64("last_indexed_block", lastIndexedBlock).Msg("event index is up to date")
+		return nil
+	})
+}
+
+// Sync indexes every block from the last indexed one up to the latest final block and returns the new last indexed
+// block.
+func (i *Indexer) Sync(ctx context.Context) (uint64, error) {
+	_, lastIndexedBlock, indexed, err := i.index.IndexedRange()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get indexed range")
+	}
+
+	// only index blocks that are final, to ensure minimum confirmations are received
+	latestBlock, err := i.finality.LatestFinalBlock(ctx, i.ethProvider)
+	if err != nil {
+		return lastIndexedBlock, err
+	}
+
+	fromBlock := i.startBlock
+	if indexed {
+		fromBlock = lastIndexedBlock + 1
+	}
+
+	for fromBlock <= latestBlock {
+		if ctx.Err() != nil {
+			return lastIndexedBlock, nil
+		}
+
+		toBlock := latestBlock
+		if blocksPerLoop := i.blockRange.Size(); toBlock-fromBlock > blocksPerLoop {
+			toBlock = fromBlock + blocksPerLoop
+		}
+
+		if err := i.indexRange(ctx, fromBlock, toBlock); errors.Is(err, ErrIndexMoved) {
+			// The index was truncated meanwhile, start again from where it is now.
+			_, lastIndexedBlock, indexed, err = i.index.IndexedRange()
+			if err != nil {
+				return 0, errors.Wrap(err, "failed to get indexed range")
+			}
+
+			fromBlock = i.startBlock
+			if indexed {
+				fromBlock = lastIndexedBlock + 1
+			}
+
+			i.logger.Info().Uint64("from_block", fromBlock).Msg("event index truncated, syncing again")
+			continue
+		} else if err != nil {
+			return lastIndexedBlock, err
+		}
+
+		lastIndexedBlock = toBlock
+		fromBlock = toBlock + 1
+	}
+
+	return lastIndexedBlock, nil
+}
+
+// Rebuild drops everything in the index and indexes the whole history again.
+func (i *Indexer) Rebuild(ctx context.Context) (uint64, error) {
+	if err := i.index.Reset(); err != nil {
+		return 0, errors.Wrap(err, "failed to reset event index")
+	}
+
+	return i.Sync(ctx)
+}
+
+func (i *Indexer) indexRange(ctx context.Context, fromBlock, toBlock uint64) error {
+	logs, err := i.blockRange.FilterLogs(ctx, i.ethProvider, func(fromBlock, toBlock uint64) ethereum.FilterQuery {
+		return FilterQuery(i.gravityAddress, fromBlock, toBlock)
+	}, fromBlock, toBlock)
+	if err != nil {
+		return errors.Wrap(err, "failed to filter Gravity events")
+	}
+
+	events := make([]Event, 0, len(logs))
+	for _, log := range logs {
+		if log.Removed {
+			continue
+		}
+
+		nonce, ok, err := EventNonce(i.gravityFilterer, log)
+		if err != nil {
+			return err
+		} else if !ok {
+			continue
+		}
+
+		events = append(events, Event{Nonce: nonce, Log: log})
+	}
+
+	if err := i.index.StoreEvents(events, fromBlock, toBlock); err != nil {
+		return errors.Wrap(err, "failed to store Gravity events")
+	}
+
+	i.logger.Debug().
+		Uint64("start", fromBlock).
+		Uint64("end", toBlock).
+		Int("num_events", len(events)).
+		Msg("indexed Gravity events")
+
+	return nil
+}
@@ -0,0 +1,160 @@
+package eventindex
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/finality"
+)
+
+func TestIndexerSync(t *testing.T) {
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	// The test data is from a real tx: https://goerli.etherscan.io/tx/0x09310b8dcc615b0baab5c0c41e9e7633f513c23532d0f191509d65e5a28b4ed7#eventlog
+	erc20DeployedLog := ethtypes.Log{
+		Address:     gravityAddress,
+		Topics:      []ethcmn.Hash{TopicERC20Deployed, ethcmn.HexToHash("0x00000000000000000000000053cf531308195be45981e75d1c217a61358f2c27")},
+		Data:        hexutil.MustDecode("0x00000000000000000000000000000000000000000000000000000000000000a000000000000000000000000000000000000000000000000000000000000000e0000000000000000000000000000000000000000000000000000000000000012000000000000000000000000000000000000000000000000000000000000000060000000000000000000000000000000000000000000000000000000000000378000000000000000000000000000000000000000000000000000000000000000575756d65650000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d6565000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000004756d656500000000000000000000000000000000000000000000000000000000"),
+		BlockNumber: 3,
+		TxHash:      ethcmn.HexToHash("0x0"),
+		TxIndex:     2,
+		BlockHash:   ethcmn.HexToHash("0x0"),
+		Index:       1,
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		idx, err := NewBadgerIndex(logger, "")
+		assert.NoError(t, err)
+		defer idx.Close()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(20),
+		}, nil)
+
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), ethereum.FilterQuery{
+			FromBlock: big.NewInt(1),
+			ToBlock:   big.NewInt(6),
+			Addresses: []ethcmn.Address{gravityAddress},
+			Topics:    [][]ethcmn.Hash{Topics()},
+		}).Return([]ethtypes.Log{erc20DeployedLog}, nil)
+
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), ethereum.FilterQuery{
+			FromBlock: big.NewInt(7),
+			ToBlock:   big.NewInt(10),
+			Addresses: []ethcmn.Address{gravityAddress},
+			Topics:    [][]ethcmn.Hash{Topics()},
+		}).Return([]ethtypes.Log{}, nil)
+
+		indexer, err := NewIndexer(logger, idx, ethProvider, gravityAddress, 1, 5, finality.FixedDepth(10), time.Second)
+		assert.NoError(t, err)
+
+		lastIndexedBlock, err := indexer.Sync(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(10), lastIndexedBlock)
+
+		firstBlock, lastBlock, ok, err := idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(1), firstBlock)
+		assert.Equal(t, uint64(10), lastBlock)
+
+		log, err := idx.EventByNonce(888)
+		assert.NoError(t, err)
+		assert.Equal(t, erc20DeployedLog.BlockNumber, log.BlockNumber)
+		assert.Equal(t, erc20DeployedLog.Data, log.Data)
+	})
+
+	t.Run("truncated while syncing", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		idx, err := NewBadgerIndex(logger, "")
+		assert.NoError(t, err)
+		defer idx.Close()
+		assert.NoError(t, idx.StoreEvents([]Event{{Nonce: 888, Log: erc20DeployedLog}}, 1, 4))
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(20),
+		}, nil)
+
+		// the oracle drops block 3 and onwards while blocks 5 to 10 are fetched
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), ethereum.FilterQuery{
+			FromBlock: big.NewInt(5),
+			ToBlock:   big.NewInt(10),
+			Addresses: []ethcmn.Address{gravityAddress},
+			Topics:    [][]ethcmn.Hash{Topics()},
+		}).DoAndReturn(func(context.Context, ethereum.FilterQuery) ([]ethtypes.Log, error) {
+			assert.NoError(t, idx.Truncate(3))
+			return []ethtypes.Log{}, nil
+		})
+
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), ethereum.FilterQuery{
+			FromBlock: big.NewInt(3),
+			ToBlock:   big.NewInt(8),
+			Addresses: []ethcmn.Address{gravityAddress},
+			Topics:    [][]ethcmn.Hash{Topics()},
+		}).Return([]ethtypes.Log{erc20DeployedLog}, nil)
+
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), ethereum.FilterQuery{
+			FromBlock: big.NewInt(9),
+			ToBlock:   big.NewInt(10),
+			Addresses: []ethcmn.Address{gravityAddress},
+			Topics:    [][]ethcmn.Hash{Topics()},
+		}).Return([]ethtypes.Log{}, nil)
+
+		indexer, err := NewIndexer(logger, idx, ethProvider, gravityAddress, 1, 5, finality.FixedDepth(10), time.Second)
+		assert.NoError(t, err)
+
+		lastIndexedBlock, err := indexer.Sync(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, uint64(10), lastIndexedBlock)
+
+		// block 3 was indexed again
+		log, err := idx.EventByNonce(888)
+		assert.NoError(t, err)
+		assert.Equal(t, erc20DeployedLog.BlockNumber, log.BlockNumber)
+	})
+
+	t.Run("error on FilterLogs", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		idx, err := NewBadgerIndex(logger, "")
+		assert.NoError(t, err)
+		defer idx.Close()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(20),
+		}, nil)
+		ethProvider.EXPECT().FilterLogs(gomock.Any(), gomock.Any()).Return(nil, errors.New("some error"))
+
+		indexer, err := NewIndexer(logger, idx, ethProvider, gravityAddress, 1, 5, finality.FixedDepth(10), time.Second)
+		assert.NoError(t, err)
+
+		_, err = indexer.Sync(context.Background())
+		assert.EqualError(t, err, "failed to filter Gravity events: some error")
+
+		_, _, ok, err := idx.IndexedRange()
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,74 @@
+package finality
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+)
+
+// HeaderReader is the subset of the EVM provider needed to find out which blocks are final.
+type HeaderReader interface {
+	HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error)
+}
+
+// Strategy decides which Ethereum blocks are considered final, so their events can be relayed without the risk of
+// them being reorganised away.
+type Strategy interface {
+	// LatestFinalBlock returns the number of the most recent block considered final.
+	LatestFinalBlock(ctx context.Context, headers HeaderReader) (uint64, error)
+
+	fmt.Stringer
+}
+
+// FixedDepth considers a block final once it has the given number of confirmations on top of it.
+type FixedDepth uint64
+
+func (d FixedDepth) LatestFinalBlock(ctx context.Context, headers HeaderReader) (uint64, error) {
+	latestHeader, err := headers.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to get latest header")
+	}
+
+	latestBlock := latestHeader.Number.Uint64()
+	if latestBlock < uint64(d) {
+		return 0, nil
+	}
+
+	return latestBlock - uint64(d), nil
+}
+
+func (d FixedDepth) String() string {
+	return fmt.Sprintf("depth(%d)", uint64(d))
+}
+
+// blockTag relies on the consensus layer of a post-merge chain to tell which blocks are final.
+type blockTag struct {
+	name   string
+	number *big.Int
+}
+
+var (
+	// Finalized considers final every block up to the one returned by the "finalized" block tag.
+	Finalized Strategy = blockTag{name: "finalized", number: provider.FinalizedBlockNumber}
+
+	// Safe considers final every block up to the one returned by the "safe" block tag. Safe blocks are very unlikely
+	// to be reorganised, but unlike finalized ones it is not impossible.
+	Safe Strategy = blockTag{name: "safe", number: provider.SafeBlockNumber}
+)
+
+func (t blockTag) LatestFinalBlock(ctx context.Context, headers HeaderReader) (uint64, error) {
+	header, err := headers.HeaderByNumber(ctx, t.number)
+	if err != nil {
+		return 0, errors.Wrapf(err, "failed to get %s header", t.name)
+	}
+
+	return header.Number.Uint64(), nil
+}
+
+func (t blockTag) String() string {
+	return t.name
+}
@@ -0,0 +1,80 @@
+package finality
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+)
+
+func TestFixedDepth(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{Number: big.NewInt(100)}, nil)
+
+		block, err := FixedDepth(13).LatestFinalBlock(context.Background(), ethProvider)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(87), block)
+	})
+
+	t.Run("chain shorter than depth", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{Number: big.NewInt(5)}, nil)
+
+		block, err := FixedDepth(13).LatestFinalBlock(context.Background(), ethProvider)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(0), block)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(nil, errors.New("some error"))
+
+		_, err := FixedDepth(13).LatestFinalBlock(context.Background(), ethProvider)
+		assert.EqualError(t, err, "failed to get latest header: some error")
+	})
+}
+
+func TestBlockTag(t *testing.T) {
+	t.Run("finalized", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().
+			HeaderByNumber(gomock.Any(), provider.FinalizedBlockNumber).
+			Return(&ethtypes.Header{Number: big.NewInt(68)}, nil)
+
+		block, err := Finalized.LatestFinalBlock(context.Background(), ethProvider)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(68), block)
+	})
+
+	t.Run("safe", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().
+			HeaderByNumber(gomock.Any(), provider.SafeBlockNumber).
+			Return(nil, errors.New("some error"))
+
+		_, err := Safe.LatestFinalBlock(context.Background(), ethProvider)
+		assert.EqualError(t, err, "failed to get safe header: some error")
+	})
+}
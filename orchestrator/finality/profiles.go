@@ -0,0 +1,139 @@
+package finality
+
+import (
+	"strconv"
+
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/pkg/errors"
+)
+
+// Strategy types, as used in the flags and in the profiles file.
+const (
+	TypeDepth     = "depth"
+	TypeFinalized = "finalized"
+	TypeSafe      = "safe"
+)
+
+// Profiles holds the finality strategy to use for each chain, and the one to use for any other chain.
+type Profiles struct {
+	Default Strategy
+	Chains  map[uint64]Strategy
+}
+
+// ForChain returns the finality strategy to use for the given chain ID.
+func (p Profiles) ForChain(chainID uint64) Strategy {
+	if s, ok := p.Chains[chainID]; ok {
+		return s
+	}
+
+	return p.Default
+}
+
+// DefaultProfiles returns the built-in profiles.
+// Copying from https://github.com/Gravity-Bridge/Gravity-Bridge/blob/main/orchestrator/orchestrator/src/ethereum_event_watcher.rs#L248
+// DO NOT MODIFY. Changing any of these values puts the network in DANGER and
+// does not provide any advantage over other validators. This is a safety
+// mechanism to prevent relaying an event that is not yet considered final.
+func DefaultProfiles() Profiles {
+	return Profiles{
+		// assume the safe option (POW) where we don't know
+		Default: FixedDepth(13),
+		Chains: map[uint64]Strategy{
+			// Mainline Ethereum, Ethereum classic, or the Ropsten, Kotti, Mordor testnets
+			// all POW Chains
+			1: FixedDepth(13),
+			3: FixedDepth(13),
+			6: FixedDepth(13),
+			7: FixedDepth(13),
+
+			// Dev, our own Gravity Ethereum testnet, and Hardhat respectively
+			// all single signer chains with no chance of any reorgs
+			2018:  FixedDepth(0),
+			15:    FixedDepth(0),
+			31337: FixedDepth(0),
+
+			// Rinkeby and Goerli use Clique (POA) Consensus, finality takes
+			// up to num validators blocks. Number is higher than Ethereum based
+			// on experience with operational issues
+			4: FixedDepth(10),
+			5: FixedDepth(10),
+		},
+	}
+}
+
+// SingleProfile returns profiles that use the same strategy for every chain.
+func SingleProfile(s Strategy) Profiles {
+	return Profiles{Default: s}
+}
+
+// ParseStrategy returns the strategy of the given type. The depth is only used by TypeDepth.
+func ParseStrategy(strategyType string, depth uint64) (Strategy, error) {
+	switch strategyType {
+	case TypeDepth:
+		return FixedDepth(depth), nil
+	case TypeFinalized:
+		return Finalized, nil
+	case TypeSafe:
+		return Safe, nil
+	default:
+		return nil, errors.Errorf("unknown finality strategy: %s", strategyType)
+	}
+}
+
+type profileConfig struct {
+	Type  string `koanf:"type"`
+	Depth uint64 `koanf:"depth"`
+}
+
+// LoadProfiles loads per-chain profiles from a TOML file, on top of the built-in ones. For instance:
+//
+//	[default]
+//	type = "depth"
+//	depth = 13
+//
+//	[chains.1]
+//	type = "finalized"
+func LoadProfiles(path string) (Profiles, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), toml.Parser()); err != nil {
+		return Profiles{}, errors.Wrap(err, "failed to load finality profiles")
+	}
+
+	profiles := DefaultProfiles()
+
+	if k.Exists("default") {
+		s, err := parseProfile(k.Cut("default"))
+		if err != nil {
+			return Profiles{}, errors.Wrap(err, "invalid default finality profile")
+		}
+
+		profiles.Default = s
+	}
+
+	for _, key := range k.MapKeys("chains") {
+		chainID, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return Profiles{}, errors.Wrapf(err, "invalid chain ID in finality profiles: %s", key)
+		}
+
+		s, err := parseProfile(k.Cut("chains." + key))
+		if err != nil {
+			return Profiles{}, errors.Wrapf(err, "invalid finality profile for chain %d", chainID)
+		}
+
+		profiles.Chains[chainID] = s
+	}
+
+	return profiles, nil
+}
+
+func parseProfile(k *koanf.Koanf) (Strategy, error) {
+	var cfg profileConfig
+	if err := k.Unmarshal("", &cfg); err != nil {
+		return nil, err
+	}
+
+	return ParseStrategy(cfg.Type, cfg.Depth)
+}
@@ -0,0 +1,92 @@
+package finality
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultProfiles(t *testing.T) {
+	profiles := DefaultProfiles()
+
+	assert.Equal(t, FixedDepth(13), profiles.ForChain(1))
+	assert.Equal(t, FixedDepth(0), profiles.ForChain(2018))
+	assert.Equal(t, FixedDepth(10), profiles.ForChain(5))
+	assert.Equal(t, FixedDepth(13), profiles.ForChain(1235))
+}
+
+func TestParseStrategy(t *testing.T) {
+	s, err := ParseStrategy(TypeDepth, 20)
+	assert.Nil(t, err)
+	assert.Equal(t, FixedDepth(20), s)
+
+	s, err = ParseStrategy(TypeFinalized, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, Finalized, s)
+
+	s, err = ParseStrategy(TypeSafe, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, Safe, s)
+
+	_, err = ParseStrategy("latest", 0)
+	assert.EqualError(t, err, "unknown finality strategy: latest")
+}
+
+func TestLoadProfiles(t *testing.T) {
+	dir, err := os.MkdirTemp("", "finality")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	t.Run("ok", func(t *testing.T) {
+		path := filepath.Join(dir, "ok.toml")
+		assert.Nil(t, os.WriteFile(path, []byte(`
+[default]
+type = "safe"
+
+[chains.1]
+type = "finalized"
+
+[chains.137]
+type = "depth"
+depth = 256
+`), 0o600))
+
+		profiles, err := LoadProfiles(path)
+		assert.Nil(t, err)
+
+		assert.Equal(t, Safe, profiles.ForChain(1235))
+		assert.Equal(t, Finalized, profiles.ForChain(1))
+		assert.Equal(t, FixedDepth(256), profiles.ForChain(137))
+
+		// built-in profiles are kept unless overridden
+		assert.Equal(t, FixedDepth(10), profiles.ForChain(5))
+	})
+
+	t.Run("unknown type", func(t *testing.T) {
+		path := filepath.Join(dir, "unknown.toml")
+		assert.Nil(t, os.WriteFile(path, []byte(`
+[chains.1]
+type = "latest"
+`), 0o600))
+
+		_, err := LoadProfiles(path)
+		assert.EqualError(t, err, "invalid finality profile for chain 1: unknown finality strategy: latest")
+	})
+
+	t.Run("invalid chain ID", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.toml")
+		assert.Nil(t, os.WriteFile(path, []byte(`
+[chains.mainnet]
+type = "finalized"
+`), 0o600))
+
+		_, err := LoadProfiles(path)
+		assert.EqualError(
+			t,
+			err,
+			`invalid chain ID in finality profiles: mainnet: strconv.ParseUint: parsing "mainnet": invalid syntax`,
+		)
+	})
+}
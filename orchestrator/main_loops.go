@@ -8,7 +8,10 @@ import (
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	"github.com/avast/retry-go"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
 	"github.com/cicizeo/loran/orchestrator/loops"
+	"github.com/cicizeo/loran/orchestrator/signjournal"
 )
 
 const (
@@ -43,6 +46,12 @@ func (p *gravityOrchestrator) Start(ctx context.Context) error {
 		return p.RelayerMainLoop(ctx)
 	})
 
+	if p.eventIndex != nil {
+		pg.Go(func() error {
+			return p.EventIndexerLoop(ctx)
+		})
+	}
+
 	return pg.Wait()
 }
 
@@ -73,17 +82,21 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 		return err
 	}
 
+	finalityStrategy := p.finalityProfiles.ForChain(gravityParams.BridgeChainId)
+	logger.Info().
+		Uint64("bridge_chain_id", gravityParams.BridgeChainId).
+		Stringer("finality", finalityStrategy).
+		Msg("using finality strategy")
+
 	// Wait until the contract is available
 	if p.bridgeStartHeight != 0 {
 		for {
-			latestHeader, err := p.ethProvider.HeaderByNumber(ctx, nil)
+			currentBlock, err := finalityStrategy.LatestFinalBlock(ctx, p.ethProvider)
 			if err != nil {
-				logger.Err(err).Msg("failed to get latest header, loop exits")
+				logger.Err(err).Msg("failed to get latest final block, loop exits")
 				return err
 			}
 
-			currentBlock := latestHeader.Number.Uint64() - getEthBlockDelay(gravityParams.BridgeChainId)
-
 			if currentBlock < p.bridgeStartHeight {
 				wait := p.ethereumBlockTime * time.Duration(p.bridgeStartHeight-currentBlock)
 				logger.Error().
@@ -102,7 +115,7 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 	}
 
 	if err := retry.Do(func() (err error) {
-		lastCheckedBlock, err = p.GetLastCheckedBlock(ctx, getEthBlockDelay(gravityParams.BridgeChainId))
+		lastCheckedBlock, err = p.GetLastCheckedBlock(ctx, finalityStrategy)
 		return err
 	}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
 		logger.Err(err).Uint("retry", n).Msg("failed to get last checked block; retrying...")
@@ -114,10 +127,32 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 	logger.Info().Uint64("last_checked_block", lastCheckedBlock).Msg("start scanning for events")
 
 	return loops.RunLoop(ctx, p.logger, p.ethereumBlockTime*ethOracleLoopMultiplier, func() error {
+		// Rewind if any of the blocks we already scanned got orphaned, so they are scanned again.
+		if err := retry.Do(func() (err error) {
+			lastCheckedBlock, err = p.RewindOnReorg(ctx, lastCheckedBlock)
+			return err
+		}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
+			logger.Err(err).Uint("retry", n).Msg("failed to check for chain reorganisations; retrying...")
+		})); err != nil {
+			logger.Err(err).Msg("got error, loop exits")
+			return err
+		}
+
+		// Rewind if we are behind the events we have seen or the contract, so the missing events are claimed again.
+		if err := retry.Do(func() (err error) {
+			lastCheckedBlock, err = p.RewindOnNonceGap(ctx, lastCheckedBlock, finalityStrategy)
+			return err
+		}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
+			logger.Err(err).Uint("retry", n).Msg("failed to check for event nonce gaps; retrying...")
+		})); err != nil {
+			logger.Err(err).Msg("got error, loop exits")
+			return err
+		}
+
 		// Relays events from Ethereum -> Cosmos
 		var currentBlock uint64
 		if err := retry.Do(func() (err error) {
-			currentBlock, err = p.CheckForEvents(ctx, lastCheckedBlock, getEthBlockDelay(gravityParams.BridgeChainId))
+			currentBlock, err = p.CheckForEvents(ctx, lastCheckedBlock, finalityStrategy)
 			return err
 		}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
 			logger.Err(err).Uint("retry", n).Msg("error during Eth event checking; retrying...")
@@ -128,7 +163,8 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 
 		lastCheckedBlock = currentBlock
 
-		// Auto re-sync to catch up the nonce. Reasons why event nonce fall behind.
+		// Auto re-sync to catch up the nonce. Event nonce gaps are already detected and rewound to on every loop, this
+		// is only a fallback in case the gap detection itself was fooled. Reasons why event nonce fall behind.
 		//	1. It takes some time for events to be indexed on Ethereum. So if loran queried events immediately as
 		//	   block produced, there is a chance the event is missed. We need to re-scan this block to ensure events
 		//	   are not missed due to indexing delay.
@@ -137,7 +173,7 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 		//	   last iteration.
 		if time.Since(lastResync) >= 48*time.Hour {
 			if err := retry.Do(func() (err error) {
-				lastCheckedBlock, err = p.GetLastCheckedBlock(ctx, getEthBlockDelay(gravityParams.BridgeChainId))
+				lastCheckedBlock, err = p.GetLastCheckedBlock(ctx, finalityStrategy)
 				return err
 			}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
 				logger.Err(err).Uint("retry", n).Msg("failed to get last checked block; retrying...")
@@ -159,7 +195,8 @@ func (p *gravityOrchestrator) EthOracleMainLoop(ctx context.Context) (err error)
 
 // EthSignerMainLoop simply signs off on any batches or validator sets provided by the validator
 // since these are provided directly by a trusted Cosmsos node they can simply be assumed to be
-// valid and signed off on.
+// valid and signed off on. When the node is not trusted (e.g. a shared sentry), the query client
+// can prove them against the committed state first, see lightclient.QueryClient.
 func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error) {
 	logger := p.logger.With().Str("loop", "EthSignerMainLoop").Logger()
 
@@ -177,6 +214,9 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 	logger.Debug().Str("gravityID", gravityID).Msg("received gravityID")
 
 	return loops.RunLoop(ctx, p.logger, p.cosmosBlockTime*ethSignerLoopMultiplier, func() error {
+		// the items Cosmos asks us to sign in this loop
+		pendingSignatures := make(map[signjournal.Key]bool)
+
 		var oldestUnsignedValsets []types.Valset
 		if err := retry.Do(func() error {
 			oldestValsets, err := p.cosmosQueryClient.LastPendingValsetRequestByAddr(
@@ -205,8 +245,27 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 		}
 
 		for _, oldestValset := range oldestUnsignedValsets {
-			logger.Info().Uint64("oldest_valset_nonce", oldestValset.Nonce).Msg("sending Valset confirm for nonce")
 			valset := oldestValset
+			allowed, err := p.valsetAllowed(ctx, valset)
+			if err != nil {
+				logger.Err(err).Msg("got error, loop exits")
+				return err
+			} else if !allowed {
+				continue
+			}
+
+			journalKey := signjournal.Key{Kind: signjournal.KindValset, GravityID: gravityID, Nonce: valset.Nonce}
+			pendingSignatures[journalKey] = true
+
+			sign, err := p.reserveSignature(logger, journalKey, gravity.EncodeValsetConfirm(gravityID, valset))
+			if err != nil {
+				logger.Err(err).Msg("got error, loop exits")
+				return err
+			} else if !sign {
+				continue
+			}
+
+			logger.Info().Uint64("oldest_valset_nonce", valset.Nonce).Msg("sending Valset confirm for nonce")
 
 			if err := retry.Do(func() error {
 				return p.gravityBroadcastClient.SendValsetConfirm(ctx, p.ethFrom, gravityID, valset)
@@ -218,11 +277,13 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 				logger.Err(err).Msg("got error, loop exits")
 				return err
 			}
+
+			p.awaitSignature(journalKey)
 		}
 
 		var oldestUnsignedTransactionBatch []types.OutgoingTxBatch
 		if err := retry.Do(func() error {
-			// sign the last unsigned batch, TODO check if we already have signed this
+			// sign the last unsigned batch, the signing journal makes sure we never sign it twice
 			txBatch, err := p.cosmosQueryClient.LastPendingBatchRequestByAddr(
 				ctx,
 				&types.QueryLastPendingBatchRequestByAddrRequest{
@@ -252,6 +313,27 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 
 		for _, batch := range oldestUnsignedTransactionBatch {
 			batch := batch
+			if !p.batchAllowed(batch) {
+				continue
+			}
+
+			journalKey := signjournal.Key{
+				Kind:      signjournal.KindBatch,
+				GravityID: gravityID,
+				// the node could change the case of the address to get a different checkpoint signed for the nonce
+				Scope: ethcmn.HexToAddress(batch.TokenContract).Hex(),
+				Nonce: batch.BatchNonce,
+			}
+			pendingSignatures[journalKey] = true
+
+			sign, err := p.reserveSignature(logger, journalKey, gravity.EncodeTxBatchConfirm(gravityID, batch))
+			if err != nil {
+				logger.Err(err).Msg("got error, loop exits")
+				return err
+			} else if !sign {
+				continue
+			}
+
 			logger.Info().
 				Uint64("batch_nonce", batch.BatchNonce).
 				Msg("sending TransactionBatch confirm for BatchNonce")
@@ -265,6 +347,17 @@ func (p *gravityOrchestrator) EthSignerMainLoop(ctx context.Context) (err error)
 				logger.Err(err).Msg("got error, loop exits")
 				return err
 			}
+
+			p.awaitSignature(journalKey)
+
+			if p.signPolicy != nil {
+				p.signPolicy.RecordBatch(batch)
+			}
+		}
+
+		if err := p.confirmSignatures(ctx, logger, pendingSignatures); err != nil {
+			logger.Err(err).Msg("got error, loop exits")
+			return err
 		}
 
 		return nil
@@ -335,6 +428,47 @@ func (p *gravityOrchestrator) RelayerMainLoop(ctx context.Context) (err error) {
 	return errors.New("relayer is nil")
 }
 
+// EventIndexerLoop keeps the local Gravity event index up to date, so the oracle, the resync logic and the relayer
+// can read past events from disk instead of scanning Ethereum.
+func (p *gravityOrchestrator) EventIndexerLoop(ctx context.Context) (err error) {
+	logger := p.logger.With().Str("loop", "EventIndexerLoop").Logger()
+
+	var gravityParams types.Params
+	if err := retry.Do(func() (err error) {
+		gravityParamsResp, err := p.cosmosQueryClient.Params(ctx, &types.QueryParamsRequest{})
+		if err != nil {
+			return err
+		} else if gravityParamsResp == nil {
+			return errors.New("no Gravity params returned")
+		}
+
+		gravityParams = gravityParamsResp.Params
+		return nil
+	}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
+		logger.Err(err).Uint("retry", n).Msg("failed to get Gravity params; retrying...")
+	})); err != nil {
+		logger.Err(err).Msg("got error, loop exits")
+		return err
+	}
+
+	indexer, err := eventindex.NewIndexer(
+		p.logger,
+		p.eventIndex,
+		p.ethProvider,
+		p.gravityContract.Address(),
+		p.bridgeStartHeight,
+		p.ethBlocksPerLoop,
+		p.finalityProfiles.ForChain(gravityParams.BridgeChainId),
+		p.ethereumBlockTime*ethOracleLoopMultiplier,
+	)
+	if err != nil {
+		logger.Err(err).Msg("failed to create event indexer, loop exits")
+		return err
+	}
+
+	return indexer.Start(ctx)
+}
+
 // ERC20ToDenom attempts to return the denomination that maps to an ERC20 token
 // contract on the Cosmos chain. First, we check the cache. If the token address
 // does not exist in the cache, we query the Cosmos chain and cache the result.
@@ -364,33 +498,3 @@ func (p *gravityOrchestrator) ERC20ToDenom(ctx context.Context, tokenAddr ethcmn
 	p.erc20DenomCache[tokenAddrStr] = resp.Denom
 	return resp.Denom, nil
 }
-
-// getEthBlockDelay returns the right amount of Ethereum blocks to wait until we
-// consider a block final. This depends on the chain we are talking to.
-// Copying from https://github.com/Gravity-Bridge/Gravity-Bridge/blob/main/orchestrator/orchestrator/src/ethereum_event_watcher.rs#L248
-// DO NOT MODIFY. Changing any of these values puts the network in DANGER and
-// does not provide any advantage over other validators. This is a safety
-// mechanism to prevent relaying an event that is not yet considered final.
-func getEthBlockDelay(chainID uint64) uint64 {
-	switch chainID {
-	// Mainline Ethereum, Ethereum classic, or the Ropsten, Kotti, Mordor testnets
-	// all POW Chains
-	case 1, 3, 6, 7:
-		return 13
-
-	// Dev, our own Gravity Ethereum testnet, and Hardhat respectively
-	// all single signer chains with no chance of any reorgs
-	case 2018, 15, 31337:
-		return 0
-
-	// Rinkeby and Goerli use Clique (POA) Consensus, finality takes
-	// up to num validators blocks. Number is higher than Ethereum based
-	// on experience with operational issues
-	case 4, 5:
-		return 10
-
-	// assume the safe option (POW) where we don't know
-	default:
-		return 13
-	}
-}
@@ -52,10 +52,3 @@ func TestERC20ToDenom(t *testing.T) {
 		assert.Equal(t, "", denom)
 	})
 }
-
-func TestGetEthBlockDelay(t *testing.T) {
-	assert.Equal(t, uint64(13), getEthBlockDelay(1))
-	assert.Equal(t, uint64(0), getEthBlockDelay(2018))
-	assert.Equal(t, uint64(10), getEthBlockDelay(5))
-	assert.Equal(t, uint64(13), getEthBlockDelay(1235))
-}
@@ -0,0 +1,125 @@
+package orchestrator
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/pkg/errors"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/finality"
+)
+
+// maxObservedNonces is the number of event nonces we keep the block of. They are forgotten as soon as our claims
+// catch up, so this is only reached if claims keep failing for a long time.
+const maxObservedNonces = 10000
+
+// recordObservedNonces keeps the block of every event we have scanned that we haven't claimed yet, so a gap in our
+// claims can be rewound to exactly.
+func (p *gravityOrchestrator) recordObservedNonces(events eventindex.Events, lastClaimedNonce uint64) {
+	if p.observedNonces == nil {
+		p.observedNonces = map[uint64]uint64{}
+	}
+
+	for nonce, block := range events.BlocksByNonce() {
+		if nonce <= lastClaimedNonce || len(p.observedNonces) >= maxObservedNonces {
+			continue
+		}
+
+		p.observedNonces[nonce] = block
+		if nonce > p.highestObservedNonce {
+			p.highestObservedNonce = nonce
+		}
+	}
+}
+
+// forgetObservedNonces drops the nonces we have claimed and the ones observed in blocks we are about to scan again.
+func (p *gravityOrchestrator) forgetObservedNonces(lastClaimedNonce uint64, fromBlock uint64) {
+	p.highestObservedNonce = lastClaimedNonce
+
+	for nonce, block := range p.observedNonces {
+		if nonce <= lastClaimedNonce || block >= fromBlock {
+			delete(p.observedNonces, nonce)
+			continue
+		}
+
+		if nonce > p.highestObservedNonce {
+			p.highestObservedNonce = nonce
+		}
+	}
+}
+
+// RewindOnNonceGap compares the nonce of the last event we claimed with the nonces we have observed while scanning
+// and with the last event nonce of the contract as of lastCheckedBlock. If we are behind either of them, some events
+// were missed or their claims failed, and the returned block is the one of the first missing event, so it will be
+// scanned again.
+func (p *gravityOrchestrator) RewindOnNonceGap(
+	ctx context.Context,
+	lastCheckedBlock uint64,
+	finalityStrategy finality.Strategy,
+) (uint64, error) {
+	lastEventResp, err := p.cosmosQueryClient.LastEventNonceByAddr(ctx, &types.QueryLastEventNonceByAddrRequest{
+		Address: p.gravityBroadcastClient.AccFromAddress().String(),
+	})
+	if err != nil {
+		return lastCheckedBlock, errors.Wrap(err, "failed to query last claim event from backend")
+	}
+
+	lastClaimedNonce := lastEventResp.EventNonce
+	p.forgetObservedNonces(lastClaimedNonce, lastCheckedBlock+1)
+
+	expectedNonce := p.highestObservedNonce
+
+	contractNonce, err := p.gravityContract.GetLastEventNonce(
+		ctx,
+		p.gravityContract.FromAddress(),
+		new(big.Int).SetUint64(lastCheckedBlock),
+	)
+	if err != nil {
+		// Nodes that are not archive nodes prune old states, so this may fail while catching up. The nonces we
+		// observed are enough to find most gaps.
+		p.logger.Debug().
+			Err(err).
+			Uint64("block_number", lastCheckedBlock).
+			Msg("failed to get the last event nonce of the contract")
+	} else if contractNonce.Uint64() > expectedNonce {
+		expectedNonce = contractNonce.Uint64()
+	}
+
+	if lastClaimedNonce >= expectedNonce {
+		return lastCheckedBlock, nil
+	}
+
+	missingNonce := lastClaimedNonce + 1
+
+	rewindTo, found := p.observedNonces[missingNonce]
+	if !found && p.eventIndex != nil {
+		if log, err := p.eventIndex.EventByNonce(missingNonce); err == nil {
+			rewindTo, found = log.BlockNumber, true
+		}
+	}
+
+	if !found {
+		// We never saw the missing event, but it can only come after the last one we claimed.
+		rewindTo, err = p.GetLastCheckedBlock(ctx, finalityStrategy)
+		if err != nil {
+			return lastCheckedBlock, err
+		}
+	}
+
+	if rewindTo > lastCheckedBlock {
+		return lastCheckedBlock, nil
+	}
+
+	p.logger.Warn().
+		Uint64("last_claimed_nonce", lastClaimedNonce).
+		Uint64("expected_nonce", expectedNonce).
+		Uint64("missing_nonce", missingNonce).
+		Uint64("last_checked_block", lastCheckedBlock).
+		Uint64("rewind_to", rewindTo).
+		Msg("EVENT NONCE GAP DETECTED: some events were not claimed; rewinding to the first missing one")
+
+	p.forgetObservedNonces(lastClaimedNonce, rewindTo)
+
+	return rewindTo, nil
+}
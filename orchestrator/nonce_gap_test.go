@@ -0,0 +1,97 @@
+package orchestrator
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	gravityMocks "github.com/cicizeo/loran/mocks/gravity"
+	"github.com/cicizeo/loran/orchestrator/cosmos"
+	"github.com/cicizeo/loran/orchestrator/finality"
+)
+
+func TestRewindOnNonceGap(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+	newOrch := func(mockCtrl *gomock.Controller, lastClaimedNonce uint64) (*gravityOrchestrator, *gravityMocks.MockContract) {
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockQClient.EXPECT().LastEventNonceByAddr(gomock.Any(), &types.QueryLastEventNonceByAddrRequest{
+			Address: sdk.AccAddress{}.String(),
+		}).Return(&types.QueryLastEventNonceByAddrResponse{
+			EventNonce: lastClaimedNonce,
+		}, nil)
+
+		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
+		mockCosmos.EXPECT().FromAddress().Return(sdk.AccAddress{}).AnyTimes()
+
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+
+		orch := &gravityOrchestrator{
+			logger:                 logger,
+			cosmosQueryClient:      mockQClient,
+			gravityBroadcastClient: cosmos.NewGravityBroadcastClient(logger, nil, mockCosmos, nil, nil, 10),
+			gravityContract:        mockGravityContract,
+		}
+
+		return orch, mockGravityContract
+	}
+
+	t.Run("no gap", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockGravityContract := newOrch(mockCtrl, 5)
+		mockGravityContract.EXPECT().GetLastEventNonce(gomock.Any(), fromAddress, big.NewInt(30)).
+			Return(big.NewInt(5), nil)
+
+		orch.observedNonces = map[uint64]uint64{4: 10, 5: 20}
+		orch.highestObservedNonce = 5
+
+		lastCheckedBlock, err := orch.RewindOnNonceGap(context.Background(), 30, finality.FixedDepth(0))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(30), lastCheckedBlock)
+		assert.Empty(t, orch.observedNonces)
+	})
+
+	t.Run("gap on an observed nonce", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockGravityContract := newOrch(mockCtrl, 5)
+		mockGravityContract.EXPECT().GetLastEventNonce(gomock.Any(), fromAddress, big.NewInt(30)).
+			Return(big.NewInt(7), nil)
+
+		orch.observedNonces = map[uint64]uint64{5: 10, 6: 20, 7: 25}
+		orch.highestObservedNonce = 7
+
+		lastCheckedBlock, err := orch.RewindOnNonceGap(context.Background(), 30, finality.FixedDepth(0))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(20), lastCheckedBlock)
+		assert.Empty(t, orch.observedNonces)
+		assert.Equal(t, uint64(5), orch.highestObservedNonce)
+	})
+
+	t.Run("contract state not available", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockGravityContract := newOrch(mockCtrl, 5)
+		mockGravityContract.EXPECT().GetLastEventNonce(gomock.Any(), fromAddress, big.NewInt(30)).
+			Return(nil, errors.New("missing trie node"))
+
+		lastCheckedBlock, err := orch.RewindOnNonceGap(context.Background(), 30, finality.FixedDepth(0))
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(30), lastCheckedBlock)
+	})
+}
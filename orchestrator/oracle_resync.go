@@ -4,15 +4,16 @@ import (
 	"context"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
-	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/pkg/errors"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/finality"
 	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
 )
 
 // GetLastCheckedBlock retrieves the Ethereum block height from the last claim event this oracle has relayed to Cosmos.
 func (p *gravityOrchestrator) GetLastCheckedBlock(
 	ctx context.Context,
-	ethBlockConfirmationDelay uint64,
+	finalityStrategy finality.Strategy,
 ) (uint64, error) {
 
 	lastEventResp, err := p.cosmosQueryClient.LastEventNonceByAddr(ctx, &types.QueryLastEventNonceByAddrRequest{
@@ -32,138 +33,72 @@ func (p *gravityOrchestrator) GetLastCheckedBlock(
 		lastEventNonce = 1
 	}
 
-	latestHeader, err := p.ethProvider.HeaderByNumber(ctx, nil)
+	if p.eventIndex != nil {
+		log, err := p.eventIndex.EventByNonce(lastEventNonce)
+		if err == nil {
+			return log.BlockNumber, nil
+		} else if err != eventindex.ErrNotFound {
+			p.logger.Err(err).Uint64("event_nonce", lastEventNonce).Msg("failed to read event from the event index")
+		}
+	}
+
+	// only search blocks that are final, to ensure minimum confirmations are received
+	currentBlock, err := finalityStrategy.LatestFinalBlock(ctx, p.ethProvider)
 	if err != nil {
-		err = errors.Wrap(err, "failed to get latest header")
 		return 0, err
 	}
 
-	// add delay to ensure minimum confirmations are received and block is finalized
-	currentBlock := latestHeader.Number.Uint64() - ethBlockConfirmationDelay
+	gravityFilterer, err := wrappers.NewGravityFilterer(p.gravityContract.Address(), p.ethProvider)
+	if err != nil {
+		err = errors.Wrap(err, "failed to init Gravity events filterer")
+		return 0, err
+	}
 
 	for currentBlock > 0 {
+		blocksPerLoop := p.blockRange.Size()
+
 		endSearch := uint64(0)
-		if currentBlock < p.ethBlocksPerLoop {
+		if currentBlock < blocksPerLoop {
 			endSearch = 0
 		} else {
-			endSearch = currentBlock - p.ethBlocksPerLoop
+			endSearch = currentBlock - blocksPerLoop
 		}
 
-		gravityFilterer, err := wrappers.NewGravityFilterer(p.gravityContract.Address(), p.ethProvider)
+		events, err := p.filterEvents(ctx, gravityFilterer, endSearch, currentBlock)
 		if err != nil {
-			err = errors.Wrap(err, "failed to init Gravity events filterer")
 			return 0, err
 		}
 
-		iterSendToCosmos, err := gravityFilterer.FilterSendToCosmosEvent(&bind.FilterOpts{
-			Start: endSearch,
-			End:   &currentBlock,
-		}, nil, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", endSearch).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past SendToCosmos events from Ethereum")
-
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past SendToCosmos events from Ethereum")
-				return 0, err
-			} else if iterSendToCosmos == nil {
-				return 0, errors.New("no iterator returned")
-			}
-		}
-
-		for iterSendToCosmos.Next() {
-			if iterSendToCosmos.Event.EventNonce.Uint64() == lastEventNonce {
-				return iterSendToCosmos.Event.Raw.BlockNumber, nil
-			}
-		}
-
-		iterSendToCosmos.Close()
-
-		iterTXBatchExec, err := gravityFilterer.FilterTransactionBatchExecutedEvent(&bind.FilterOpts{
-			Start: endSearch,
-			End:   &currentBlock,
-		}, nil, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", endSearch).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past TransactionBatchExecuted events from Ethereum")
-
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past TransactionBatchExecuted events from Ethereum")
-				return 0, err
-			} else if iterTXBatchExec == nil {
-				return 0, errors.New("no iterator returned")
+		for _, ev := range events.SendToCosmos {
+			if ev.EventNonce.Uint64() == lastEventNonce {
+				return ev.Raw.BlockNumber, nil
 			}
 		}
 
-		for iterTXBatchExec.Next() {
-			if iterTXBatchExec.Event.EventNonce.Uint64() == lastEventNonce {
-				return iterTXBatchExec.Event.Raw.BlockNumber, nil
+		for _, ev := range events.TransactionBatchExecuted {
+			if ev.EventNonce.Uint64() == lastEventNonce {
+				return ev.Raw.BlockNumber, nil
 			}
 		}
 
-		iterTXBatchExec.Close()
-
-		iterErc20Deploy, err := gravityFilterer.FilterERC20DeployedEvent(&bind.FilterOpts{
-			Start: endSearch,
-			End:   &currentBlock,
-		}, nil)
-		if err != nil {
-			p.logger.Err(err).
-				Uint64("start", endSearch).
-				Uint64("end", currentBlock).
-				Msg("failed to scan past ERC20Deployed events from Ethereum")
-
-			if !isUnknownBlockErr(err) {
-				err = errors.Wrap(err, "failed to scan past ERC20Deployed events from Ethereum")
-				return 0, err
-			} else if iterErc20Deploy == nil {
-				return 0, errors.New("no iterator returned")
+		for _, ev := range events.ERC20Deployed {
+			if ev.EventNonce.Uint64() == lastEventNonce {
+				return ev.Raw.BlockNumber, nil
 			}
 		}
 
-		for iterErc20Deploy.Next() {
-			if iterErc20Deploy.Event.EventNonce.Uint64() == lastEventNonce {
-				return iterErc20Deploy.Event.Raw.BlockNumber, nil
+		for _, ev := range events.LogicCall {
+			if ev.EventNonce.Uint64() == lastEventNonce {
+				return ev.Raw.BlockNumber, nil
 			}
 		}
 
-		iterErc20Deploy.Close()
-
 		// This reverse solves a very specific bug, we use the properties of the first valsets for edgecase
 		// handling here, but events come in chronological order, so if we don't reverse the iterator
 		// we will encounter the first validator sets first and exit early and incorrectly.
 		// Note that reversing everything won't actually get you that much of a performance gain
 		// because this only involves events within the searching block range.
-		var valsetUpdatedEvents []*wrappers.GravityValsetUpdatedEvent
-		{
-			iter, err := gravityFilterer.FilterValsetUpdatedEvent(&bind.FilterOpts{
-				Start: endSearch,
-				End:   &currentBlock,
-			}, nil)
-			if err != nil {
-				p.logger.Err(err).
-					Uint64("start", endSearch).
-					Uint64("end", currentBlock).
-					Msg("failed to scan past ValsetUpdatedEvent events from Ethereum")
-
-				if !isUnknownBlockErr(err) {
-					err = errors.Wrap(err, "failed to scan past ValsetUpdatedEvent events from Ethereum")
-					return 0, err
-				} else if iter == nil {
-					return 0, errors.New("no iterator returned")
-				}
-			}
-
-			for iter.Next() {
-				valsetUpdatedEvents = append(valsetUpdatedEvents, iter.Event)
-			}
-
-			iter.Close()
-		}
+		valsetUpdatedEvents := events.ValsetUpdated
 
 		// There's no easy way to reverse the list, so we have to do it manually.
 		for i := 0; i < len(valsetUpdatedEvents)/2; i++ {
@@ -178,8 +113,8 @@ func (p *gravityOrchestrator) GetLastCheckedBlock(
 			if commonCase || bootstrapping {
 				return valset.Raw.BlockNumber, nil
 			} else if valset.NewValsetNonce.Uint64() == 0 && lastEventNonce > 1 {
-				// If another iterator is added below the valset iterator, this panic will be triggered. Add new
-				// iterators above.
+				// If another event type is checked below the valsets, this panic will be triggered. Check new
+				// event types above.
 				p.logger.Panic().Msg("could not find the last event relayed")
 			}
 		}
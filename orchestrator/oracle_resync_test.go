@@ -21,6 +21,8 @@ import (
 	"github.com/cicizeo/loran/orchestrator/cosmos"
 	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
 	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/finality"
 )
 
 func TestGetLastCheckedBlock(t *testing.T) {
@@ -47,56 +49,14 @@ func TestGetLastCheckedBlock(t *testing.T) {
 				Number: big.NewInt(100),
 			}, nil)
 
-			// FilterSendToCosmosEvent
+		// Gravity events
 		ethProvider.EXPECT().FilterLogs(
 			gomock.Any(),
 			MatchFilterQuery(ethereum.FilterQuery{
 				FromBlock: new(big.Int).SetUint64(0),
 				ToBlock:   new(big.Int).SetUint64(100),
 				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x9e9794dbf94b0a0aa31a480f5b38550eda7f89115ac8fbf4953fa4dd219900c9")}, {}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// FilterERC20DeployedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(0),
-				ToBlock:   new(big.Int).SetUint64(100),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x82fe3a4fa49c6382d0c085746698ddbbafe6c2bf61285b19410644b5b26287c7")}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// TransactionBatchExecutedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(0),
-				ToBlock:   new(big.Int).SetUint64(100),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x02c7e81975f8edb86e2a0c038b7b86a49c744236abf0f6177ff5afc6986ab708")}, {}, {}},
-			})).
-			Return(
-				[]ethtypes.Log{},
-				nil,
-			).Times(1)
-
-		// FilterValsetUpdatedEvent
-		ethProvider.EXPECT().FilterLogs(
-			gomock.Any(),
-			MatchFilterQuery(ethereum.FilterQuery{
-				FromBlock: new(big.Int).SetUint64(0),
-				ToBlock:   new(big.Int).SetUint64(100),
-				Addresses: []ethcmn.Address{gravityAddress},
-				Topics:    [][]ethcmn.Hash{{ethcmn.HexToHash("0x76d08978c024a4bf8cbb30c67fd78fcaa1827cbc533e4e175f36d07e64ccf96a")}, {}},
+				Topics:    [][]ethcmn.Hash{eventindex.Topics()},
 			})).
 			Return(
 				[]ethtypes.Log{
@@ -159,7 +119,7 @@ func TestGetLastCheckedBlock(t *testing.T) {
 			0,
 		)
 
-		block, err := orch.GetLastCheckedBlock(context.Background(), 0)
+		block, err := orch.GetLastCheckedBlock(context.Background(), finality.FixedDepth(0))
 		assert.Nil(t, err)
 		assert.Equal(t, uint64(3), block)
 	})
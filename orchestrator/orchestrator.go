@@ -8,21 +8,52 @@ import (
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog"
+	"github.com/cicizeo/loran/orchestrator/blockrange"
 	sidechain "github.com/cicizeo/loran/orchestrator/cosmos"
 	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
 	"github.com/cicizeo/loran/orchestrator/ethereum/keystore"
 	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+	"github.com/cicizeo/loran/orchestrator/ethereum/receiptproof"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/finality"
 	"github.com/cicizeo/loran/orchestrator/relayer"
+	"github.com/cicizeo/loran/orchestrator/signjournal"
+	"github.com/cicizeo/loran/orchestrator/signpolicy"
 )
 
 type GravityOrchestrator interface {
 	Start(ctx context.Context) error
-	CheckForEvents(ctx context.Context, startingBlock, ethBlockConfirmationDelay uint64) (currentBlock uint64, err error)
-	GetLastCheckedBlock(ctx context.Context, ethBlockConfirmationDelay uint64) (uint64, error)
+	CheckForEvents(
+		ctx context.Context,
+		startingBlock uint64,
+		finalityStrategy finality.Strategy,
+	) (currentBlock uint64, err error)
+	GetLastCheckedBlock(ctx context.Context, finalityStrategy finality.Strategy) (uint64, error)
+	RewindOnReorg(ctx context.Context, lastCheckedBlock uint64) (uint64, error)
+	RewindOnNonceGap(ctx context.Context, lastCheckedBlock uint64, finalityStrategy finality.Strategy) (uint64, error)
 	EthOracleMainLoop(ctx context.Context) error
 	EthSignerMainLoop(ctx context.Context) error
 	BatchRequesterLoop(ctx context.Context) error
 	RelayerMainLoop(ctx context.Context) error
+	EventIndexerLoop(ctx context.Context) error
+
+	// SetEventIndex sets the (optional) local Gravity event index used to avoid scanning Ethereum for events that
+	// were already indexed.
+	SetEventIndex(eventindex.Index)
+
+	// SetFinalityProfiles sets the profiles used to pick the strategy deciding which Ethereum blocks are final.
+	SetFinalityProfiles(finality.Profiles)
+
+	// SetReceiptVerifier sets the (optional) verifier proving that deposit and batch execution logs are part of a
+	// block known to an independent header source before they are claimed.
+	SetReceiptVerifier(*receiptproof.Verifier)
+
+	// SetSignJournal sets the (optional) journal of the checkpoints our Ethereum key signed, used to never sign two
+	// different checkpoints for the same valset or batch.
+	SetSignJournal(signjournal.Journal)
+
+	// SetSignPolicy sets the (optional) policy engine checking batches and valsets before they are signed.
+	SetSignPolicy(*signpolicy.Engine)
 }
 
 type gravityOrchestrator struct {
@@ -40,6 +71,26 @@ type gravityOrchestrator struct {
 	batchRequesterLoopDuration time.Duration
 	ethBlocksPerLoop           uint64
 	bridgeStartHeight          uint64
+	eventIndex                 eventindex.Index
+	finalityProfiles           finality.Profiles
+	blockRange                 *blockrange.Sizer
+	receiptVerifier            *receiptproof.Verifier
+	signJournal                signjournal.Journal
+	signPolicy                 *signpolicy.Engine
+
+	// scannedRanges is only used by the Ethereum oracle loop to detect chain reorganisations.
+	scannedRanges []scannedRange
+
+	// observedNonces and highestObservedNonce are only used by the Ethereum oracle loop to detect event nonce gaps.
+	observedNonces       map[uint64]uint64
+	highestObservedNonce uint64
+
+	// reportedCheckpoints is only used by the Ethereum oracle loop to submit the evidence of a forged checkpoint once.
+	reportedCheckpoints map[ethcmn.Hash]bool
+
+	// unconfirmedSignatures is only used by the Ethereum signer loop to confirm the signatures in the signing journal
+	// once Cosmos has them.
+	unconfirmedSignatures map[signjournal.Key]bool
 
 	mtx             sync.Mutex
 	erc20DenomCache map[string]string
@@ -77,6 +128,9 @@ func NewGravityOrchestrator(
 		batchRequesterLoopDuration: batchRequesterLoopDuration,
 		ethBlocksPerLoop:           uint64(ethBlocksPerLoop),
 		bridgeStartHeight:          uint64(bridgeStartHeight),
+		finalityProfiles:           finality.DefaultProfiles(),
+		blockRange:                 blockrange.NewSizer(logger, uint64(ethBlocksPerLoop)),
+		reportedCheckpoints:        make(map[ethcmn.Hash]bool),
 	}
 
 	for _, option := range options {
@@ -85,3 +139,43 @@ func NewGravityOrchestrator(
 
 	return orch
 }
+
+func SetEventIndex(idx eventindex.Index) func(GravityOrchestrator) {
+	return func(p GravityOrchestrator) { p.SetEventIndex(idx) }
+}
+
+func (p *gravityOrchestrator) SetEventIndex(idx eventindex.Index) {
+	p.eventIndex = idx
+}
+
+func SetFinalityProfiles(profiles finality.Profiles) func(GravityOrchestrator) {
+	return func(p GravityOrchestrator) { p.SetFinalityProfiles(profiles) }
+}
+
+func (p *gravityOrchestrator) SetFinalityProfiles(profiles finality.Profiles) {
+	p.finalityProfiles = profiles
+}
+
+func SetReceiptVerifier(verifier *receiptproof.Verifier) func(GravityOrchestrator) {
+	return func(p GravityOrchestrator) { p.SetReceiptVerifier(verifier) }
+}
+
+func (p *gravityOrchestrator) SetReceiptVerifier(verifier *receiptproof.Verifier) {
+	p.receiptVerifier = verifier
+}
+
+func SetSignJournal(journal signjournal.Journal) func(GravityOrchestrator) {
+	return func(p GravityOrchestrator) { p.SetSignJournal(journal) }
+}
+
+func (p *gravityOrchestrator) SetSignJournal(journal signjournal.Journal) {
+	p.signJournal = journal
+}
+
+func SetSignPolicy(engine *signpolicy.Engine) func(GravityOrchestrator) {
+	return func(p GravityOrchestrator) { p.SetSignPolicy(engine) }
+}
+
+func (p *gravityOrchestrator) SetSignPolicy(engine *signpolicy.Engine) {
+	p.signPolicy = engine
+}
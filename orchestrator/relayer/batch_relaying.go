@@ -8,6 +8,7 @@ import (
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
 )
 
 type SubmittableBatch struct {
@@ -39,8 +40,8 @@ func (s *gravityRelayer) getBatchesAndSignatures(
 
 	for _, batch := range outTxBatches.Batches {
 
-		// We might have already sent this same batch. Skip it.
-		if s.lastSentBatchNonce >= batch.BatchNonce {
+		// We might have already sent this same batch, or a newer one of the same token. Skip it.
+		if s.isRelayActive(sentRelay{kind: relayKindBatch, nonce: batch.BatchNonce, scope: batch.TokenContract}) {
 			continue
 		}
 
@@ -97,9 +98,10 @@ func (s *gravityRelayer) getBatchesAndSignatures(
 
 // RelayBatches attempts to submit batches with valid signatures, checking the state of the Ethereum chain to ensure
 // that it is valid to submit a given batch, more specifically that the correctly signed batch has not timed out or
-// already been submitted. The goal of this function is to submit batches in chronological order of their creation.
-// This function estimates the cost of submitting a batch before submitting it to Ethereum, if it is determined that
-// the ETH cost to submit is too high the batch will be skipped and a later, more profitable, batch may be submitted.
+// already been submitted. This function estimates the cost of submitting a batch before submitting it to Ethereum, if
+// it is determined that the ETH cost to submit is too high the batch will be skipped and a later, more profitable,
+// batch may be submitted. Executing a batch makes the older batches of the same token obsolete, so out of the batches
+// left the batch selection strategy picks at most one per token to submit in each loop.
 // Keep in mind that many other relayers are making this same computation and some may have different standards for
 // their profit margin, therefore there may be a race not only to submit individual batches but also batches in
 // different orders.
@@ -121,10 +123,8 @@ func (s *gravityRelayer) RelayBatches(
 
 	for tokenContract, batches := range possibleBatches {
 
-		// Requests data from Ethereum only once per token type, this is valid because we are
-		// iterating from oldest to newest, so submitting a batch earlier in the loop won't
-		// ever invalidate submitting a batch later in the loop. Another relayer could always
-		// do that though.
+		// Requests data from Ethereum only once per token type, we only submit one batch per token type in a loop.
+		// Another relayer could always submit a batch in the meantime though.
 		latestEthereumBatch, err := s.gravityContract.GetTxBatchNonce(
 			ctx,
 			tokenContract,
@@ -135,76 +135,151 @@ func (s *gravityRelayer) RelayBatches(
 			return err
 		}
 
-		// Now we iterate through batches per token type.
-		for _, batch := range batches {
-			if batch.Batch.BatchTimeout < ethBlockHeight {
-				s.logger.Debug().
-					Uint64("batch_nonce", batch.Batch.BatchNonce).
-					Str("token_contract", batch.Batch.TokenContract).
-					Uint64("batch_timeout", batch.Batch.BatchTimeout).
-					Uint64("eth_block_height", ethBlockHeight).
-					Msg("batch has timed out and can't be submitted")
-				continue
-			}
-
-			// If the batch is newer than the latest Ethereum batch, we can submit it.
-			if batch.Batch.BatchNonce <= latestEthereumBatch.Uint64() {
-				continue
-			}
-
-			txData, err := s.gravityContract.EncodeTransactionBatch(ctx, currentValset, batch.Batch, batch.Signatures)
-			if err != nil {
-				s.logger.Err(err).Msg("failed to encode transaction batch")
-				continue
-			}
-
-			if txData == nil {
-				continue
-			}
-
-			estimatedGasCost, gasPrice, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
-			if err != nil {
-				s.logger.Err(err).Msg("failed to estimate gas cost")
-				// Here we shouldn't return, as it could be just another "nonce must be greater than the current nonce"
-				// error. We should continue to the next batch as this could make this orch retry with no good reason.
-				continue
-			}
-
-			// If the batch is not profitable, move on to the next one.
-			if !s.IsBatchProfitable(ctx, batch.Batch, estimatedGasCost, gasPrice, s.profitMultiplier) {
-				continue
-			}
-
-			// Checking in pending txs(mempool) if tx with same input is already submitted
-			// We have to check this at the last moment because any other relayer could have submitted.
-			if s.gravityContract.IsPendingTxInput(txData, s.pendingTxWait) {
-				s.logger.Debug().
-					Msg("Transaction with same batch input data is already present in mempool")
-				continue
-			}
-
-			s.logger.Info().
-				Uint64("latest_batch", batch.Batch.BatchNonce).
-				Uint64("latest_ethereum_batch", latestEthereumBatch.Uint64()).
-				Msg("we have detected a newer profitable batch; sending an update")
-
-			txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasPrice)
-			if err != nil {
-				s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to sign and submit (Gravity submitBatch) to EVM")
-				continue
-			}
-
-			s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity submitBatch)")
-
-			// Update our local tracker of the latest batch.
-			s.lastSentBatchNonce = batch.Batch.BatchNonce
+		candidates := s.getBatchCandidates(ctx, currentValset, batches, ethBlockHeight, latestEthereumBatch.Uint64())
+		if len(candidates) == 0 {
+			continue
 		}
 
+		selected := s.batchSelectionStrategy().SelectBatch(candidates)
+		if selected == nil {
+			continue
+		}
+
+		s.sendBatch(ctx, *selected, latestEthereumBatch.Uint64())
 	}
 
 	return nil
 }
 
+// getBatchCandidates returns the batches of a token that haven't timed out, are newer than the latest batch on
+// Ethereum and are profitable, along with their encoded transaction and its estimated cost.
+func (s *gravityRelayer) getBatchCandidates(
+	ctx context.Context,
+	currentValset types.Valset,
+	batches []SubmittableBatch,
+	ethBlockHeight uint64,
+	latestEthereumBatch uint64,
+) []BatchCandidate {
+	candidates := make([]BatchCandidate, 0, len(batches))
+
+	for _, batch := range batches {
+		if batch.Batch.BatchTimeout < ethBlockHeight {
+			s.logger.Debug().
+				Uint64("batch_nonce", batch.Batch.BatchNonce).
+				Str("token_contract", batch.Batch.TokenContract).
+				Uint64("batch_timeout", batch.Batch.BatchTimeout).
+				Uint64("eth_block_height", ethBlockHeight).
+				Msg("batch has timed out and can't be submitted")
+			continue
+		}
+
+		// If the batch is newer than the latest Ethereum batch, we can submit it.
+		if batch.Batch.BatchNonce <= latestEthereumBatch {
+			continue
+		}
+
+		relay := sentRelay{kind: relayKindBatch, nonce: batch.Batch.BatchNonce, scope: batch.Batch.TokenContract}
+		if s.isBackingOff(relay) {
+			s.logger.Debug().
+				Uint64("batch_nonce", batch.Batch.BatchNonce).
+				Str("token_contract", batch.Batch.TokenContract).
+				Msg("batch reverted recently, backing off")
+			continue
+		}
+
+		txData, err := s.gravityContract.EncodeTransactionBatch(ctx, currentValset, batch.Batch, batch.Signatures)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to encode transaction batch")
+			continue
+		}
+
+		if txData == nil {
+			continue
+		}
+
+		estimatedGasCost, gasPrice, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to estimate gas cost")
+			// Here we shouldn't return, as it could be just another "nonce must be greater than the current nonce"
+			// error. We should continue to the next batch as this could make this orch retry with no good reason.
+			continue
+		}
+
+		// If the batch is not profitable, move on to the next one.
+		if !s.IsBatchProfitable(ctx, batch.Batch, estimatedGasCost, gasPrice, s.profitMultiplier) {
+			continue
+		}
+
+		candidates = append(candidates, BatchCandidate{
+			SubmittableBatch: batch,
+			TxData:           txData,
+			GasCost:          estimatedGasCost,
+			GasPrice:         gasPrice,
+			TotalFees:        batchTotalFees(batch.Batch),
+		})
+	}
+
+	return candidates
+}
+
+// sendBatch submits the batch picked by the batch selection strategy, unless it is already in the mempool or would
+// revert.
+func (s *gravityRelayer) sendBatch(ctx context.Context, batch BatchCandidate, latestEthereumBatch uint64) {
+	relay := sentRelay{kind: relayKindBatch, nonce: batch.Batch.BatchNonce, scope: batch.Batch.TokenContract}
+
+	// Checking in pending txs(mempool) if tx with same input is already submitted
+	// We have to check this at the last moment because any other relayer could have submitted.
+	if s.gravityContract.IsPendingTxInput(batch.TxData, s.pendingTxWait) {
+		s.logger.Debug().
+			Msg("Transaction with same batch input data is already present in mempool")
+		return
+	}
+
+	// The state may have changed since the gas estimation, another relayer could have landed this batch.
+	if !s.simulateRelay(ctx, relay, batch.TxData, batch.GasCost) {
+		return
+	}
+
+	s.logger.Info().
+		Uint64("latest_batch", batch.Batch.BatchNonce).
+		Uint64("latest_ethereum_batch", latestEthereumBatch).
+		Msg("we have detected a newer profitable batch; sending an update")
+
+	txHash, err := s.gravityContract.SendTx(
+		ctx,
+		s.gravityContract.Address(),
+		batch.TxData,
+		batch.GasCost,
+		batch.GasPrice,
+	)
+	if err != nil {
+		s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to sign and submit (Gravity submitBatch) to EVM")
+		return
+	}
+
+	s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity submitBatch)")
+	s.trackRelay(txHash, relay)
+}
+
+// batchSelectionStrategy returns the strategy picking the batch to relay for each token, oldest first by default.
+func (s *gravityRelayer) batchSelectionStrategy() BatchSelectionStrategy {
+	if s.batchSelection == nil {
+		return oldestFirstStrategy{}
+	}
+
+	return s.batchSelection
+}
+
+// batchTotalFees returns the sum of the fees of the batch transactions, in the smallest unit of the token.
+func batchTotalFees(batch types.OutgoingTxBatch) *big.Int {
+	totalFees := big.NewInt(0)
+	for _, tx := range batch.Transactions {
+		totalFees = totalFees.Add(tx.Erc20Fee.Amount.BigInt(), totalFees)
+	}
+
+	return totalFees
+}
+
 // IsBatchProfitable gets the current prices in USD of ETH and the ERC20 token and compares the value of the estimated
 // gas cost of the transaction to the fees paid by the batch. If the estimated gas cost is greater than the batch's
 // fees, the batch is not profitable and should not be submitted.
@@ -219,6 +294,28 @@ func (s *gravityRelayer) IsBatchProfitable(
 		return true
 	}
 
+	return s.isRewardProfitable(
+		ctx,
+		relayKindBatch,
+		ethcmn.HexToAddress(batch.TokenContract),
+		batchTotalFees(batch),
+		ethGasCost,
+		gasPrice,
+		profitMultiplier,
+	)
+}
+
+// isRewardProfitable compares the value in USD of the reward paid to the relayer, in ERC20 tokens, with the estimated
+// gas cost of the transaction times the profit multiplier.
+func (s *gravityRelayer) isRewardProfitable(
+	ctx context.Context,
+	kind relaystate.Kind,
+	tokenContract ethcmn.Address,
+	reward *big.Int,
+	ethGasCost uint64,
+	gasPrice *big.Int,
+	profitMultiplier float64,
+) bool {
 	// First we get the cost of the transaction in USD
 	usdEthPrice, err := s.priceFeeder.QueryETHUSDPrice()
 	if err != nil {
@@ -231,50 +328,40 @@ func (s *gravityRelayer) IsBatchProfitable(
 	// Ethereum decimals are 18 and that's a constant.
 	gasCostInUSDDec := decimal.NewFromBigInt(totalETHcost, -18).Mul(usdEthPriceDec)
 
-	// Then we get the fees of the batch in USD
-	decimals, err := s.gravityContract.GetERC20Decimals(
-		ctx,
-		ethcmn.HexToAddress(batch.TokenContract),
-		s.gravityContract.FromAddress(),
-	)
+	// Then we get the reward in USD
+	decimals, err := s.gravityContract.GetERC20Decimals(ctx, tokenContract, s.gravityContract.FromAddress())
 	if err != nil {
-		s.logger.Err(err).Str("token_contract", batch.TokenContract).Msg("failed to get token decimals")
+		s.logger.Err(err).Str("token_contract", tokenContract.Hex()).Msg("failed to get token decimals")
 		return false
 	}
 
 	s.logger.Debug().
 		Uint8("decimals", decimals).
-		Str("token_contract", batch.TokenContract).
+		Str("token_contract", tokenContract.Hex()).
 		Msg("got token decimals")
 
-	usdTokenPrice, err := s.priceFeeder.QueryUSDPrice(ethcmn.HexToAddress(batch.TokenContract))
+	usdTokenPrice, err := s.priceFeeder.QueryUSDPrice(tokenContract)
 	if err != nil {
 		return false
 	}
 
-	// We calculate the total fee in ERC20 tokens
-	totalBatchFees := big.NewInt(0)
-	for _, tx := range batch.Transactions {
-		totalBatchFees = totalBatchFees.Add(tx.Erc20Fee.Amount.BigInt(), totalBatchFees)
-	}
-
 	usdTokenPriceDec := decimal.NewFromFloat(usdTokenPrice)
 	// Decimals (uint8) can be safely casted into int32 because the max uint8 is 255 and the max int32 is 2147483647.
-	totalFeeInUSDDec := decimal.NewFromBigInt(totalBatchFees, -int32(decimals)).Mul(usdTokenPriceDec)
+	rewardInUSDDec := decimal.NewFromBigInt(reward, -int32(decimals)).Mul(usdTokenPriceDec)
 
-	// Simplified: totalFee > (gasCost * profitMultiplier).
-	isProfitable := totalFeeInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(decimal.NewFromFloat(profitMultiplier)))
+	// Simplified: reward > (gasCost * profitMultiplier).
+	isProfitable := rewardInUSDDec.GreaterThanOrEqual(gasCostInUSDDec.Mul(decimal.NewFromFloat(profitMultiplier)))
 
 	s.logger.Debug().
-		Str("token_contract", batch.TokenContract).
+		Str("relay", string(kind)).
+		Str("token_contract", tokenContract.Hex()).
 		Float64("token_price_in_usd", usdTokenPrice).
-		Int64("total_fees", totalBatchFees.Int64()).
-		Float64("total_fee_in_usd", totalFeeInUSDDec.InexactFloat64()).
+		Str("reward", reward.String()).
+		Float64("reward_in_usd", rewardInUSDDec.InexactFloat64()).
 		Float64("gas_cost_in_usd", gasCostInUSDDec.InexactFloat64()).
 		Float64("profit_multiplier", profitMultiplier).
 		Bool("is_profitable", isProfitable).
-		Msg("checking if batch is profitable")
+		Msg("checking if relay is profitable")
 
 	return isProfitable
-
 }
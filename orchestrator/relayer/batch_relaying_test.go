@@ -24,6 +24,7 @@ import (
 	"github.com/cicizeo/loran/orchestrator/coingecko"
 	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
 	"github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
 )
 
 func TestIsBatchProfitable(t *testing.T) {
@@ -216,6 +217,55 @@ func TestGetBatchesAndSignatures(t *testing.T) {
 
 	})
 
+	t.Run("newer batch of the same token already sent", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		mockQClient.EXPECT().
+			OutgoingTxBatches(gomock.Any(), &types.QueryOutgoingTxBatchesRequest{}).
+			Return(&types.QueryOutgoingTxBatchesResponse{
+				Batches: []types.OutgoingTxBatch{
+					{BatchNonce: 10, BatchTimeout: 111111, TokenContract: "0x0"},
+					{BatchNonce: 11, BatchTimeout: 111111, TokenContract: "0x1"},
+				},
+			}, nil)
+
+		// Only the batch of the other token is checked.
+		mockQClient.EXPECT().BatchConfirms(gomock.Any(), &types.QueryBatchConfirmsRequest{
+			Nonce:           11,
+			ContractAddress: "0x1",
+		}).Return(&types.QueryBatchConfirmsResponse{}, nil)
+
+		mockGravityContract.EXPECT().
+			EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil)
+
+		relayState := newRelayState(t)
+		assert.NoError(t, relayState.Set(
+			relaystate.Key{Kind: relaystate.KindBatch, Scope: "0x0", Nonce: 12},
+			relaystate.Entry{Status: relaystate.StatusSent},
+		))
+		// A batch of another token with a higher nonce doesn't matter.
+		assert.NoError(t, relayState.Set(
+			relaystate.Key{Kind: relaystate.KindBatch, Scope: "0x1", Nonce: 13},
+			relaystate.Entry{Status: relaystate.StatusDropped},
+		))
+
+		relayer := gravityRelayer{
+			logger:            logger,
+			cosmosQueryClient: mockQClient,
+			gravityContract:   mockGravityContract,
+			relayState:        relayState,
+		}
+
+		submittableBatches, err := relayer.getBatchesAndSignatures(context.Background(), types.Valset{})
+		assert.NoError(t, err)
+		assert.Len(t, submittableBatches[ethcmn.HexToAddress("0x0")], 0)
+		assert.Len(t, submittableBatches[ethcmn.HexToAddress("0x1")], 1)
+	})
+
 	t.Run("not ready to be relayed, no error", func(t *testing.T) {
 		mockCtrl := gomock.NewController(t)
 		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
@@ -398,6 +448,8 @@ func TestRelayBatches(t *testing.T) {
 		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(99999), big.NewInt(1), nil)
 		mockGravityContract.EXPECT().IsPendingTxInput(gomock.Any(), gomock.Any()).Return(false)
 
+		mockGravityContract.EXPECT().SimulateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
 		mockGravityContract.EXPECT().SendTx(
 			gomock.Any(),
 			gravityAddress,
@@ -411,6 +463,7 @@ func TestRelayBatches(t *testing.T) {
 			cosmosQueryClient: mockQClient,
 			gravityContract:   mockGravityContract,
 			ethProvider:       ethProvider,
+			relayState:        newRelayState(t),
 		}
 
 		possibleBatches := map[ethcmn.Address][]SubmittableBatch{
@@ -427,7 +480,115 @@ func TestRelayBatches(t *testing.T) {
 
 		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
 		assert.NoError(t, err)
-		assert.Equal(t, uint64(2), relayer.lastSentBatchNonce)
+		assert.True(t, relayer.isRelayActive(sentRelay{kind: relayKindBatch, nonce: 2}))
+	})
+
+	t.Run("batch landed by another relayer, not sent", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(112),
+		}, nil)
+
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), gomock.Any(), gomock.Any()).Return(big.NewInt(1), nil)
+		mockGravityContract.EXPECT().EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{1}, nil)
+		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(99999), big.NewInt(1), nil)
+		mockGravityContract.EXPECT().IsPendingTxInput(gomock.Any(), gomock.Any()).Return(false)
+		mockGravityContract.EXPECT().SimulateTx(gomock.Any(), []byte{1}, uint64(99999)).
+			Return(&gravity.RevertError{Name: "InvalidBatchNonce", Args: []interface{}{big.NewInt(2), big.NewInt(2)}}, nil)
+
+		relayer := gravityRelayer{
+			logger:            logger,
+			cosmosQueryClient: mockQClient,
+			gravityContract:   mockGravityContract,
+			ethProvider:       ethProvider,
+			relayState:        newRelayState(t),
+		}
+
+		possibleBatches := map[ethcmn.Address][]SubmittableBatch{
+			ethcmn.HexToAddress("0x0"): {
+				{
+					Batch: types.OutgoingTxBatch{
+						BatchTimeout: 113,
+						BatchNonce:   2,
+					},
+					Signatures: []types.MsgConfirmBatch{},
+				},
+			},
+		}
+
+		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
+		assert.NoError(t, err)
+		assert.False(t, relayer.isRelayActive(sentRelay{kind: relayKindBatch, nonce: 2}))
+	})
+
+	t.Run("one batch per token, newest supersedes", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(112),
+		}, nil)
+
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+		mockGravityContract.EXPECT().GetTxBatchNonce(gomock.Any(), gomock.Any(), gomock.Any()).Return(big.NewInt(1), nil)
+		mockGravityContract.EXPECT().
+			EncodeTransactionBatch(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			DoAndReturn(func(
+				_ context.Context,
+				_ types.Valset,
+				batch types.OutgoingTxBatch,
+				_ []types.MsgConfirmBatch,
+			) ([]byte, error) {
+				return []byte{byte(batch.BatchNonce)}, nil
+			}).Times(2)
+		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(uint64(99999), big.NewInt(1), nil).Times(2)
+
+		// Only the newest batch is sent.
+		mockGravityContract.EXPECT().IsPendingTxInput([]byte{3}, gomock.Any()).Return(false)
+		mockGravityContract.EXPECT().SimulateTx(gomock.Any(), []byte{3}, uint64(99999)).Return(nil, nil)
+		mockGravityContract.EXPECT().
+			SendTx(gomock.Any(), gravityAddress, []byte{3}, uint64(99999), big.NewInt(1)).
+			Return(ethcmn.HexToHash("0x01010101"), nil)
+
+		relayer := gravityRelayer{
+			logger:          logger,
+			gravityContract: mockGravityContract,
+			ethProvider:     ethProvider,
+			relayState:      newRelayState(t),
+			batchSelection:  newestSupersedesStrategy{},
+		}
+
+		possibleBatches := map[ethcmn.Address][]SubmittableBatch{
+			ethcmn.HexToAddress("0x0"): {
+				{Batch: types.OutgoingTxBatch{BatchTimeout: 113, BatchNonce: 3}},
+				{Batch: types.OutgoingTxBatch{BatchTimeout: 113, BatchNonce: 2}},
+			},
+		}
+
+		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
+		assert.NoError(t, err)
+		assert.True(t, relayer.isRelayActive(sentRelay{kind: relayKindBatch, nonce: 3}))
 	})
 
 	t.Run("batch timeout, no error", func(t *testing.T) {
@@ -452,6 +613,7 @@ func TestRelayBatches(t *testing.T) {
 			cosmosQueryClient: mockQClient,
 			gravityContract:   mockGravityContract,
 			ethProvider:       ethProvider,
+			relayState:        newRelayState(t),
 		}
 
 		possibleBatches := map[ethcmn.Address][]SubmittableBatch{
@@ -468,6 +630,6 @@ func TestRelayBatches(t *testing.T) {
 
 		err := relayer.RelayBatches(context.Background(), types.Valset{}, possibleBatches)
 		assert.NoError(t, err)
-		assert.Equal(t, uint64(0), relayer.lastSentBatchNonce)
+		assert.False(t, relayer.isRelayActive(sentRelay{kind: relayKindBatch, nonce: 2}))
 	})
 }
@@ -0,0 +1,100 @@
+package relayer
+
+import (
+	"math/big"
+
+	"github.com/pkg/errors"
+)
+
+// Names of the built-in batch selection strategies.
+const (
+	BatchSelectionOldestFirst      = "oldest-first"
+	BatchSelectionHighestFeePerGas = "highest-fee-per-gas"
+	BatchSelectionNewestSupersedes = "newest-supersedes"
+)
+
+// BatchCandidate is a batch ready to be relayed: it can be submitted, hasn't timed out and is profitable.
+type BatchCandidate struct {
+	SubmittableBatch
+
+	TxData   []byte
+	GasCost  uint64
+	GasPrice *big.Int
+	// TotalFees is the sum of the fees of the batch transactions, in the smallest unit of the token.
+	TotalFees *big.Int
+}
+
+// BatchSelectionStrategy picks the batch to relay out of the candidates of a token. Executing a batch on Ethereum makes
+// the older batches of the same token obsolete, so relaying more than one batch per token in a loop wastes gas.
+type BatchSelectionStrategy interface {
+	// SelectBatch returns the candidate to relay, or nil to relay none. All the candidates are of the same token and
+	// there is at least one.
+	SelectBatch(candidates []BatchCandidate) *BatchCandidate
+}
+
+// NewBatchSelectionStrategy returns the built-in strategy with the given name.
+func NewBatchSelectionStrategy(name string) (BatchSelectionStrategy, error) {
+	switch name {
+	case BatchSelectionOldestFirst:
+		return oldestFirstStrategy{}, nil
+	case BatchSelectionHighestFeePerGas:
+		return highestFeePerGasStrategy{}, nil
+	case BatchSelectionNewestSupersedes:
+		return newestSupersedesStrategy{}, nil
+	default:
+		return nil, errors.Errorf(
+			"unsupported batch selection strategy %s, must be %s, %s or %s",
+			name,
+			BatchSelectionOldestFirst,
+			BatchSelectionHighestFeePerGas,
+			BatchSelectionNewestSupersedes,
+		)
+	}
+}
+
+// oldestFirstStrategy relays the batches in the order they were created, so every batch gets its turn.
+type oldestFirstStrategy struct{}
+
+func (oldestFirstStrategy) SelectBatch(candidates []BatchCandidate) *BatchCandidate {
+	selected := &candidates[0]
+	for i := range candidates {
+		if candidates[i].Batch.BatchNonce < selected.Batch.BatchNonce {
+			selected = &candidates[i]
+		}
+	}
+
+	return selected
+}
+
+// highestFeePerGasStrategy relays the batch paying the most fees for each unit of gas spent on it.
+type highestFeePerGasStrategy struct{}
+
+func (highestFeePerGasStrategy) SelectBatch(candidates []BatchCandidate) *BatchCandidate {
+	selected := &candidates[0]
+	for i := range candidates {
+		// fees / gasCost > selectedFees / selectedGasCost, without dividing.
+		fees := new(big.Int).Mul(candidates[i].TotalFees, new(big.Int).SetUint64(selected.GasCost))
+		selectedFees := new(big.Int).Mul(selected.TotalFees, new(big.Int).SetUint64(candidates[i].GasCost))
+
+		if fees.Cmp(selectedFees) > 0 {
+			selected = &candidates[i]
+		}
+	}
+
+	return selected
+}
+
+// newestSupersedesStrategy relays the newest batch only, since it makes all the older ones obsolete in a single
+// transaction.
+type newestSupersedesStrategy struct{}
+
+func (newestSupersedesStrategy) SelectBatch(candidates []BatchCandidate) *BatchCandidate {
+	selected := &candidates[0]
+	for i := range candidates {
+		if candidates[i].Batch.BatchNonce > selected.Batch.BatchNonce {
+			selected = &candidates[i]
+		}
+	}
+
+	return selected
+}
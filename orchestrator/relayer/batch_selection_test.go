@@ -0,0 +1,59 @@
+package relayer
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBatchSelectionStrategies(t *testing.T) {
+	candidate := func(nonce, gasCost uint64, fees int64) BatchCandidate {
+		return BatchCandidate{
+			SubmittableBatch: SubmittableBatch{Batch: types.OutgoingTxBatch{BatchNonce: nonce}},
+			GasCost:          gasCost,
+			TotalFees:        big.NewInt(fees),
+		}
+	}
+
+	// newest first, as returned by getBatchesAndSignatures
+	candidates := []BatchCandidate{
+		candidate(12, 300000, 300),
+		candidate(11, 100000, 200),
+		candidate(10, 200000, 100),
+	}
+
+	testCases := []struct {
+		strategy      string
+		expectedNonce uint64
+	}{
+		{BatchSelectionOldestFirst, 10},
+		{BatchSelectionHighestFeePerGas, 11},
+		{BatchSelectionNewestSupersedes, 12},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.strategy, func(t *testing.T) {
+			strategy, err := NewBatchSelectionStrategy(tc.strategy)
+			assert.NoError(t, err)
+
+			selected := strategy.SelectBatch(candidates)
+			assert.Equal(t, tc.expectedNonce, selected.Batch.BatchNonce)
+
+			// a single candidate is always selected
+			selected = strategy.SelectBatch(candidates[1:2])
+			assert.Equal(t, uint64(11), selected.Batch.BatchNonce)
+		})
+	}
+
+	t.Run("unknown strategy", func(t *testing.T) {
+		_, err := NewBatchSelectionStrategy("random")
+		assert.EqualError(
+			t,
+			err,
+			"unsupported batch selection strategy random, must be oldest-first, highest-fee-per-gas or newest-supersedes",
+		)
+	})
+}
@@ -8,6 +8,7 @@ import (
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/pkg/errors"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
 	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
 )
 
@@ -49,10 +50,21 @@ func (s *gravityRelayer) FindLatestValset(ctx context.Context) (*types.Valset, e
 		return nil, errors.New("failed to get cosmos Valset, empty response")
 	}
 
-	for currentBlock > 0 {
+	// If we have a local event index, we only need to search Ethereum for the blocks that weren't indexed yet.
+	var stopSearchBlock uint64
+	_, lastIndexedBlock, indexed, err := s.indexedRange()
+	if err != nil {
+		s.logger.Err(err).Msg("failed to get the event index range; searching Ethereum only")
+	} else if indexed && lastIndexedBlock < currentBlock {
+		stopSearchBlock = lastIndexedBlock
+	} else if indexed {
+		currentBlock = 0
+	}
+
+	for currentBlock > stopSearchBlock {
 		var endSearchBlock uint64
-		if currentBlock <= defaultBlocksToSearch {
-			endSearchBlock = 0
+		if currentBlock <= stopSearchBlock+defaultBlocksToSearch {
+			endSearchBlock = stopSearchBlock
 		} else {
 			endSearchBlock = currentBlock - defaultBlocksToSearch
 		}
@@ -86,32 +98,71 @@ func (s *gravityRelayer) FindLatestValset(ctx context.Context) (*types.Valset, e
 
 		// we take only the first event if we find any at all.
 		if len(valsetUpdatedEvents) > 0 {
-			event := valsetUpdatedEvents[0]
-			valset := &types.Valset{
-				Nonce:        event.NewValsetNonce.Uint64(),
-				Members:      make([]types.BridgeValidator, 0, len(event.Powers)),
-				RewardAmount: sdk.NewIntFromBigInt(event.RewardAmount),
-				RewardToken:  event.RewardToken.Hex(),
-			}
+			valset := ValsetFromEvent(valsetUpdatedEvents[0])
+			_ = s.checkIfValsetsDiffer(cosmosValset.Valset, valset)
+			s.setLatestValsetBlock(valset.Nonce, valsetUpdatedEvents[0].Raw.BlockNumber)
+			return valset, nil
+		}
 
-			for idx, p := range event.Powers {
-				valset.Members = append(valset.Members, types.BridgeValidator{
-					Power:           p.Uint64(),
-					EthereumAddress: event.Validators[idx].Hex(),
-				})
+		currentBlock = endSearchBlock
+	}
+
+	if s.eventIndex != nil && indexed {
+		log, err := s.eventIndex.LatestEventByTopic(eventindex.TopicValsetUpdated)
+		if err != nil && err != eventindex.ErrNotFound {
+			err = errors.Wrap(err, "failed to read the latest ValsetUpdated event from the event index")
+			return nil, err
+		} else if err == nil {
+			event, err := gravityFilterer.ParseValsetUpdatedEvent(*log)
+			if err != nil {
+				err = errors.Wrap(err, "failed to parse indexed ValsetUpdated event")
+				return nil, err
 			}
 
-			s.checkIfValsetsDiffer(cosmosValset.Valset, valset)
+			valset := ValsetFromEvent(event)
+			_ = s.checkIfValsetsDiffer(cosmosValset.Valset, valset)
+			s.setLatestValsetBlock(valset.Nonce, log.BlockNumber)
 			return valset, nil
 		}
-
-		currentBlock = endSearchBlock
 	}
 
 	return nil, ErrNotFound
 }
 
-var ErrNotFound = errors.New("not found")
+// indexedRange returns the range of blocks covered by the event index, if any.
+func (s *gravityRelayer) indexedRange() (firstBlock, lastBlock uint64, ok bool, err error) {
+	if s.eventIndex == nil {
+		return 0, 0, false, nil
+	}
+
+	return s.eventIndex.IndexedRange()
+}
+
+// ValsetFromEvent returns the valset set on the Gravity contract by a ValsetUpdatedEvent.
+func ValsetFromEvent(event *wrappers.GravityValsetUpdatedEvent) *types.Valset {
+	valset := &types.Valset{
+		Nonce:        event.NewValsetNonce.Uint64(),
+		Members:      make([]types.BridgeValidator, 0, len(event.Powers)),
+		RewardAmount: sdk.NewIntFromBigInt(event.RewardAmount),
+		RewardToken:  event.RewardToken.Hex(),
+	}
+
+	for idx, p := range event.Powers {
+		valset.Members = append(valset.Members, types.BridgeValidator{
+			Power:           p.Uint64(),
+			EthereumAddress: event.Validators[idx].Hex(),
+		})
+	}
+
+	return valset
+}
+
+var (
+	ErrNotFound = errors.New("not found")
+
+	// ErrValsetsDiffer is returned when a valset found on Ethereum doesn't match the one Cosmos has for its nonce.
+	ErrValsetsDiffer = errors.New("Ethereum and cosmos valsets differ")
+)
 
 type GravityValsetUpdatedEvents []*wrappers.GravityValsetUpdatedEvent
 
@@ -128,17 +179,18 @@ func (a GravityValsetUpdatedEvents) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
 // the loop early once a vote has enough power, if a relayer where to submit things in the reverse order
 // they could grief users of the contract into paying more in gas.
 // The other (and far worse) way a disagreement here could occur is if validators are colluding to steal
-// funds from the Gravity contract and have submitted a hijacking update. If slashing for off Cosmos chain
-// Ethereum signatures is implemented you would put that handler here.
-func (s *gravityRelayer) checkIfValsetsDiffer(cosmosValset, ethereumValset *types.Valset) {
+// funds from the Gravity contract and have submitted a hijacking update. The signatures of such an update are
+// submitted as bad signature evidence by the Ethereum oracle (see reportForgedCheckpoints), so the validators
+// behind it get slashed.
+func (s *gravityRelayer) checkIfValsetsDiffer(cosmosValset, ethereumValset *types.Valset) error {
 	if cosmosValset == nil && ethereumValset.Nonce == 0 {
 		// bootstrapping case
-		return
+		return nil
 	} else if cosmosValset == nil {
 		s.logger.Error().
 			Uint64("eth_valset_nonce", ethereumValset.Nonce).
 			Msg("cosmos does not have a valset for nonce from Ethereum chain. Possible bridge hijacking!")
-		return
+		return errors.Wrapf(ErrValsetsDiffer, "cosmos has no valset %d", ethereumValset.Nonce)
 	}
 
 	if cosmosValset.Nonce != ethereumValset.Nonce {
@@ -147,7 +199,12 @@ func (s *gravityRelayer) checkIfValsetsDiffer(cosmosValset, ethereumValset *type
 			Uint64("eth_valset_nonce", ethereumValset.Nonce).
 			Uint64("cosmos_valset_nonce", cosmosValset.Nonce).
 			Msg("cosmos does have a wrong valset nonce, differs from Ethereum chain. Possible bridge hijacking!")
-		return
+		return errors.Wrapf(
+			ErrValsetsDiffer,
+			"cosmos valset nonce %d, Ethereum valset nonce %d",
+			cosmosValset.Nonce,
+			ethereumValset.Nonce,
+		)
 	}
 
 	if len(cosmosValset.Members) != len(ethereumValset.Members) {
@@ -155,20 +212,41 @@ func (s *gravityRelayer) checkIfValsetsDiffer(cosmosValset, ethereumValset *type
 			Int("eth_valset", len(ethereumValset.Members)).
 			Int("cosmos_valset", len(cosmosValset.Members)).
 			Msg("cosmos and Ethereum Valsets have different length. Possible bridge hijacking!")
-		return
+		return errors.Wrapf(
+			ErrValsetsDiffer,
+			"valset %d has %d members on cosmos, %d on Ethereum",
+			ethereumValset.Nonce,
+			len(cosmosValset.Members),
+			len(ethereumValset.Members),
+		)
 	}
 
 	BridgeValidators(cosmosValset.Members).Sort()
 	BridgeValidators(ethereumValset.Members).Sort()
 
 	for idx, member := range cosmosValset.Members {
-		if ethereumValset.Members[idx].EthereumAddress != member.EthereumAddress {
-			s.logger.Error().Msg("valsets are different, a sorting error?")
-		}
-		if ethereumValset.Members[idx].Power != member.Power {
+		if ethereumValset.Members[idx].EthereumAddress != member.EthereumAddress ||
+			ethereumValset.Members[idx].Power != member.Power {
 			s.logger.Error().Msg("valsets are different, a sorting error?")
+			return errors.Wrapf(
+				ErrValsetsDiffer,
+				"valset %d member %d is %s with power %d on cosmos, %s with power %d on Ethereum",
+				ethereumValset.Nonce,
+				idx,
+				member.EthereumAddress,
+				member.Power,
+				ethereumValset.Members[idx].EthereumAddress,
+				ethereumValset.Members[idx].Power,
+			)
 		}
 	}
+
+	return nil
+}
+
+// CheckValset compares a valset found on Ethereum with the one Cosmos has for the same nonce.
+func (s *gravityRelayer) CheckValset(cosmosValset, ethereumValset *types.Valset) error {
+	return s.checkIfValsetsDiffer(cosmosValset, ethereumValset)
 }
 
 type BridgeValidators []types.BridgeValidator
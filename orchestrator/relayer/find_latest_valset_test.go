@@ -152,22 +152,50 @@ func TestFindLatestValset(t *testing.T) {
 }
 
 func TestCheckIfValsetsDiffer(t *testing.T) {
-	// this function doesn't return a value. Running different scenarios just to increase code coverage.
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
 
-	t.Run("ok", func(t *testing.T) {
-		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
-
-		relayer := gravityRelayer{
-			logger: logger,
-		}
+	relayer := gravityRelayer{
+		logger: logger,
+	}
 
-		relayer.checkIfValsetsDiffer(&types.Valset{}, &types.Valset{})
-		relayer.checkIfValsetsDiffer(nil, &types.Valset{})
-		relayer.checkIfValsetsDiffer(nil, &types.Valset{Nonce: 2})
-		relayer.checkIfValsetsDiffer(&types.Valset{Nonce: 12}, &types.Valset{Nonce: 11})
-		relayer.checkIfValsetsDiffer(&types.Valset{}, &types.Valset{Members: []types.BridgeValidator{{EthereumAddress: "0x0"}}})
+	t.Run("same valsets", func(t *testing.T) {
+		assert.Nil(t, relayer.checkIfValsetsDiffer(&types.Valset{}, &types.Valset{}))
+		assert.Nil(t, relayer.checkIfValsetsDiffer(nil, &types.Valset{}))
+		assert.Nil(t, relayer.checkIfValsetsDiffer(
+			&types.Valset{Nonce: 3, Members: []types.BridgeValidator{
+				{EthereumAddress: ethcmn.HexToAddress("0x1").Hex(), Power: 1},
+				{EthereumAddress: ethcmn.HexToAddress("0x2").Hex(), Power: 2},
+			}},
+			&types.Valset{Nonce: 3, Members: []types.BridgeValidator{
+				{EthereumAddress: ethcmn.HexToAddress("0x2").Hex(), Power: 2},
+				{EthereumAddress: ethcmn.HexToAddress("0x1").Hex(), Power: 1},
+			}},
+		))
 	})
 
+	t.Run("different valsets", func(t *testing.T) {
+		err := relayer.checkIfValsetsDiffer(nil, &types.Valset{Nonce: 2})
+		assert.ErrorIs(t, err, ErrValsetsDiffer)
+
+		err = relayer.checkIfValsetsDiffer(&types.Valset{Nonce: 12}, &types.Valset{Nonce: 11})
+		assert.ErrorIs(t, err, ErrValsetsDiffer)
+
+		err = relayer.checkIfValsetsDiffer(
+			&types.Valset{},
+			&types.Valset{Members: []types.BridgeValidator{{EthereumAddress: "0x0"}}},
+		)
+		assert.ErrorIs(t, err, ErrValsetsDiffer)
+
+		err = relayer.CheckValset(
+			&types.Valset{Nonce: 3, Members: []types.BridgeValidator{
+				{EthereumAddress: ethcmn.HexToAddress("0x1").Hex(), Power: 1},
+			}},
+			&types.Valset{Nonce: 3, Members: []types.BridgeValidator{
+				{EthereumAddress: ethcmn.HexToAddress("0x1").Hex(), Power: 2},
+			}},
+		)
+		assert.ErrorIs(t, err, ErrValsetsDiffer)
+	})
 }
 
 func TestBridgeValidator(t *testing.T) {
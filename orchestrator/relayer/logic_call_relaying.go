@@ -0,0 +1,189 @@
+package relayer
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+)
+
+type SubmittableLogicCall struct {
+	Call       types.OutgoingLogicCall
+	Signatures []types.MsgConfirmLogicCall
+}
+
+// getLogicCallsAndSignatures retrieves the pending outgoing logic calls from the Cosmos module and then iterates
+// through the signatures for each call, determining if they are ready to submit. Just like batches, a logic call may
+// not have valid signatures yet because not enough validators have signed it or because the signatures don't reflect
+// the current validator set on Ethereum. In both cases the call is skipped and will be checked again on the next loop.
+func (s *gravityRelayer) getLogicCallsAndSignatures(
+	ctx context.Context,
+	currentValset types.Valset,
+) ([]SubmittableLogicCall, error) {
+	possibleCalls := []SubmittableLogicCall{}
+
+	outLogicCalls, err := s.cosmosQueryClient.OutgoingLogicCalls(ctx, &types.QueryOutgoingLogicCallsRequest{})
+
+	if err != nil {
+		s.logger.Err(err).Msg("failed to get latest logic calls")
+		return possibleCalls, err
+	} else if outLogicCalls == nil {
+		s.logger.Info().Msg("no outgoing logic calls found")
+		return possibleCalls, nil
+	}
+
+	for _, call := range outLogicCalls.Calls {
+
+		relay := sentRelay{
+			kind:  relayKindLogicCall,
+			nonce: call.InvalidationNonce,
+			scope: ethcmn.Bytes2Hex(call.InvalidationId),
+		}
+
+		// We might have already sent this same logic call, or a newer one of the same invalidation ID. Skip it.
+		if s.isRelayActive(relay) {
+			continue
+		}
+
+		logicCallConfirms, err := s.cosmosQueryClient.LogicConfirms(ctx, &types.QueryLogicConfirmsRequest{
+			InvalidationId:    call.InvalidationId,
+			InvalidationNonce: call.InvalidationNonce,
+		})
+
+		if err != nil || logicCallConfirms == nil {
+			// If we can't get the signatures for a logic call we will continue to the next one.
+			// Use Error() instead of Err() because the latter will print on info level instead of error if err == nil.
+			s.logger.Error().
+				AnErr("error", err).
+				Str("invalidation_id", ethcmn.Bytes2Hex(call.InvalidationId)).
+				Uint64("invalidation_nonce", call.InvalidationNonce).
+				Msg("failed to get logic call's signatures")
+			continue
+		}
+
+		// This checks that the signatures for the logic call are actually possible to submit to the chain.
+		// We only need to know if the signatures are good, we won't use the other returned value.
+		txData, err := s.gravityContract.EncodeLogicCall(ctx, currentValset, call, logicCallConfirms.Confirms)
+
+		if err != nil || txData == nil {
+			// this logic call is not ready to be relayed
+			s.logger.
+				Debug().
+				AnErr("err", err).
+				Str("invalidation_id", ethcmn.Bytes2Hex(call.InvalidationId)).
+				Uint64("invalidation_nonce", call.InvalidationNonce).
+				Msg("logic call can't be submitted yet, waiting for more signatures")
+
+			// Do not return an error here, we want to continue to the next logic call
+			continue
+		}
+
+		possibleCalls = append(possibleCalls, SubmittableLogicCall{Call: call, Signatures: logicCallConfirms.Confirms})
+	}
+
+	// Order logic calls by invalidation nonce ASC, so calls sharing an invalidation ID are submitted in order.
+	sort.SliceStable(possibleCalls, func(i, j int) bool {
+		return possibleCalls[i].Call.InvalidationNonce < possibleCalls[j].Call.InvalidationNonce
+	})
+
+	return possibleCalls, nil
+}
+
+// RelayLogicCalls attempts to submit logic calls with valid signatures, checking the state of the Ethereum chain to
+// ensure that it is valid to submit a given call, more specifically that the correctly signed call has not timed out
+// or been invalidated by a call with the same invalidation ID and an equal or higher invalidation nonce.
+func (s *gravityRelayer) RelayLogicCalls(
+	ctx context.Context,
+	currentValset types.Valset,
+	possibleCalls []SubmittableLogicCall,
+) error {
+	// first get current block height to check for any timeouts
+	lastEthereumHeader, err := s.ethProvider.HeaderByNumber(ctx, nil)
+	if err != nil {
+		s.logger.Err(err).Msg("failed to get last ethereum header")
+		return err
+	}
+
+	ethBlockHeight := lastEthereumHeader.Number.Uint64()
+
+	for _, call := range possibleCalls {
+		invalidationID := ethcmn.Bytes2Hex(call.Call.InvalidationId)
+
+		// The call can land in the next block at the earliest, and the contract wants it before the timeout block.
+		if call.Call.Timeout <= ethBlockHeight+1 {
+			s.logger.Debug().
+				Str("invalidation_id", invalidationID).
+				Uint64("invalidation_nonce", call.Call.InvalidationNonce).
+				Uint64("logic_call_timeout", call.Call.Timeout).
+				Uint64("eth_block_height", ethBlockHeight).
+				Msg("logic call has timed out and can't be submitted")
+			continue
+		}
+
+		latestEthereumNonce, err := s.gravityContract.GetLogicCallNonce(
+			ctx,
+			call.Call.InvalidationId,
+			s.gravityContract.FromAddress(),
+		)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to get latest Ethereum logic call nonce")
+			return err
+		}
+
+		// If the call is newer than the latest invalidation nonce on Ethereum, we can submit it.
+		if call.Call.InvalidationNonce <= latestEthereumNonce.Uint64() {
+			continue
+		}
+
+		relay := sentRelay{kind: relayKindLogicCall, nonce: call.Call.InvalidationNonce, scope: invalidationID}
+		if s.isBackingOff(relay) {
+			s.logger.Debug().
+				Str("invalidation_id", invalidationID).
+				Uint64("invalidation_nonce", call.Call.InvalidationNonce).
+				Msg("logic call reverted recently, backing off")
+			continue
+		}
+
+		txData, err := s.gravityContract.EncodeLogicCall(ctx, currentValset, call.Call, call.Signatures)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to encode logic call")
+			continue
+		}
+
+		if txData == nil {
+			continue
+		}
+
+		estimatedGasCost, gasPrice, err := s.gravityContract.EstimateGas(ctx, s.gravityContract.Address(), txData)
+		if err != nil {
+			s.logger.Err(err).Msg("failed to estimate gas cost")
+			continue
+		}
+
+		// Checking in pending txs(mempool) if tx with same input is already submitted
+		// We have to check this at the last moment because any other relayer could have submitted.
+		if s.gravityContract.IsPendingTxInput(txData, s.pendingTxWait) {
+			s.logger.Debug().
+				Msg("Transaction with same logic call input data is already present in mempool")
+			continue
+		}
+
+		s.logger.Info().
+			Str("invalidation_id", invalidationID).
+			Uint64("invalidation_nonce", call.Call.InvalidationNonce).
+			Uint64("latest_ethereum_invalidation_nonce", latestEthereumNonce.Uint64()).
+			Msg("we have detected a newer logic call; sending an update")
+
+		txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasPrice)
+		if err != nil {
+			s.logger.Err(err).Str("tx_hash", txHash.Hex()).Msg("failed to sign and submit (Gravity submitLogicCall) to EVM")
+			continue
+		}
+
+		s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity submitLogicCall)")
+		s.trackRelay(txHash, relay)
+	}
+
+	return nil
+}
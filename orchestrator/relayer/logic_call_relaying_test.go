@@ -0,0 +1,243 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	gravityMocks "github.com/cicizeo/loran/mocks/gravity"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
+)
+
+func TestGetLogicCallsAndSignatures(t *testing.T) {
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		mockQClient.EXPECT().
+			OutgoingLogicCalls(gomock.Any(), &types.QueryOutgoingLogicCallsRequest{}).
+			Return(&types.QueryOutgoingLogicCallsResponse{
+				Calls: []types.OutgoingLogicCall{
+					{
+						InvalidationId:    []byte{0xa},
+						InvalidationNonce: 3,
+						Timeout:           111111,
+					},
+					{
+						InvalidationId:    []byte{0xa},
+						InvalidationNonce: 2,
+						Timeout:           111111,
+					},
+				},
+			}, nil)
+
+		mockQClient.EXPECT().LogicConfirms(gomock.Any(), gomock.Any()).Return(&types.QueryLogicConfirmsResponse{
+			Confirms: []types.MsgConfirmLogicCall{
+				{
+					EthSigner: "0x5",
+					Signature: "0x111",
+				},
+			},
+		}, nil).Times(2)
+
+		mockGravityContract.EXPECT().
+			EncodeLogicCall(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return([]byte{0x1}, nil).Times(2)
+
+		relayer := gravityRelayer{
+			logger:            logger,
+			cosmosQueryClient: mockQClient,
+			gravityContract:   mockGravityContract,
+		}
+
+		submittableCalls, err := relayer.getLogicCallsAndSignatures(context.Background(), types.Valset{})
+		assert.NoError(t, err)
+		assert.Len(t, submittableCalls, 2)
+		assert.Equal(t, uint64(2), submittableCalls[0].Call.InvalidationNonce)
+	})
+
+	t.Run("already sent and not ready to be relayed, no error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		mockQClient.EXPECT().
+			OutgoingLogicCalls(gomock.Any(), &types.QueryOutgoingLogicCallsRequest{}).
+			Return(&types.QueryOutgoingLogicCallsResponse{
+				Calls: []types.OutgoingLogicCall{
+					{
+						InvalidationId:    []byte{0xa},
+						InvalidationNonce: 2,
+					},
+					{
+						InvalidationId:    []byte{0xb},
+						InvalidationNonce: 1,
+					},
+				},
+			}, nil)
+
+		mockQClient.EXPECT().LogicConfirms(gomock.Any(), &types.QueryLogicConfirmsRequest{
+			InvalidationId:    []byte{0xb},
+			InvalidationNonce: 1,
+		}).Return(&types.QueryLogicConfirmsResponse{}, nil)
+
+		mockGravityContract.EXPECT().
+			EncodeLogicCall(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+			Return(nil, nil)
+
+		relayState := newRelayState(t)
+		assert.NoError(t, relayState.Set(
+			relaystate.Key{Kind: relaystate.KindLogicCall, Scope: "0a", Nonce: 2},
+			relaystate.Entry{Status: relaystate.StatusSent},
+		))
+
+		relayer := gravityRelayer{
+			logger:            logger,
+			cosmosQueryClient: mockQClient,
+			gravityContract:   mockGravityContract,
+			relayState:        relayState,
+		}
+
+		submittableCalls, err := relayer.getLogicCallsAndSignatures(context.Background(), types.Valset{})
+		assert.NoError(t, err)
+		assert.Len(t, submittableCalls, 0)
+	})
+
+	t.Run("error getting logic calls", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+
+		mockQClient.EXPECT().
+			OutgoingLogicCalls(gomock.Any(), &types.QueryOutgoingLogicCallsRequest{}).
+			Return(nil, errors.New("some error"))
+
+		relayer := gravityRelayer{
+			logger:            logger,
+			cosmosQueryClient: mockQClient,
+		}
+
+		_, err := relayer.getLogicCallsAndSignatures(context.Background(), types.Valset{})
+		assert.EqualError(t, err, "some error")
+	})
+}
+
+func TestRelayLogicCalls(t *testing.T) {
+
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(112),
+		}, nil)
+
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().GetLogicCallNonce(gomock.Any(), []byte{0xa}, fromAddress).Return(big.NewInt(1), nil)
+		mockGravityContract.EXPECT().EncodeLogicCall(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return([]byte{0x1}, nil)
+		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+		mockGravityContract.EXPECT().EstimateGas(gomock.Any(), gomock.Any(), gomock.Any()).Return(uint64(99999), big.NewInt(1), nil)
+		mockGravityContract.EXPECT().IsPendingTxInput(gomock.Any(), gomock.Any()).Return(false)
+
+		mockGravityContract.EXPECT().SendTx(
+			gomock.Any(),
+			gravityAddress,
+			[]byte{0x1},
+			uint64(99999),
+			big.NewInt(1),
+		).Return(ethcmn.HexToHash("0x01010101"), nil)
+
+		relayer := gravityRelayer{
+			logger:          logger,
+			gravityContract: mockGravityContract,
+			ethProvider:     ethProvider,
+			relayState:      newRelayState(t),
+		}
+
+		possibleCalls := []SubmittableLogicCall{
+			{
+				Call: types.OutgoingLogicCall{
+					InvalidationId:    []byte{0xa},
+					InvalidationNonce: 2,
+					Timeout:           114,
+				},
+				Signatures: []types.MsgConfirmLogicCall{},
+			},
+		}
+
+		err := relayer.RelayLogicCalls(context.Background(), types.Valset{}, possibleCalls)
+		assert.NoError(t, err)
+		assert.True(t, relayer.isRelayActive(sentRelay{kind: relayKindLogicCall, nonce: 2, scope: "0a"}))
+	})
+
+	t.Run("timed out and invalidated, no error", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+
+		fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).Return(&ethtypes.Header{
+			Number: big.NewInt(112),
+		}, nil)
+
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().GetLogicCallNonce(gomock.Any(), []byte{0xb}, fromAddress).Return(big.NewInt(2), nil)
+
+		relayer := gravityRelayer{
+			logger:          logger,
+			gravityContract: mockGravityContract,
+			ethProvider:     ethProvider,
+			relayState:      newRelayState(t),
+		}
+
+		possibleCalls := []SubmittableLogicCall{
+			{
+				Call: types.OutgoingLogicCall{
+					InvalidationId:    []byte{0xa},
+					InvalidationNonce: 2,
+					Timeout:           100,
+				},
+			},
+			{
+				Call: types.OutgoingLogicCall{
+					InvalidationId:    []byte{0xb},
+					InvalidationNonce: 2,
+					Timeout:           114,
+				},
+			},
+			{
+				// would only land in block 113 or later
+				Call: types.OutgoingLogicCall{
+					InvalidationId:    []byte{0xc},
+					InvalidationNonce: 3,
+					Timeout:           113,
+				},
+			},
+		}
+
+		err := relayer.RelayLogicCalls(context.Background(), types.Valset{}, possibleCalls)
+		assert.NoError(t, err)
+		sent, err := relayer.relayState.Sent()
+		assert.NoError(t, err)
+		assert.Len(t, sent, 0)
+	})
+}
@@ -21,6 +21,15 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 		logger.Info().Msg("batch relay enabled; starting to relay batches to Ethereum")
 	}
 
+	if s.logicCallRelayEnabled {
+		logger.Info().Msg("logic call relay enabled; starting to relay logic calls to Ethereum")
+	}
+
+	// Follow again the relays sent before a restart.
+	if err := s.restoreSentRelays(); err != nil {
+		return err
+	}
+
 	return loops.RunLoop(ctx, s.logger, s.loopDuration, func() error {
 		var (
 			currentValset *types.Valset
@@ -42,6 +51,9 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 			s.logger.Panic().Err(err).Msg("exhausted retries to get latest valset")
 		}
 
+		// Roll back the relays that didn't land before relaying again.
+		s.checkSentRelays(ctx)
+
 		var pg loops.ParanoidGroup
 		if s.valsetRelayEnabled {
 			pg.Go(func() error {
@@ -69,6 +81,22 @@ func (s *gravityRelayer) Start(ctx context.Context) error {
 			})
 		}
 
+		if s.logicCallRelayEnabled {
+			pg.Go(func() error {
+				return retry.Do(func() error {
+
+					possibleCalls, err := s.getLogicCallsAndSignatures(ctx, *currentValset)
+					if err != nil {
+						return err
+					}
+
+					return s.RelayLogicCalls(ctx, *currentValset, possibleCalls)
+				}, retry.Context(ctx), retry.OnRetry(func(n uint, err error) {
+					logger.Err(err).Uint("retry", n).Msg("failed to relay logic calls; retrying...")
+				}))
+			})
+		}
+
 		if pg.Initialized() {
 			if err := pg.Wait(); err != nil {
 				logger.Err(err).Msg("main relay loop failed; exiting...")
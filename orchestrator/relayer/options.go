@@ -1,6 +1,12 @@
 package relayer
 
-import "github.com/cicizeo/loran/orchestrator/coingecko"
+import (
+	"time"
+
+	"github.com/cicizeo/loran/orchestrator/coingecko"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
+)
 
 func SetPriceFeeder(pf *coingecko.PriceFeed) func(GravityRelayer) {
 	return func(s GravityRelayer) { s.SetPriceFeeder(pf) }
@@ -9,3 +15,36 @@ func SetPriceFeeder(pf *coingecko.PriceFeed) func(GravityRelayer) {
 func (s *gravityRelayer) SetPriceFeeder(pf *coingecko.PriceFeed) {
 	s.priceFeeder = pf
 }
+
+func SetEventIndex(idx eventindex.Index) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetEventIndex(idx) }
+}
+
+func (s *gravityRelayer) SetEventIndex(idx eventindex.Index) {
+	s.eventIndex = idx
+}
+
+func SetRelayState(store relaystate.Store) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetRelayState(store) }
+}
+
+func (s *gravityRelayer) SetRelayState(store relaystate.Store) {
+	s.relayState = store
+}
+
+func SetBatchSelectionStrategy(strategy BatchSelectionStrategy) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetBatchSelectionStrategy(strategy) }
+}
+
+func (s *gravityRelayer) SetBatchSelectionStrategy(strategy BatchSelectionStrategy) {
+	s.batchSelection = strategy
+}
+
+func SetValsetProfitability(profitMultiplier float64, maxAge time.Duration) func(GravityRelayer) {
+	return func(s GravityRelayer) { s.SetValsetProfitability(profitMultiplier, maxAge) }
+}
+
+func (s *gravityRelayer) SetValsetProfitability(profitMultiplier float64, maxAge time.Duration) {
+	s.valsetProfitMultiplier = profitMultiplier
+	s.valsetMaxAge = maxAge
+}
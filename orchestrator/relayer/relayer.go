@@ -2,13 +2,16 @@ package relayer
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/rs/zerolog"
 	"github.com/cicizeo/loran/orchestrator/coingecko"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
 	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
 	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
 
 	gravitytypes "github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
 )
@@ -18,6 +21,10 @@ type GravityRelayer interface {
 
 	FindLatestValset(ctx context.Context) (*gravitytypes.Valset, error)
 
+	// CheckValset compares a valset found on Ethereum with the one Cosmos has for the same nonce. It returns
+	// ErrValsetsDiffer if they differ, which may mean the bridge was hijacked.
+	CheckValset(cosmosValset, ethereumValset *gravitytypes.Valset) error
+
 	RelayBatches(
 		ctx context.Context,
 		currentValset gravitytypes.Valset,
@@ -26,27 +33,64 @@ type GravityRelayer interface {
 
 	RelayValsets(ctx context.Context, currentValset gravitytypes.Valset) error
 
+	RelayLogicCalls(
+		ctx context.Context,
+		currentValset gravitytypes.Valset,
+		possibleCalls []SubmittableLogicCall,
+	) error
+
 	// SetPriceFeeder sets the (optional) price feeder used when performing profitable
 	// batch calculations.
 	SetPriceFeeder(*coingecko.PriceFeed)
+
+	// SetEventIndex sets the (optional) local Gravity event index used when looking for the latest valset.
+	SetEventIndex(eventindex.Index)
+
+	// SetBatchSelectionStrategy sets the strategy picking the batch to relay for each token. Without it the oldest
+	// batch is relayed first.
+	SetBatchSelectionStrategy(BatchSelectionStrategy)
+
+	// SetValsetProfitability makes the relayer only relay the valset updates whose reward is worth profitMultiplier
+	// times their gas cost, unless the valset on Ethereum is older than maxAge.
+	SetValsetProfitability(profitMultiplier float64, maxAge time.Duration)
+
+	// SetRelayState sets the store of the relays sent and their outcome. Without it every relay found is sent.
+	SetRelayState(relaystate.Store)
 }
 
 type gravityRelayer struct {
-	logger             zerolog.Logger
-	cosmosQueryClient  gravitytypes.QueryClient
-	gravityContract    gravity.Contract
-	ethProvider        provider.EVMProvider
-	valsetRelayEnabled bool
-	batchRelayEnabled  bool
-	loopDuration       time.Duration
-	priceFeeder        *coingecko.PriceFeed
-	pendingTxWait      time.Duration
-	profitMultiplier   float64
-
-	// Store locally the last tx this validator made to avoid sending duplicates
-	// or invalid txs.
-	lastSentBatchNonce  uint64
-	lastSentValsetNonce uint64
+	logger                zerolog.Logger
+	cosmosQueryClient     gravitytypes.QueryClient
+	gravityContract       gravity.Contract
+	ethProvider           provider.EVMProvider
+	valsetRelayEnabled    bool
+	batchRelayEnabled     bool
+	logicCallRelayEnabled bool
+	loopDuration          time.Duration
+	priceFeeder           *coingecko.PriceFeed
+	pendingTxWait         time.Duration
+	profitMultiplier      float64
+	eventIndex            eventindex.Index
+	batchSelection        BatchSelectionStrategy
+
+	// valsetProfitMultiplier is applied to the gas cost of a valset update compared to its reward, zero relays every
+	// valset. Past valsetMaxAge, the valset on Ethereum is updated no matter the reward.
+	valsetProfitMultiplier float64
+	valsetMaxAge           time.Duration
+
+	// relayState remembers the relays this validator sent and their outcome, to avoid sending duplicates or invalid
+	// txs, even across restarts.
+	relayState relaystate.Store
+
+	// sentRelays holds the relayed transactions not mined yet, and relayBackoffs the relays that reverted.
+	sentRelaysMux sync.Mutex
+	sentRelays    map[ethcmn.Hash]sentRelay
+	relayBackoffs map[string]relayBackoff
+
+	// latestValsetBlock is the Ethereum block the latest valset found by FindLatestValset was set at.
+	latestValsetMux   sync.Mutex
+	latestValsetNonce uint64
+	latestValsetBlock uint64
 }
 
 func NewGravityRelayer(
@@ -55,21 +99,23 @@ func NewGravityRelayer(
 	gravityContract gravity.Contract,
 	valsetRelayEnabled bool,
 	batchRelayEnabled bool,
+	logicCallRelayEnabled bool,
 	loopDuration time.Duration,
 	pendingTxWait time.Duration,
 	profitMultiplier float64,
 	options ...func(GravityRelayer),
 ) GravityRelayer {
 	relayer := &gravityRelayer{
-		logger:             logger.With().Str("module", "gravity_relayer").Logger(),
-		cosmosQueryClient:  gravityQueryClient,
-		gravityContract:    gravityContract,
-		ethProvider:        gravityContract.Provider(),
-		valsetRelayEnabled: valsetRelayEnabled,
-		batchRelayEnabled:  batchRelayEnabled,
-		loopDuration:       loopDuration,
-		pendingTxWait:      pendingTxWait,
-		profitMultiplier:   profitMultiplier,
+		logger:                logger.With().Str("module", "gravity_relayer").Logger(),
+		cosmosQueryClient:     gravityQueryClient,
+		gravityContract:       gravityContract,
+		ethProvider:           gravityContract.Provider(),
+		valsetRelayEnabled:    valsetRelayEnabled,
+		batchRelayEnabled:     batchRelayEnabled,
+		logicCallRelayEnabled: logicCallRelayEnabled,
+		loopDuration:          loopDuration,
+		pendingTxWait:         pendingTxWait,
+		profitMultiplier:      profitMultiplier,
 	}
 
 	for _, option := range options {
@@ -27,6 +27,7 @@ func TestNewGravityRelayer(t *testing.T) {
 		mockGravityContract,
 		true,
 		true,
+		true,
 		time.Minute,
 		time.Minute,
 		1.0,
@@ -0,0 +1,292 @@
+package relayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
+	"github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
+)
+
+// maxRelayBackoff caps the time a relay that keeps reverting is held back for.
+const maxRelayBackoff = time.Hour
+
+// Kinds of relayed transactions.
+const (
+	relayKindValset    = relaystate.KindValset
+	relayKindBatch     = relaystate.KindBatch
+	relayKindLogicCall = relaystate.KindLogicCall
+)
+
+// sentRelay is a relayed transaction followed until it is mined.
+type sentRelay struct {
+	kind  relaystate.Kind
+	nonce uint64
+	// scope is the token contract of a batch or the invalidation ID of a logic call, empty for a valset.
+	scope string
+}
+
+func (r sentRelay) stateKey() relaystate.Key {
+	return relaystate.Key{Kind: r.kind, Scope: r.scope, Nonce: r.nonce}
+}
+
+func (r sentRelay) key() string {
+	return r.stateKey().String()
+}
+
+// relayBackoff holds a relay back after it reverted.
+type relayBackoff struct {
+	failures   int
+	retryAfter time.Time
+}
+
+// isRelayActive returns true if we already sent this relay, or a newer one of the same scope, and it is still on its
+// way or was executed. Either way, sending it again would be a waste.
+func (s *gravityRelayer) isRelayActive(relay sentRelay) bool {
+	if s.relayState == nil {
+		return false
+	}
+
+	latestKey, latestEntry, ok, err := s.relayState.Latest(relay.kind, relay.scope)
+	if err != nil {
+		s.logger.Err(err).Str("relay", string(relay.kind)).Msg("failed to get the relay state")
+		return false
+	}
+
+	return ok && latestKey.Nonce >= relay.nonce && latestEntry.Active()
+}
+
+// setRelayStatus records where the relay stands. Failing to record it only means it may be sent again.
+func (s *gravityRelayer) setRelayStatus(relay sentRelay, status relaystate.Status, txHash ethcmn.Hash) {
+	if s.relayState == nil {
+		return
+	}
+
+	entry := relaystate.Entry{Status: status, TxHash: txHash, UpdatedAt: time.Now()}
+	if err := s.relayState.Set(relay.stateKey(), entry); err != nil {
+		s.logger.Err(err).
+			Str("relay", string(relay.kind)).
+			Uint64("nonce", relay.nonce).
+			Str("status", string(status)).
+			Msg("failed to record the relay state")
+	}
+}
+
+// restoreSentRelays follows again the relays sent before a restart whose outcome is not known yet.
+func (s *gravityRelayer) restoreSentRelays() error {
+	if s.relayState == nil {
+		return nil
+	}
+
+	sent, err := s.relayState.Sent()
+	if err != nil {
+		return errors.Wrap(err, "failed to get the sent relays")
+	}
+
+	s.sentRelaysMux.Lock()
+	defer s.sentRelaysMux.Unlock()
+
+	if s.sentRelays == nil {
+		s.sentRelays = map[ethcmn.Hash]sentRelay{}
+	}
+
+	for key, entry := range sent {
+		s.sentRelays[entry.TxHash] = sentRelay{kind: key.Kind, nonce: key.Nonce, scope: key.Scope}
+	}
+
+	return nil
+}
+
+// trackRelay follows a relayed transaction until checkSentRelays finds its outcome.
+func (s *gravityRelayer) trackRelay(txHash ethcmn.Hash, relay sentRelay) {
+	s.setRelayStatus(relay, relaystate.StatusSent, txHash)
+
+	s.sentRelaysMux.Lock()
+	defer s.sentRelaysMux.Unlock()
+
+	if s.sentRelays == nil {
+		s.sentRelays = map[ethcmn.Hash]sentRelay{}
+	}
+
+	s.sentRelays[txHash] = relay
+}
+
+// isBackingOff returns true if the relay reverted recently and shouldn't be sent again yet.
+func (s *gravityRelayer) isBackingOff(relay sentRelay) bool {
+	s.sentRelaysMux.Lock()
+	defer s.sentRelaysMux.Unlock()
+
+	backoff, ok := s.relayBackoffs[relay.key()]
+	if !ok {
+		return false
+	}
+
+	return time.Now().Before(backoff.retryAfter)
+}
+
+// checkSentRelays looks up the outcome of the relayed transactions. A relay is only considered delivered once its
+// transaction succeeded; a dropped relay may be sent again right away, and a reverted one after a backoff doubling with
+// every revert.
+func (s *gravityRelayer) checkSentRelays(ctx context.Context) {
+	s.sentRelaysMux.Lock()
+	sentRelays := make(map[ethcmn.Hash]sentRelay, len(s.sentRelays))
+	for txHash, relay := range s.sentRelays {
+		sentRelays[txHash] = relay
+	}
+	s.sentRelaysMux.Unlock()
+
+	for txHash, relay := range sentRelays {
+		logger := s.logger.With().
+			Str("tx_hash", txHash.Hex()).
+			Str("relay", string(relay.kind)).
+			Uint64("nonce", relay.nonce).
+			Logger()
+
+		outcome, err := s.txOutcome(ctx, txHash)
+		if errors.Is(err, committer.ErrTxPending) {
+			continue
+		} else if err != nil && !errors.Is(err, committer.ErrTxUnknown) {
+			logger.Err(err).Msg("failed to get the outcome of the relayed transaction")
+			continue
+		}
+
+		switch {
+		case errors.Is(err, committer.ErrTxUnknown):
+			logger.Warn().Msg("relayed transaction is unknown, considering it dropped")
+			s.relayFailed(relay, relaystate.StatusDropped, txHash)
+
+		case outcome.Status == committer.TxStatusSucceeded:
+			logger.Info().Str("mined_tx_hash", outcome.TxHash.Hex()).Msg("relayed transaction succeeded")
+			s.relaySucceeded(relay, outcome)
+
+		case outcome.Status == committer.TxStatusReverted:
+			revertErr, err := s.gravityContract.RevertReason(ctx, outcome)
+			switch {
+			case err != nil:
+				logger.Err(err).Msg("relayed transaction reverted, failed to find the reason")
+			case revertErr == nil:
+				logger.Error().Msg("relayed transaction reverted, likely out of gas")
+			default:
+				logger.Error().Str("reason", revertErr.Error()).Msg("relayed transaction reverted")
+			}
+
+			s.relayFailed(relay, relaystate.StatusReverted, outcome.TxHash)
+
+		default:
+			logger.Warn().Msg("relayed transaction dropped, its nonce was used by another transaction")
+			s.relayFailed(relay, relaystate.StatusDropped, outcome.TxHash)
+		}
+
+		s.sentRelaysMux.Lock()
+		delete(s.sentRelays, txHash)
+		s.sentRelaysMux.Unlock()
+	}
+}
+
+// txOutcome returns the outcome of a relayed transaction. The committer forgets about the transactions it sent before
+// a restart, so for those the outcome is looked up on chain, without knowing about their replacements.
+func (s *gravityRelayer) txOutcome(ctx context.Context, txHash ethcmn.Hash) (*committer.TxOutcome, error) {
+	outcome, err := s.gravityContract.TxOutcome(txHash)
+	if !errors.Is(err, committer.ErrTxUnknown) || s.ethProvider == nil {
+		return outcome, err
+	}
+
+	tx, isPending, err := s.ethProvider.TransactionByHash(ctx, txHash)
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, committer.ErrTxUnknown
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction")
+	} else if isPending {
+		return nil, committer.ErrTxPending
+	}
+
+	receipt, err := s.ethProvider.TransactionReceipt(ctx, txHash)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get transaction receipt")
+	}
+
+	outcome = &committer.TxOutcome{
+		Nonce:   tx.Nonce(),
+		Status:  committer.TxStatusSucceeded,
+		TxHash:  txHash,
+		Receipt: receipt,
+		TxData:  tx.Data(),
+		GasCost: tx.Gas(),
+	}
+	if tx.To() != nil {
+		outcome.Recipient = *tx.To()
+	}
+	if receipt.Status == ethtypes.ReceiptStatusFailed {
+		outcome.Status = committer.TxStatusReverted
+	}
+
+	return outcome, nil
+}
+
+// relaySucceeded marks the relay as executed if the receipt of its transaction holds the event the Gravity contract
+// emits for it. A successful transaction without the event is kept as mined, which still stops it from being sent
+// again.
+func (s *gravityRelayer) relaySucceeded(relay sentRelay, outcome *committer.TxOutcome) {
+	gravityAddress := s.gravityContract.Address()
+
+	var executed bool
+	switch relay.kind {
+	case relayKindValset:
+		executed = gravity.ValsetUpdated(outcome.Receipt, gravityAddress, relay.nonce)
+	case relayKindBatch:
+		executed = gravity.BatchExecuted(outcome.Receipt, gravityAddress, ethcmn.HexToAddress(relay.scope), relay.nonce)
+	case relayKindLogicCall:
+		executed = gravity.LogicCallExecuted(outcome.Receipt, gravityAddress, ethcmn.Hex2Bytes(relay.scope), relay.nonce)
+	}
+
+	if executed {
+		s.setRelayStatus(relay, relaystate.StatusExecuted, outcome.TxHash)
+	} else {
+		s.logger.Warn().
+			Str("tx_hash", outcome.TxHash.Hex()).
+			Str("relay", string(relay.kind)).
+			Uint64("nonce", relay.nonce).
+			Msg("relayed transaction succeeded without executing the relay")
+		s.setRelayStatus(relay, relaystate.StatusMined, outcome.TxHash)
+	}
+
+	s.sentRelaysMux.Lock()
+	defer s.sentRelaysMux.Unlock()
+
+	delete(s.relayBackoffs, relay.key())
+}
+
+// relayFailed records that the relay didn't land, so it is sent again if it is still needed. A reverted relay is held
+// back for a while first.
+func (s *gravityRelayer) relayFailed(relay sentRelay, status relaystate.Status, txHash ethcmn.Hash) {
+	s.setRelayStatus(relay, status, txHash)
+
+	if status != relaystate.StatusReverted {
+		return
+	}
+
+	s.sentRelaysMux.Lock()
+	defer s.sentRelaysMux.Unlock()
+
+	if s.relayBackoffs == nil {
+		s.relayBackoffs = map[string]relayBackoff{}
+	}
+
+	backoff := s.relayBackoffs[relay.key()]
+	backoff.failures++
+
+	delay := maxRelayBackoff
+	if backoff.failures < 16 {
+		if d := s.loopDuration << uint(backoff.failures); d > 0 && d < maxRelayBackoff {
+			delay = d
+		}
+	}
+
+	backoff.retryAfter = time.Now().Add(delay)
+	s.relayBackoffs[relay.key()] = backoff
+}
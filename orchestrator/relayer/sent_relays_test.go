@@ -0,0 +1,171 @@
+package relayer
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	gravityMocks "github.com/cicizeo/loran/mocks/gravity"
+	"github.com/cicizeo/loran/orchestrator/ethereum/committer"
+	"github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/relaystate"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+// newRelayState returns an in-memory relay state store closed at the end of the test.
+func newRelayState(t *testing.T) relaystate.Store {
+	store, err := relaystate.NewBadgerStore(zerolog.Nop(), "")
+	assert.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+
+	return store
+}
+
+func relayStatus(t *testing.T, store relaystate.Store, relay sentRelay) relaystate.Status {
+	entry, ok, err := store.Get(relay.stateKey())
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	return entry.Status
+}
+
+func TestCheckSentRelays(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	gravityAddress := ethcmn.HexToAddress("0x3bdf8428734244c9e5d82c95d125081939d6d42d")
+	tokenContract := ethcmn.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7")
+	txHash := ethcmn.HexToHash("0x1")
+	batchRelay := sentRelay{kind: relayKindBatch, nonce: 5, scope: tokenContract.Hex()}
+
+	newRelayer := func(t *testing.T, mockCtrl *gomock.Controller) (*gravityRelayer, *gravityMocks.MockContract) {
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+		mockGravityContract.EXPECT().Address().Return(gravityAddress).AnyTimes()
+
+		relayer := &gravityRelayer{
+			logger:          logger,
+			gravityContract: mockGravityContract,
+			loopDuration:    time.Minute,
+			relayState:      newRelayState(t),
+		}
+		relayer.trackRelay(txHash, batchRelay)
+
+		return relayer, mockGravityContract
+	}
+
+	t.Run("pending", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, mockGravityContract := newRelayer(t, mockCtrl)
+		mockGravityContract.EXPECT().TxOutcome(txHash).Return(nil, committer.ErrTxPending)
+
+		relayer.checkSentRelays(context.Background())
+		assert.Len(t, relayer.sentRelays, 1)
+		assert.Equal(t, relaystate.StatusSent, relayStatus(t, relayer.relayState, batchRelay))
+		assert.True(t, relayer.isRelayActive(batchRelay))
+	})
+
+	t.Run("executed", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		gravityABI, err := wrappers.GravityMetaData.GetAbi()
+		assert.NoError(t, err)
+
+		receipt := &ethtypes.Receipt{
+			Status: ethtypes.ReceiptStatusSuccessful,
+			Logs: []*ethtypes.Log{
+				{
+					Address: gravityAddress,
+					Topics: []ethcmn.Hash{
+						gravityABI.Events["TransactionBatchExecutedEvent"].ID,
+						ethcmn.BigToHash(big.NewInt(5)),
+						tokenContract.Hash(),
+					},
+					Data: ethcmn.BigToHash(big.NewInt(42)).Bytes(),
+				},
+			},
+		}
+
+		relayer, mockGravityContract := newRelayer(t, mockCtrl)
+		mockGravityContract.EXPECT().TxOutcome(txHash).
+			Return(&committer.TxOutcome{Status: committer.TxStatusSucceeded, TxHash: txHash, Receipt: receipt}, nil)
+
+		relayer.checkSentRelays(context.Background())
+		assert.Empty(t, relayer.sentRelays)
+		assert.Equal(t, relaystate.StatusExecuted, relayStatus(t, relayer.relayState, batchRelay))
+		assert.True(t, relayer.isRelayActive(sentRelay{kind: relayKindBatch, nonce: 4, scope: tokenContract.Hex()}))
+	})
+
+	t.Run("succeeded without the event", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, mockGravityContract := newRelayer(t, mockCtrl)
+		mockGravityContract.EXPECT().TxOutcome(txHash).Return(&committer.TxOutcome{
+			Status:  committer.TxStatusSucceeded,
+			TxHash:  txHash,
+			Receipt: &ethtypes.Receipt{Status: ethtypes.ReceiptStatusSuccessful},
+		}, nil)
+
+		relayer.checkSentRelays(context.Background())
+		assert.Equal(t, relaystate.StatusMined, relayStatus(t, relayer.relayState, batchRelay))
+	})
+
+	t.Run("dropped", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, mockGravityContract := newRelayer(t, mockCtrl)
+		mockGravityContract.EXPECT().TxOutcome(txHash).
+			Return(&committer.TxOutcome{Status: committer.TxStatusDropped, TxHash: txHash}, nil)
+
+		relayer.checkSentRelays(context.Background())
+		assert.Empty(t, relayer.sentRelays)
+		assert.Equal(t, relaystate.StatusDropped, relayStatus(t, relayer.relayState, batchRelay))
+		assert.False(t, relayer.isRelayActive(batchRelay))
+		assert.False(t, relayer.isBackingOff(batchRelay))
+	})
+
+	t.Run("reverted", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, mockGravityContract := newRelayer(t, mockCtrl)
+		outcome := &committer.TxOutcome{
+			Status:  committer.TxStatusReverted,
+			TxHash:  txHash,
+			Receipt: &ethtypes.Receipt{Status: ethtypes.ReceiptStatusFailed},
+		}
+		mockGravityContract.EXPECT().TxOutcome(txHash).Return(outcome, nil)
+		mockGravityContract.EXPECT().RevertReason(gomock.Any(), outcome).
+			Return(&gravity.RevertError{Name: "BatchTimedOut"}, nil)
+
+		relayer.checkSentRelays(context.Background())
+		assert.Empty(t, relayer.sentRelays)
+		assert.Equal(t, relaystate.StatusReverted, relayStatus(t, relayer.relayState, batchRelay))
+		assert.False(t, relayer.isRelayActive(batchRelay))
+		assert.True(t, relayer.isBackingOff(batchRelay))
+		assert.Equal(t, 1, relayer.relayBackoffs[batchRelay.key()].failures)
+	})
+
+	t.Run("restored after a restart", func(t *testing.T) {
+		relayState := newRelayState(t)
+		assert.NoError(t, relayState.Set(batchRelay.stateKey(), relaystate.Entry{Status: relaystate.StatusSent, TxHash: txHash}))
+		assert.NoError(t, relayState.Set(
+			sentRelay{kind: relayKindValset, nonce: 3}.stateKey(),
+			relaystate.Entry{Status: relaystate.StatusExecuted, TxHash: ethcmn.HexToHash("0x2")},
+		))
+
+		relayer := &gravityRelayer{logger: logger, relayState: relayState}
+		assert.NoError(t, relayer.restoreSentRelays())
+		assert.Equal(t, map[ethcmn.Hash]sentRelay{txHash: batchRelay}, relayer.sentRelays)
+	})
+}
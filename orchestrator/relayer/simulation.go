@@ -0,0 +1,31 @@
+package relayer
+
+import (
+	"context"
+)
+
+// simulateRelay runs a relay against the pending block right before it is sent, returning false if it would revert so
+// we don't pay for a transaction that lost a relay race. A simulation that fails for another reason doesn't hold the
+// relay back.
+func (s *gravityRelayer) simulateRelay(ctx context.Context, relay sentRelay, txData []byte, gasCost uint64) bool {
+	logger := s.logger.With().Str("relay", string(relay.kind)).Uint64("nonce", relay.nonce).Logger()
+
+	revertErr, err := s.gravityContract.SimulateTx(ctx, txData, gasCost)
+	if err != nil {
+		logger.Err(err).Msg("failed to simulate relay, sending it anyway")
+		return true
+	}
+
+	switch {
+	case revertErr == nil:
+		return true
+
+	case revertErr.IsStale():
+		logger.Info().Str("reason", revertErr.Error()).Msg("relay is outdated, likely landed by another relayer; skipping")
+
+	default:
+		logger.Error().Str("reason", revertErr.Error()).Msg("relay would revert; skipping")
+	}
+
+	return false
+}
@@ -2,8 +2,11 @@ package relayer
 
 import (
 	"context"
+	"math/big"
+	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
 	"github.com/pkg/errors"
 )
 
@@ -36,7 +39,8 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
-	if s.lastSentValsetNonce >= latestCosmosConfirmed.Nonce {
+	relay := sentRelay{kind: relayKindValset, nonce: latestCosmosConfirmed.Nonce}
+	if s.isRelayActive(relay) {
 		s.logger.Debug().Msg("already relayed this valset; skipping")
 		return nil
 	}
@@ -58,6 +62,13 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
+	if s.isBackingOff(relay) {
+		s.logger.Debug().
+			Uint64("latest_cosmos_confirmed_nonce", latestCosmosConfirmed.Nonce).
+			Msg("valset update reverted recently, backing off")
+		return nil
+	}
+
 	s.logger.Info().
 		Uint64("latest_cosmos_confirmed_nonce", latestCosmosConfirmed.Nonce).
 		Uint64("latest_ethereum_valset_nonce", latestEthereumValsetNonce.Uint64()).
@@ -84,9 +95,9 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
-	// TODO: Estimate profitability using "valset reward" param.
-	//
-	// Ref: https://github.com/cicizeo/loran/issues/56
+	if !s.isValsetWorthRelaying(ctx, currentValset, *latestCosmosConfirmed, estimatedGasCost, gasPrice) {
+		return nil
+	}
 
 	// Checking in pending txs (mempool) if tx with same input is already submitted.
 	// We have to check this at the very last moment because any other relayer could have submitted.
@@ -96,6 +107,11 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 		return nil
 	}
 
+	// The state may have changed since the gas estimation, another relayer could have landed this valset update.
+	if !s.simulateRelay(ctx, relay, txData, estimatedGasCost) {
+		return nil
+	}
+
 	// Send Valset Update to Ethereum
 	txHash, err := s.gravityContract.SendTx(ctx, s.gravityContract.Address(), txData, estimatedGasCost, gasPrice)
 	if err != nil {
@@ -107,13 +123,101 @@ func (s *gravityRelayer) RelayValsets(ctx context.Context, currentValset types.V
 	}
 
 	s.logger.Info().Str("tx_hash", txHash.Hex()).Msg("sent Tx (Gravity updateValset)")
-
-	// update our local tracker of the latest valset
-	s.lastSentValsetNonce = latestCosmosConfirmed.Nonce
+	s.trackRelay(txHash, relay)
 
 	return nil
 }
 
+// isValsetWorthRelaying returns true if the reward of the valset update pays for its gas cost. An unprofitable valset
+// update is still relayed once the valset on Ethereum is older than the max age, so the liveness of the bridge doesn't
+// depend on the rewards alone.
+func (s *gravityRelayer) isValsetWorthRelaying(
+	ctx context.Context,
+	currentValset types.Valset,
+	valset types.Valset,
+	ethGasCost uint64,
+	gasPrice *big.Int,
+) bool {
+	if s.IsValsetProfitable(ctx, valset, ethGasCost, gasPrice, s.valsetProfitMultiplier) {
+		return true
+	}
+
+	if s.valsetMaxAge > 0 {
+		if age, ok := s.ethereumValsetAge(ctx, currentValset); ok && age >= s.valsetMaxAge {
+			s.logger.Warn().
+				Uint64("valset_nonce", valset.Nonce).
+				Uint64("current_eth_valset_nonce", currentValset.Nonce).
+				Dur("current_eth_valset_age", age).
+				Msg("valset update is not profitable, but the valset on Ethereum is too old; relaying anyway")
+			return true
+		}
+	}
+
+	s.logger.Info().
+		Uint64("valset_nonce", valset.Nonce).
+		Msg("valset update is not profitable; skipping")
+
+	return false
+}
+
+// IsValsetProfitable gets the current prices in USD of ETH and the reward token of the valset and compares the value
+// of the estimated gas cost of the valset update to its reward. A valset without a reward is never profitable.
+func (s *gravityRelayer) IsValsetProfitable(
+	ctx context.Context,
+	valset types.Valset,
+	ethGasCost uint64,
+	gasPrice *big.Int,
+	profitMultiplier float64,
+) bool {
+	if s.priceFeeder == nil || profitMultiplier == 0 {
+		return true
+	}
+
+	rewardToken := ethcmn.HexToAddress(valset.RewardToken)
+	if valset.RewardAmount.IsNil() || !valset.RewardAmount.IsPositive() || rewardToken == (ethcmn.Address{}) {
+		s.logger.Debug().Uint64("valset_nonce", valset.Nonce).Msg("valset has no reward")
+		return false
+	}
+
+	return s.isRewardProfitable(
+		ctx,
+		relayKindValset,
+		rewardToken,
+		valset.RewardAmount.BigInt(),
+		ethGasCost,
+		gasPrice,
+		profitMultiplier,
+	)
+}
+
+// ethereumValsetAge returns the time since the valset on Ethereum was set, based on the block of the ValsetUpdatedEvent
+// found by FindLatestValset. The last return value is false if the age is unknown.
+func (s *gravityRelayer) ethereumValsetAge(ctx context.Context, currentValset types.Valset) (time.Duration, bool) {
+	s.latestValsetMux.Lock()
+	nonce, block := s.latestValsetNonce, s.latestValsetBlock
+	s.latestValsetMux.Unlock()
+
+	if nonce != currentValset.Nonce || block == 0 {
+		return 0, false
+	}
+
+	header, err := s.ethProvider.HeaderByNumber(ctx, new(big.Int).SetUint64(block))
+	if err != nil {
+		s.logger.Err(err).Uint64("block", block).Msg("failed to get the header of the latest valset update")
+		return 0, false
+	}
+
+	return time.Since(time.Unix(int64(header.Time), 0)), true
+}
+
+func (s *gravityRelayer) setLatestValsetBlock(nonce, block uint64) {
+	s.latestValsetMux.Lock()
+	defer s.latestValsetMux.Unlock()
+
+	s.latestValsetNonce = nonce
+	s.latestValsetBlock = block
+}
+
 func (s *gravityRelayer) findLatestValidValset(
 	ctx context.Context,
 	currentValset types.Valset,
@@ -3,15 +3,24 @@ package relayer
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
+	"time"
 
 	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
 	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 	"github.com/cicizeo/loran/mocks"
 	gravityMocks "github.com/cicizeo/loran/mocks/gravity"
+	"github.com/cicizeo/loran/orchestrator/coingecko"
 )
 
 func TestRelayValsets(t *testing.T) {
@@ -92,6 +101,8 @@ func TestRelayValsets(t *testing.T) {
 
 		mockGravityContract.EXPECT().IsPendingTxInput([]byte{1, 2, 3}, gomock.Any()).Return(false)
 
+		mockGravityContract.EXPECT().SimulateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
 		mockGravityContract.EXPECT().SendTx(
 			gomock.Any(),
 			gravityAddress,
@@ -363,6 +374,8 @@ func TestRelayValsets(t *testing.T) {
 
 		mockGravityContract.EXPECT().IsPendingTxInput([]byte{1, 2, 3}, gomock.Any()).Return(false)
 
+		mockGravityContract.EXPECT().SimulateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
 		mockGravityContract.EXPECT().SendTx(
 			gomock.Any(),
 			gravityAddress,
@@ -457,6 +470,8 @@ func TestRelayValsets(t *testing.T) {
 
 		mockGravityContract.EXPECT().IsPendingTxInput([]byte{1, 2, 3}, gomock.Any()).Return(false)
 
+		mockGravityContract.EXPECT().SimulateTx(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil, nil)
+
 		mockGravityContract.EXPECT().SendTx(
 			gomock.Any(),
 			gravityAddress,
@@ -475,3 +490,95 @@ func TestRelayValsets(t *testing.T) {
 	})
 
 }
+
+func TestIsValsetWorthRelaying(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+	rewardToken := ethcmn.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7")
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("contract_addresses") != "" {
+			fmt.Fprint(w, `{"0xdac17f958d2ee523a2206206994597c13d831ec7":{"usd":0.998233}}`)
+			return
+		}
+		fmt.Fprint(w, `{"ethereum": {"usd": 4271.57}}`)
+	}))
+	defer svr.Close()
+
+	newRelayer := func(mockCtrl *gomock.Controller) (*gravityRelayer, *mocks.MockEVMProviderWithRet) {
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+		mockGravityContract.EXPECT().GetERC20Decimals(gomock.Any(), rewardToken, fromAddress).Return(uint8(6), nil).AnyTimes()
+
+		relayer := &gravityRelayer{
+			logger:                 logger,
+			gravityContract:        mockGravityContract,
+			ethProvider:            ethProvider,
+			priceFeeder:            coingecko.NewCoingeckoPriceFeed(logger, 100, &coingecko.Config{BaseURL: svr.URL}),
+			valsetProfitMultiplier: 1.1,
+			valsetMaxAge:           24 * time.Hour,
+		}
+
+		return relayer, ethProvider
+	}
+
+	currentValset := types.Valset{Nonce: 2}
+	valset := func(reward int64) types.Valset {
+		return types.Valset{Nonce: 3, RewardAmount: sdk.NewInt(reward), RewardToken: rewardToken.Hex()}
+	}
+
+	// 100000 gas at 10 gwei is 0.001 ETH, about 4.27 USD.
+	gasPrice := big.NewInt(10000000000)
+
+	t.Run("profitable", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, _ := newRelayer(mockCtrl)
+		assert.True(t, relayer.isValsetWorthRelaying(context.Background(), currentValset, valset(5000000), 100000, gasPrice))
+	})
+
+	t.Run("no reward", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, _ := newRelayer(mockCtrl)
+		assert.False(t, relayer.IsValsetProfitable(context.Background(), types.Valset{Nonce: 3}, 100000, gasPrice, 1.1))
+	})
+
+	t.Run("not profitable, recent valset on Ethereum", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, ethProvider := newRelayer(mockCtrl)
+		relayer.setLatestValsetBlock(2, 100)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(100)).
+			Return(&ethtypes.Header{Time: uint64(time.Now().Add(-time.Hour).Unix())}, nil)
+
+		assert.False(t, relayer.isValsetWorthRelaying(context.Background(), currentValset, valset(1000000), 100000, gasPrice))
+	})
+
+	t.Run("not profitable, old valset on Ethereum", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		relayer, ethProvider := newRelayer(mockCtrl)
+		relayer.setLatestValsetBlock(2, 100)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(100)).
+			Return(&ethtypes.Header{Time: uint64(time.Now().Add(-48 * time.Hour).Unix())}, nil)
+
+		assert.True(t, relayer.isValsetWorthRelaying(context.Background(), currentValset, valset(1000000), 100000, gasPrice))
+	})
+
+	t.Run("not profitable, unknown age", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		// the latest valset block found is for another nonce
+		relayer, _ := newRelayer(mockCtrl)
+		relayer.setLatestValsetBlock(1, 100)
+
+		assert.False(t, relayer.isValsetWorthRelaying(context.Background(), currentValset, valset(1000000), 100000, gasPrice))
+	})
+}
@@ -0,0 +1,142 @@
+package relaystate
+
+import (
+	"encoding/json"
+	"fmt"
+
+	badger "github.com/dgraph-io/badger/v3"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type badgerStore struct {
+	logger zerolog.Logger
+	db     *badger.DB
+}
+
+// record is the stored value of an item. It repeats the key so Sent doesn't have to parse it back.
+type record struct {
+	Key   Key   `json:"key"`
+	Entry Entry `json:"entry"`
+}
+
+// NewBadgerStore opens (or creates) a badger backed relay state store in dbDir. Writes are synced to disk before
+// returning, so a relay sent right before a crash is not sent again. If dbDir is empty the store is kept in memory.
+func NewBadgerStore(logger zerolog.Logger, dbDir string) (Store, error) {
+	opts := badger.DefaultOptions(dbDir).WithLogger(nil)
+	if dbDir == "" {
+		opts = opts.WithInMemory(true)
+	} else {
+		opts = opts.WithSyncWrites(true)
+	}
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open relay state db")
+	}
+
+	return &badgerStore{
+		logger: logger.With().Str("module", "relay_state").Logger(),
+		db:     db,
+	}, nil
+}
+
+func (s *badgerStore) Get(key Key) (entry Entry, ok bool, err error) {
+	err = s.db.View(func(txn *badger.Txn) error {
+		item, err := txn.Get([]byte(key.String()))
+		if err == badger.ErrKeyNotFound {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		rec, err := getRecord(item)
+		if err != nil {
+			return err
+		}
+
+		entry, ok = rec.Entry, true
+		return nil
+	})
+
+	return entry, ok, err
+}
+
+func (s *badgerStore) Set(key Key, entry Entry) error {
+	value, err := json.Marshal(record{Key: key, Entry: entry})
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal relay state entry")
+	}
+
+	return s.db.Update(func(txn *badger.Txn) error {
+		return txn.Set([]byte(key.String()), value)
+	})
+}
+
+func (s *badgerStore) Latest(kind Kind, scope string) (key Key, entry Entry, ok bool, err error) {
+	prefix := []byte(fmt.Sprintf("%s/%s/", kind, scope))
+
+	err = s.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Reverse = true
+		opts.Prefix = prefix
+
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		// Seeking past the prefix lands on its last key when iterating in reverse.
+		it.Seek(append(append([]byte{}, prefix...), 0xff))
+		if !it.ValidForPrefix(prefix) {
+			return nil
+		}
+
+		rec, err := getRecord(it.Item())
+		if err != nil {
+			return err
+		}
+
+		key, entry, ok = rec.Key, rec.Entry, true
+		return nil
+	})
+
+	return key, entry, ok, err
+}
+
+func (s *badgerStore) Sent() (map[Key]Entry, error) {
+	sent := map[Key]Entry{}
+
+	err := s.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			rec, err := getRecord(it.Item())
+			if err != nil {
+				return err
+			}
+
+			if rec.Entry.Status == StatusSent {
+				sent[rec.Key] = rec.Entry
+			}
+		}
+
+		return nil
+	})
+
+	return sent, err
+}
+
+func (s *badgerStore) Close() error {
+	return s.db.Close()
+}
+
+func getRecord(item *badger.Item) (rec record, err error) {
+	err = item.Value(func(v []byte) error {
+		return json.Unmarshal(v, &rec)
+	})
+	if err != nil {
+		return record{}, errors.Wrapf(err, "failed to unmarshal the relay state entry of %s", item.Key())
+	}
+
+	return rec, nil
+}
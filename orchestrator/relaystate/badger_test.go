@@ -0,0 +1,69 @@
+package relaystate
+
+import (
+	"os"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadgerStore(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	store, err := NewBadgerStore(logger, "")
+	assert.NoError(t, err)
+	defer store.Close()
+
+	tokenA := "0xdac17f958d2ee523a2206206994597c13d831ec7"
+	tokenB := "0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"
+
+	t.Run("empty", func(t *testing.T) {
+		_, ok, err := store.Get(Key{Kind: KindBatch, Scope: tokenA, Nonce: 1})
+		assert.NoError(t, err)
+		assert.False(t, ok)
+
+		_, _, ok, err = store.Latest(KindBatch, tokenA)
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("set and get", func(t *testing.T) {
+		key := Key{Kind: KindBatch, Scope: tokenA, Nonce: 9}
+		assert.NoError(t, store.Set(key, Entry{Status: StatusSent, TxHash: ethcmn.HexToHash("0x01")}))
+
+		entry, ok, err := store.Get(key)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, StatusSent, entry.Status)
+		assert.Equal(t, ethcmn.HexToHash("0x01"), entry.TxHash)
+	})
+
+	t.Run("latest is per scope and sorted by nonce", func(t *testing.T) {
+		assert.NoError(t, store.Set(Key{Kind: KindBatch, Scope: tokenA, Nonce: 10}, Entry{Status: StatusReverted}))
+		assert.NoError(t, store.Set(Key{Kind: KindBatch, Scope: tokenB, Nonce: 11}, Entry{Status: StatusExecuted}))
+
+		key, entry, ok, err := store.Latest(KindBatch, tokenA)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, Key{Kind: KindBatch, Scope: tokenA, Nonce: 10}, key)
+		assert.Equal(t, StatusReverted, entry.Status)
+
+		key, _, ok, err = store.Latest(KindBatch, tokenB)
+		assert.NoError(t, err)
+		assert.True(t, ok)
+		assert.Equal(t, uint64(11), key.Nonce)
+
+		_, _, ok, err = store.Latest(KindValset, "")
+		assert.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("sent", func(t *testing.T) {
+		sent, err := store.Sent()
+		assert.NoError(t, err)
+		assert.Len(t, sent, 1)
+		assert.Equal(t, ethcmn.HexToHash("0x01"), sent[Key{Kind: KindBatch, Scope: tokenA, Nonce: 9}].TxHash)
+	})
+}
@@ -0,0 +1,84 @@
+package relaystate
+
+import (
+	"fmt"
+	"time"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+)
+
+// Kind is the kind of Gravity item a relayer submits to Ethereum.
+type Kind string
+
+const (
+	KindValset    Kind = "valset"
+	KindBatch     Kind = "batch"
+	KindLogicCall Kind = "logic_call"
+)
+
+// Status is where a relay stands, from the moment its transaction is sent until the Gravity contract executed it.
+type Status string
+
+const (
+	// StatusSent means the transaction was sent and its outcome is not known yet.
+	StatusSent Status = "sent"
+	// StatusMined means the transaction succeeded but the event of the executed item was not observed.
+	StatusMined Status = "mined"
+	// StatusExecuted means the event of the executed item was observed in the receipt of the transaction.
+	StatusExecuted Status = "executed"
+	// StatusReverted means the transaction was mined but reverted.
+	StatusReverted Status = "reverted"
+	// StatusDropped means the transaction was never mined.
+	StatusDropped Status = "dropped"
+)
+
+// Key identifies a relayed item. Scope tells apart the items whose nonce is not unique on its own: the token contract
+// of a batch or the invalidation ID of a logic call. It is empty for valsets.
+type Key struct {
+	Kind  Kind   `json:"kind"`
+	Scope string `json:"scope"`
+	Nonce uint64 `json:"nonce"`
+}
+
+// String returns the key the item is stored under. The nonce is zero padded so the items of a scope are sorted by
+// nonce.
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s/%020d", k.Kind, k.Scope, k.Nonce)
+}
+
+// Entry is what the store remembers about a relayed item.
+type Entry struct {
+	Status    Status      `json:"status"`
+	TxHash    ethcmn.Hash `json:"tx_hash"`
+	UpdatedAt time.Time   `json:"updated_at"`
+}
+
+// Active returns true if the relay is on its way or done, so neither it nor an older item of the same scope should be
+// sent again.
+func (e Entry) Active() bool {
+	switch e.Status {
+	case StatusSent, StatusMined, StatusExecuted:
+		return true
+	default:
+		return false
+	}
+}
+
+// Store is a persistent record of the items our relayer sent to Ethereum and of their outcome, so a restarted relayer
+// neither sends them twice nor forgets about the ones that failed to land.
+type Store interface {
+	// Get returns the entry of the item identified by key. The second return value is false if there is none.
+	Get(key Key) (Entry, bool, error)
+
+	// Set records the entry of the item identified by key.
+	Set(key Key, entry Entry) error
+
+	// Latest returns the key and entry of the item with the highest nonce of the given kind and scope. The last
+	// return value is false if there is none.
+	Latest(kind Kind, scope string) (Key, Entry, bool, error)
+
+	// Sent returns the items whose transaction outcome is not known yet.
+	Sent() (map[Key]Entry, error)
+
+	Close() error
+}
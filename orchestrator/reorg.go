@@ -0,0 +1,170 @@
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+)
+
+// maxScannedRanges is the number of scanned ranges we keep the block hash of. With the default of 2000 blocks per
+// loop this goes back way further than any reorg we could survive anyway.
+const maxScannedRanges = 128
+
+// scannedRange is a range of Ethereum blocks the oracle has already scanned for events, together with the hash of
+// its last block at the time it was scanned.
+type scannedRange struct {
+	fromBlock uint64
+	toBlock   uint64
+	blockHash ethcmn.Hash
+}
+
+// recordScannedRange keeps the hash of the last block of a range we have scanned. Consecutive ranges share a block;
+// any range going further than the start of the new one is dropped, as it is being scanned again after a rewind or a
+// resync.
+func (p *gravityOrchestrator) recordScannedRange(fromBlock, toBlock uint64, blockHash ethcmn.Hash) {
+	i := len(p.scannedRanges)
+	for i > 0 && p.scannedRanges[i-1].toBlock > fromBlock {
+		i--
+	}
+
+	p.scannedRanges = append(p.scannedRanges[:i], scannedRange{
+		fromBlock: fromBlock,
+		toBlock:   toBlock,
+		blockHash: blockHash,
+	})
+
+	if len(p.scannedRanges) > maxScannedRanges {
+		p.scannedRanges = p.scannedRanges[len(p.scannedRanges)-maxScannedRanges:]
+	}
+}
+
+// RewindOnReorg checks that the blocks we have already scanned are still part of the canonical chain. As every block
+// commits to its parent, it is enough to check the last block of each range, starting from the most recent one and
+// stopping at the first one that still matches. If any range got orphaned, the returned block is the first block
+// of the oldest orphaned range, so it will be scanned again.
+//
+// The scanned ranges are only kept in memory: after a restart, a reorg of the blocks scanned before it goes unnoticed,
+// so the oracle relies on the finality depth alone for those.
+func (p *gravityOrchestrator) RewindOnReorg(ctx context.Context, lastCheckedBlock uint64) (uint64, error) {
+	var (
+		orphaned bool
+		rewindTo uint64
+	)
+
+	for len(p.scannedRanges) > 0 {
+		last := p.scannedRanges[len(p.scannedRanges)-1]
+
+		header, err := p.ethProvider.HeaderByNumber(ctx, new(big.Int).SetUint64(last.toBlock))
+		if err != nil {
+			return lastCheckedBlock, errors.Wrapf(err, "failed to get header of block %d", last.toBlock)
+		}
+
+		if header.Hash() == last.blockHash {
+			break
+		}
+
+		p.logger.Error().
+			Uint64("start", last.fromBlock).
+			Uint64("end", last.toBlock).
+			Str("scanned_block_hash", last.blockHash.Hex()).
+			Str("canonical_block_hash", header.Hash().Hex()).
+			Msg("CHAIN REORGANISATION DETECTED: a range of blocks we already scanned is no longer canonical")
+
+		orphaned = true
+		rewindTo = last.fromBlock
+		p.scannedRanges = p.scannedRanges[:len(p.scannedRanges)-1]
+	}
+
+	if !orphaned || rewindTo >= lastCheckedBlock {
+		return lastCheckedBlock, nil
+	}
+
+	p.logger.Error().
+		Uint64("last_checked_block", lastCheckedBlock).
+		Uint64("rewind_to", rewindTo).
+		Msg("CHAIN REORGANISATION DETECTED: rewinding the oracle; claims sent for the orphaned blocks may not match " +
+			"the chain and must be checked manually")
+
+	return rewindTo, nil
+}
+
+// checkCanonicalLogs makes sure that we never build a claim from the logs of an orphaned block. To keep the number of
+// header lookups per scan constant, only the newest log and the last block of the range are checked against the
+// canonical chain: a reorg orphaning an older log orphans every block after it too. The logs of the first block, which
+// is shared with the last scanned range, are checked against the hash recorded for it. It returns the header of
+// toBlock, which is fetched anyway.
+func (p *gravityOrchestrator) checkCanonicalLogs(
+	ctx context.Context,
+	fromBlock uint64,
+	toBlock uint64,
+	logs []ethtypes.Log,
+) (*ethtypes.Header, error) {
+	toHeader, err := p.ethProvider.HeaderByNumber(ctx, new(big.Int).SetUint64(toBlock))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get header of block %d", toBlock)
+	}
+
+	canonicalHashes := map[uint64]ethcmn.Hash{
+		toBlock: toHeader.Hash(),
+	}
+
+	if n := len(p.scannedRanges); n > 0 && p.scannedRanges[n-1].toBlock == fromBlock {
+		canonicalHashes[fromBlock] = p.scannedRanges[n-1].blockHash
+	}
+
+	var newestBlock uint64
+	for _, log := range logs {
+		if log.BlockNumber > newestBlock {
+			newestBlock = log.BlockNumber
+		}
+	}
+
+	if _, ok := canonicalHashes[newestBlock]; len(logs) > 0 && !ok {
+		header, err := p.ethProvider.HeaderByNumber(ctx, new(big.Int).SetUint64(newestBlock))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get header of block %d", newestBlock)
+		}
+
+		canonicalHashes[newestBlock] = header.Hash()
+	}
+
+	// Logs of the blocks in between can't be compared to a header, but they must all agree on the hash of their block.
+	for _, log := range logs {
+		canonicalHash, ok := canonicalHashes[log.BlockNumber]
+		if !ok {
+			canonicalHash = log.BlockHash
+			canonicalHashes[log.BlockNumber] = canonicalHash
+		}
+
+		if log.Removed || log.BlockHash != canonicalHash {
+			return nil, &orphanedLogError{
+				blockNumber:   log.BlockNumber,
+				logBlockHash:  log.BlockHash,
+				canonicalHash: canonicalHash,
+			}
+		}
+	}
+
+	return toHeader, nil
+}
+
+// orphanedLogError is returned when a log we got from Ethereum (or the event index) doesn't belong to the canonical
+// chain anymore.
+type orphanedLogError struct {
+	blockNumber   uint64
+	logBlockHash  ethcmn.Hash
+	canonicalHash ethcmn.Hash
+}
+
+func (e *orphanedLogError) Error() string {
+	return fmt.Sprintf(
+		"log of block %d was emitted in orphaned block %s (canonical block is %s)",
+		e.blockNumber,
+		e.logBlockHash.Hex(),
+		e.canonicalHash.Hex(),
+	)
+}
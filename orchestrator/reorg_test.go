@@ -0,0 +1,165 @@
+package orchestrator
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+)
+
+func TestRewindOnReorg(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	header10 := &ethtypes.Header{Number: big.NewInt(10)}
+	header20 := &ethtypes.Header{Number: big.NewInt(20)}
+	header30 := &ethtypes.Header{Number: big.NewInt(30)}
+	orphanedHeader20 := &ethtypes.Header{Number: big.NewInt(20), Extra: []byte("orphaned")}
+	orphanedHeader30 := &ethtypes.Header{Number: big.NewInt(30), Extra: []byte("orphaned")}
+
+	newOrch := func(ethProvider *mocks.MockEVMProviderWithRet) *gravityOrchestrator {
+		orch := &gravityOrchestrator{logger: logger, ethProvider: ethProvider}
+		orch.recordScannedRange(1, 10, header10.Hash())
+		orch.recordScannedRange(10, 20, header20.Hash())
+		orch.recordScannedRange(20, 30, header30.Hash())
+		return orch
+	}
+
+	t.Run("no reorg", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(30)).Return(header30, nil)
+
+		orch := newOrch(ethProvider)
+
+		lastCheckedBlock, err := orch.RewindOnReorg(context.Background(), 30)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(30), lastCheckedBlock)
+		assert.Len(t, orch.scannedRanges, 3)
+	})
+
+	t.Run("reorg", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(30)).Return(orphanedHeader30, nil)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(20)).Return(orphanedHeader20, nil)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(10)).Return(header10, nil)
+
+		orch := newOrch(ethProvider)
+
+		lastCheckedBlock, err := orch.RewindOnReorg(context.Background(), 30)
+		assert.Nil(t, err)
+		assert.Equal(t, uint64(10), lastCheckedBlock)
+		assert.Equal(t, []scannedRange{{fromBlock: 1, toBlock: 10, blockHash: header10.Hash()}}, orch.scannedRanges)
+	})
+}
+
+func TestRecordScannedRange(t *testing.T) {
+	orch := &gravityOrchestrator{}
+
+	orch.recordScannedRange(1, 10, ethcmn.HexToHash("0x01"))
+	orch.recordScannedRange(10, 20, ethcmn.HexToHash("0x02"))
+	orch.recordScannedRange(20, 30, ethcmn.HexToHash("0x03"))
+
+	// after a resync we scan again from an older block
+	orch.recordScannedRange(15, 25, ethcmn.HexToHash("0x04"))
+
+	assert.Equal(t, []scannedRange{
+		{fromBlock: 1, toBlock: 10, blockHash: ethcmn.HexToHash("0x01")},
+		{fromBlock: 15, toBlock: 25, blockHash: ethcmn.HexToHash("0x04")},
+	}, orch.scannedRanges)
+
+	for i := uint64(0); i < maxScannedRanges*2; i++ {
+		orch.recordScannedRange(100+i, 100+i, ethcmn.HexToHash("0x05"))
+	}
+
+	assert.Len(t, orch.scannedRanges, maxScannedRanges)
+}
+
+func TestCheckCanonicalLogs(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	header3 := &ethtypes.Header{Number: big.NewInt(3)}
+	header5 := &ethtypes.Header{Number: big.NewInt(5)}
+
+	t.Run("ok", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(5)).Return(header5, nil)
+
+		orch := &gravityOrchestrator{logger: logger, ethProvider: ethProvider}
+
+		// block 3 is covered by the header of block 5
+		header, err := orch.checkCanonicalLogs(context.Background(), 1, 5, []ethtypes.Log{
+			{BlockNumber: 3, BlockHash: header3.Hash(), Index: 0},
+			{BlockNumber: 3, BlockHash: header3.Hash(), Index: 1},
+			{BlockNumber: 5, BlockHash: header5.Hash()},
+		})
+		assert.Nil(t, err)
+		assert.Equal(t, header5, header)
+	})
+
+	t.Run("orphaned log", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(5)).Return(header5, nil)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(3)).Return(header3, nil)
+
+		orch := &gravityOrchestrator{logger: logger, ethProvider: ethProvider}
+
+		_, err := orch.checkCanonicalLogs(context.Background(), 1, 5, []ethtypes.Log{
+			{BlockNumber: 3, BlockHash: ethcmn.HexToHash("0x01")},
+		})
+		_, ok := err.(*orphanedLogError)
+		assert.True(t, ok)
+	})
+
+	t.Run("logs disagree on a block", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(5)).Return(header5, nil)
+
+		orch := &gravityOrchestrator{logger: logger, ethProvider: ethProvider}
+
+		_, err := orch.checkCanonicalLogs(context.Background(), 1, 5, []ethtypes.Log{
+			{BlockNumber: 3, BlockHash: header3.Hash(), Index: 0},
+			{BlockNumber: 3, BlockHash: ethcmn.HexToHash("0x01"), Index: 1},
+			{BlockNumber: 5, BlockHash: header5.Hash()},
+		})
+		_, ok := err.(*orphanedLogError)
+		assert.True(t, ok)
+	})
+
+	t.Run("first block orphaned since the last scan", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), big.NewInt(5)).Return(header5, nil)
+
+		orch := &gravityOrchestrator{logger: logger, ethProvider: ethProvider}
+		orch.recordScannedRange(1, 3, ethcmn.HexToHash("0x01"))
+
+		_, err := orch.checkCanonicalLogs(context.Background(), 3, 5, []ethtypes.Log{
+			{BlockNumber: 3, BlockHash: header3.Hash()},
+		})
+		_, ok := err.(*orphanedLogError)
+		assert.True(t, ok)
+	})
+}
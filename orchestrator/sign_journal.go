@@ -0,0 +1,130 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/cicizeo/loran/orchestrator/signjournal"
+)
+
+// reserveSignature checks the signing journal before our Ethereum key signs the checkpoint of an item. It returns
+// false if the item must not be signed, either because we signed a different checkpoint for it or because our confirm
+// is already on Cosmos. Otherwise the same checkpoint is signed again as long as Cosmos asks for it, as our previous
+// confirm may never have made it on chain.
+func (p *gravityOrchestrator) reserveSignature(
+	logger zerolog.Logger,
+	key signjournal.Key,
+	checkpoint ethcmn.Hash,
+) (bool, error) {
+	if p.signJournal == nil {
+		return true, nil
+	}
+
+	entry, err := p.signJournal.Reserve(key, checkpoint)
+	if conflictErr, ok := err.(*signjournal.ConflictError); ok {
+		logger.Error().
+			Err(conflictErr).
+			Str("item", key.String()).
+			Str("signed_checkpoint", conflictErr.SignedCheckpoint.Hex()).
+			Str("requested_checkpoint", conflictErr.RequestedCheckpoint.Hex()).
+			Msg("DOUBLE SIGN PREVENTED: the Cosmos node asked us to sign a different checkpoint for an item we " +
+				"already signed; it may be compromised")
+		return false, nil
+	} else if err != nil {
+		return false, errors.Wrap(err, "failed to reserve the signature in the signing journal")
+	}
+
+	if entry.Confirmed {
+		logger.Debug().Str("item", key.String()).Msg("our confirm is already on Cosmos; skipping")
+		return false, nil
+	}
+
+	return true, nil
+}
+
+// awaitSignature remembers that the signature of an item was queued for broadcast, so it is confirmed in the signing
+// journal once Cosmos has it.
+func (p *gravityOrchestrator) awaitSignature(key signjournal.Key) {
+	if p.signJournal == nil {
+		return
+	}
+
+	if p.unconfirmedSignatures == nil {
+		p.unconfirmedSignatures = make(map[signjournal.Key]bool)
+	}
+
+	p.unconfirmedSignatures[key] = true
+}
+
+// confirmSignatures records in the signing journal the signatures that made it on chain. The ones Cosmos doesn't have
+// are checked again on the next loop, unless Cosmos no longer asks us to sign them (pending holds the items it still
+// does), e.g. because the item was pruned.
+func (p *gravityOrchestrator) confirmSignatures(
+	ctx context.Context,
+	logger zerolog.Logger,
+	pending map[signjournal.Key]bool,
+) error {
+	for key := range p.unconfirmedSignatures {
+		onChain, err := p.isSignatureOnChain(ctx, key)
+		if err != nil {
+			logger.Err(err).Str("item", key.String()).Msg("failed to look for our confirm on Cosmos; will retry")
+			continue
+		}
+
+		if onChain {
+			if err := p.signJournal.Confirm(key); err != nil {
+				return errors.Wrap(err, "failed to confirm the signature in the signing journal")
+			}
+		} else if pending[key] {
+			continue
+		}
+
+		delete(p.unconfirmedSignatures, key)
+	}
+
+	return nil
+}
+
+// isSignatureOnChain returns true if Cosmos has our confirm of the item.
+func (p *gravityOrchestrator) isSignatureOnChain(ctx context.Context, key signjournal.Key) (bool, error) {
+	orchestrator := p.gravityBroadcastClient.AccFromAddress().String()
+
+	switch key.Kind {
+	case signjournal.KindValset:
+		resp, err := p.cosmosQueryClient.ValsetConfirm(ctx, &types.QueryValsetConfirmRequest{
+			Nonce:   key.Nonce,
+			Address: orchestrator,
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get our confirm of valset %d", key.Nonce)
+		}
+
+		return resp != nil && resp.Confirm != nil, nil
+
+	case signjournal.KindBatch:
+		resp, err := p.cosmosQueryClient.BatchConfirms(ctx, &types.QueryBatchConfirmsRequest{
+			Nonce:           key.Nonce,
+			ContractAddress: ethcmn.HexToAddress(key.Scope).Hex(),
+		})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get the confirms of batch %d of %s", key.Nonce, key.Scope)
+		}
+
+		if resp == nil {
+			return false, nil
+		}
+
+		for _, confirm := range resp.Confirms {
+			if confirm.Orchestrator == orchestrator {
+				return true, nil
+			}
+		}
+
+		return false, nil
+	}
+
+	return false, errors.Errorf("can't look for our confirm of %s on Cosmos", key)
+}
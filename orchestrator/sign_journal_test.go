@@ -0,0 +1,162 @@
+package orchestrator
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/golang/mock/gomock"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	"github.com/cicizeo/loran/orchestrator/cosmos"
+	"github.com/cicizeo/loran/orchestrator/signjournal"
+)
+
+func TestReserveSignature(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	key := signjournal.Key{Kind: signjournal.KindValset, GravityID: "gravity", Nonce: 5}
+
+	t.Run("no journal", func(t *testing.T) {
+		orch := &gravityOrchestrator{logger: logger}
+
+		sign, err := orch.reserveSignature(logger, key, ethcmn.HexToHash("0x01"))
+		assert.Nil(t, err)
+		assert.True(t, sign)
+
+		orch.awaitSignature(key)
+		assert.Empty(t, orch.unconfirmedSignatures)
+	})
+
+	t.Run("journal", func(t *testing.T) {
+		journal, err := signjournal.NewBadgerJournal(logger, "")
+		assert.Nil(t, err)
+		defer journal.Close()
+
+		orch := &gravityOrchestrator{logger: logger, signJournal: journal}
+
+		sign, err := orch.reserveSignature(logger, key, ethcmn.HexToHash("0x01"))
+		assert.Nil(t, err)
+		assert.True(t, sign)
+
+		// a different checkpoint for the same valset is never signed
+		sign, err = orch.reserveSignature(logger, key, ethcmn.HexToHash("0x02"))
+		assert.Nil(t, err)
+		assert.False(t, sign)
+
+		// the same checkpoint is signed again as long as our confirm isn't on Cosmos
+		sign, err = orch.reserveSignature(logger, key, ethcmn.HexToHash("0x01"))
+		assert.Nil(t, err)
+		assert.True(t, sign)
+
+		assert.Nil(t, journal.Confirm(key))
+
+		sign, err = orch.reserveSignature(logger, key, ethcmn.HexToHash("0x01"))
+		assert.Nil(t, err)
+		assert.False(t, sign)
+
+		sign, err = orch.reserveSignature(logger, key, ethcmn.HexToHash("0x02"))
+		assert.Nil(t, err)
+		assert.False(t, sign)
+	})
+}
+
+func TestConfirmSignatures(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	orchAddress := sdk.AccAddress(ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045").Bytes())
+	tokenContract := ethcmn.HexToAddress("0xdac17f958d2ee523a2206206994597c13d831ec7").Hex()
+
+	valsetKey := signjournal.Key{Kind: signjournal.KindValset, GravityID: "gravity", Nonce: 5}
+	batchKey := signjournal.Key{Kind: signjournal.KindBatch, GravityID: "gravity", Scope: tokenContract, Nonce: 3}
+
+	newOrch := func(t *testing.T, mockCtrl *gomock.Controller) (*gravityOrchestrator, *mocks.MockQueryClient) {
+		journal, err := signjournal.NewBadgerJournal(logger, "")
+		assert.Nil(t, err)
+		t.Cleanup(func() { journal.Close() })
+
+		mockCosmos := mocks.NewMockCosmosClient(mockCtrl)
+		mockCosmos.EXPECT().FromAddress().Return(orchAddress).AnyTimes()
+
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+
+		orch := &gravityOrchestrator{
+			logger:                 logger,
+			cosmosQueryClient:      mockQClient,
+			gravityBroadcastClient: cosmos.NewGravityBroadcastClient(logger, nil, mockCosmos, nil, nil, 10),
+			signJournal:            journal,
+		}
+
+		for _, key := range []signjournal.Key{valsetKey, batchKey} {
+			_, err := journal.Reserve(key, ethcmn.HexToHash("0x01"))
+			assert.Nil(t, err)
+			orch.awaitSignature(key)
+		}
+
+		return orch, mockQClient
+	}
+
+	assertConfirmed := func(t *testing.T, orch *gravityOrchestrator, key signjournal.Key, confirmed bool) {
+		entry, err := orch.signJournal.Reserve(key, ethcmn.HexToHash("0x01"))
+		assert.Nil(t, err)
+		assert.Equal(t, confirmed, entry.Confirmed)
+	}
+
+	t.Run("on chain", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockQClient := newOrch(t, mockCtrl)
+		mockQClient.EXPECT().ValsetConfirm(gomock.Any(), &types.QueryValsetConfirmRequest{
+			Nonce:   5,
+			Address: orchAddress.String(),
+		}).Return(&types.QueryValsetConfirmResponse{Confirm: &types.MsgValsetConfirm{Nonce: 5}}, nil)
+		mockQClient.EXPECT().BatchConfirms(gomock.Any(), &types.QueryBatchConfirmsRequest{
+			Nonce:           3,
+			ContractAddress: tokenContract,
+		}).Return(&types.QueryBatchConfirmsResponse{Confirms: []types.MsgConfirmBatch{
+			{Nonce: 3, Orchestrator: orchAddress.String()},
+		}}, nil)
+
+		assert.Nil(t, orch.confirmSignatures(context.Background(), logger, nil))
+		assert.Empty(t, orch.unconfirmedSignatures)
+		assertConfirmed(t, orch, valsetKey, true)
+		assertConfirmed(t, orch, batchKey, true)
+	})
+
+	t.Run("still queued", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockQClient := newOrch(t, mockCtrl)
+		mockQClient.EXPECT().ValsetConfirm(gomock.Any(), gomock.Any()).
+			Return(&types.QueryValsetConfirmResponse{}, nil)
+		mockQClient.EXPECT().BatchConfirms(gomock.Any(), gomock.Any()).
+			Return(&types.QueryBatchConfirmsResponse{Confirms: []types.MsgConfirmBatch{
+				{Nonce: 3, Orchestrator: "gravity1ahx7f8wyertuus9r20284ej0asrs085ceqtfnm"},
+			}}, nil)
+
+		// the batch is no longer pending, it won't ever be confirmed
+		pending := map[signjournal.Key]bool{valsetKey: true}
+
+		assert.Nil(t, orch.confirmSignatures(context.Background(), logger, pending))
+		assert.Equal(t, map[signjournal.Key]bool{valsetKey: true}, orch.unconfirmedSignatures)
+		assertConfirmed(t, orch, valsetKey, false)
+		assertConfirmed(t, orch, batchKey, false)
+	})
+
+	t.Run("query failure", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		orch, mockQClient := newOrch(t, mockCtrl)
+		mockQClient.EXPECT().ValsetConfirm(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused"))
+		mockQClient.EXPECT().BatchConfirms(gomock.Any(), gomock.Any()).Return(nil, errors.New("connection refused"))
+
+		assert.Nil(t, orch.confirmSignatures(context.Background(), logger, nil))
+		assert.Len(t, orch.unconfirmedSignatures, 2)
+	})
+}
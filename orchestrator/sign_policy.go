@@ -0,0 +1,40 @@
+package orchestrator
+
+import (
+	"context"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	"github.com/pkg/errors"
+)
+
+// valsetAllowed checks a valset against the signing policy, if any. It returns false if our signature must be held
+// back; the violation has already been reported by the policy engine.
+func (p *gravityOrchestrator) valsetAllowed(ctx context.Context, valset types.Valset) (bool, error) {
+	if p.signPolicy == nil {
+		return true, nil
+	}
+
+	var previous *types.Valset
+	if valset.Nonce > 1 {
+		resp, err := p.cosmosQueryClient.ValsetRequest(ctx, &types.QueryValsetRequestRequest{Nonce: valset.Nonce - 1})
+		if err != nil {
+			return false, errors.Wrapf(err, "failed to get valset %d", valset.Nonce-1)
+		}
+
+		if resp != nil {
+			previous = resp.Valset
+		}
+	}
+
+	return p.signPolicy.CheckValset(previous, valset) == nil, nil
+}
+
+// batchAllowed checks a batch against the signing policy, if any. It returns false if our signature must be held
+// back; the violation has already been reported by the policy engine.
+func (p *gravityOrchestrator) batchAllowed(batch types.OutgoingTxBatch) bool {
+	if p.signPolicy == nil {
+		return true
+	}
+
+	return p.signPolicy.CheckBatch(batch) == nil
+}
@@ -0,0 +1,111 @@
+package signjournal
+
+import (
+	"encoding/json"
+
+	badger "github.com/dgraph-io/badger/v3"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+type badgerJournal struct {
+	logger zerolog.Logger
+	db     *badger.DB
+}
+
+// NewBadgerJournal opens (or creates) a badger backed journal in dbDir. Writes are synced to disk before returning,
+// so a reservation survives a crash right after the signature is produced. If dbDir is empty the journal is kept in
+// memory.
+func NewBadgerJournal(logger zerolog.Logger, dbDir string) (Journal, error) {
+	opts := badger.DefaultOptions(dbDir).WithLogger(nil)
+	if dbDir == "" {
+		opts = opts.WithInMemory(true)
+	} else {
+		opts = opts.WithSyncWrites(true)
+	}
+
+	db, err := badger.Open(opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open signing journal db")
+	}
+
+	return &badgerJournal{
+		logger: logger.With().Str("module", "sign_journal").Logger(),
+		db:     db,
+	}, nil
+}
+
+func (j *badgerJournal) Reserve(key Key, checkpoint ethcmn.Hash) (Entry, error) {
+	var entry Entry
+
+	err := j.db.Update(func(txn *badger.Txn) error {
+		recorded, ok, err := getEntry(txn, key)
+		if err != nil {
+			return err
+		}
+
+		if ok {
+			if recorded.Checkpoint != checkpoint {
+				return &ConflictError{
+					Key:                 key,
+					SignedCheckpoint:    recorded.Checkpoint,
+					RequestedCheckpoint: checkpoint,
+				}
+			}
+
+			entry = recorded
+			return nil
+		}
+
+		entry = Entry{Checkpoint: checkpoint}
+		return setEntry(txn, key, entry)
+	})
+
+	return entry, err
+}
+
+func (j *badgerJournal) Confirm(key Key) error {
+	return j.db.Update(func(txn *badger.Txn) error {
+		entry, ok, err := getEntry(txn, key)
+		if err != nil {
+			return err
+		} else if !ok {
+			return errors.Errorf("%s was never reserved", key)
+		}
+
+		entry.Confirmed = true
+		return setEntry(txn, key, entry)
+	})
+}
+
+func (j *badgerJournal) Close() error {
+	return j.db.Close()
+}
+
+func getEntry(txn *badger.Txn, key Key) (entry Entry, ok bool, err error) {
+	item, err := txn.Get([]byte(key.String()))
+	if err == badger.ErrKeyNotFound {
+		return Entry{}, false, nil
+	} else if err != nil {
+		return Entry{}, false, err
+	}
+
+	err = item.Value(func(v []byte) error {
+		return json.Unmarshal(v, &entry)
+	})
+	if err != nil {
+		return Entry{}, false, errors.Wrapf(err, "failed to unmarshal the journal entry of %s", key)
+	}
+
+	return entry, true, nil
+}
+
+func setEntry(txn *badger.Txn, key Key, entry Entry) error {
+	value, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal journal entry")
+	}
+
+	return txn.Set([]byte(key.String()), value)
+}
@@ -0,0 +1,56 @@
+package signjournal
+
+import (
+	"os"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBadgerJournal(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+
+	journal, err := NewBadgerJournal(logger, "")
+	assert.NoError(t, err)
+	defer journal.Close()
+
+	valsetKey := Key{Kind: KindValset, GravityID: "gravity", Nonce: 5}
+	checkpoint := ethcmn.HexToHash("0x01")
+	conflictingCheckpoint := ethcmn.HexToHash("0x02")
+
+	t.Run("reserve", func(t *testing.T) {
+		entry, err := journal.Reserve(valsetKey, checkpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, Entry{Checkpoint: checkpoint}, entry)
+
+		// reserving the same checkpoint again is fine
+		entry, err = journal.Reserve(valsetKey, checkpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, Entry{Checkpoint: checkpoint}, entry)
+	})
+
+	t.Run("conflict", func(t *testing.T) {
+		_, err := journal.Reserve(valsetKey, conflictingCheckpoint)
+		assert.Equal(t, &ConflictError{
+			Key:                 valsetKey,
+			SignedCheckpoint:    checkpoint,
+			RequestedCheckpoint: conflictingCheckpoint,
+		}, err)
+
+		// the same nonce of another kind or scope is another item
+		_, err = journal.Reserve(Key{Kind: KindBatch, GravityID: "gravity", Scope: "0xabc", Nonce: 5}, conflictingCheckpoint)
+		assert.NoError(t, err)
+	})
+
+	t.Run("confirm", func(t *testing.T) {
+		assert.NoError(t, journal.Confirm(valsetKey))
+
+		entry, err := journal.Reserve(valsetKey, checkpoint)
+		assert.NoError(t, err)
+		assert.Equal(t, Entry{Checkpoint: checkpoint, Confirmed: true}, entry)
+
+		assert.Error(t, journal.Confirm(Key{Kind: KindValset, GravityID: "gravity", Nonce: 6}))
+	})
+}
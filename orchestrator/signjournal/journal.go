@@ -0,0 +1,69 @@
+package signjournal
+
+import (
+	"fmt"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+)
+
+// Kind is the kind of Gravity item our Ethereum key signs a checkpoint of.
+type Kind string
+
+const (
+	KindValset    Kind = "valset"
+	KindBatch     Kind = "batch"
+	KindLogicCall Kind = "logic_call"
+)
+
+// Key identifies a signed item. Scope tells apart the items whose nonce is not unique on its own: the token contract
+// of a batch or the invalidation ID of a logic call. It is empty for valsets.
+type Key struct {
+	Kind      Kind
+	GravityID string
+	Scope     string
+	Nonce     uint64
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s/%s/%d", k.Kind, k.GravityID, k.Scope, k.Nonce)
+}
+
+// Entry is what the journal remembers about a signed item.
+type Entry struct {
+	Checkpoint ethcmn.Hash `json:"checkpoint"`
+
+	// Confirmed is true once Cosmos has our confirm of the item on chain.
+	Confirmed bool `json:"confirmed"`
+}
+
+// ConflictError is returned when we are asked to sign a checkpoint for an item we already signed a different
+// checkpoint of. Signing both would be a double sign.
+type ConflictError struct {
+	Key                 Key
+	SignedCheckpoint    ethcmn.Hash
+	RequestedCheckpoint ethcmn.Hash
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf(
+		"refusing to sign checkpoint %s for %s: checkpoint %s was already signed",
+		e.RequestedCheckpoint.Hex(),
+		e.Key,
+		e.SignedCheckpoint.Hex(),
+	)
+}
+
+// Journal is a persistent record of every checkpoint our Ethereum key signed, so it never signs two different
+// checkpoints for the same item, even across restarts.
+type Journal interface {
+	// Reserve records that we are about to sign checkpoint for the item identified by key, before the signature is
+	// produced. It returns a *ConflictError if a different checkpoint was already recorded for it. Reserving the same
+	// checkpoint again is allowed, as it gives the same signature; the returned entry tells whether it was already
+	// confirmed.
+	Reserve(key Key, checkpoint ethcmn.Hash) (Entry, error)
+
+	// Confirm marks the item as confirmed on Cosmos.
+	Confirm(key Key) error
+
+	Close() error
+}
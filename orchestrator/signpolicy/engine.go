@@ -0,0 +1,342 @@
+package signpolicy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Rules of the signing policy.
+const (
+	RuleAllowedTokens        = "allowed_tokens"
+	RuleDeniedDestinations   = "denied_destinations"
+	RuleMaxBatchValue        = "max_batch_value"
+	RuleMaxHourlyValue       = "max_hourly_value"
+	RuleMaxValsetPowerChange = "max_valset_power_change"
+	RuleValsetMembership     = "valset_membership"
+)
+
+// powerThreshold is a mirror of constant_powerThreshold in Gravity.sol. A valset whose members don't add up to it can
+// never sign anything on Ethereum again.
+const powerThreshold uint64 = 2863311530
+
+// valueAtRiskWindow is the window MaxHourlyValue applies to.
+const valueAtRiskWindow = time.Hour
+
+// Violation describes why an item must not be signed.
+type Violation struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	Nonce  uint64    `json:"nonce"`
+	Rule   string    `json:"rule"`
+	Reason string    `json:"reason"`
+}
+
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s %d violates the %s rule: %s", v.Kind, v.Nonce, v.Rule, v.Reason)
+}
+
+type signedValue struct {
+	token  ethcmn.Address
+	nonce  uint64
+	amount *big.Int
+	time   time.Time
+}
+
+// Engine checks batches and valsets against a signing policy before they are signed. The value signed over the last
+// hour is only kept in memory, so MaxHourlyValue starts counting from zero again after a restart.
+type Engine struct {
+	logger zerolog.Logger
+	policy Policy
+	now    func() time.Time
+
+	mtx      sync.Mutex
+	signed   []signedValue
+	reported map[string]bool
+}
+
+func NewEngine(logger zerolog.Logger, policy Policy) *Engine {
+	return &Engine{
+		logger:   logger.With().Str("module", "sign_policy").Logger(),
+		policy:   policy,
+		now:      time.Now,
+		reported: map[string]bool{},
+	}
+}
+
+// CheckBatch returns a *Violation if the batch must not be signed.
+func (e *Engine) CheckBatch(batch types.OutgoingTxBatch) error {
+	violation := e.checkBatch(batch)
+	if violation == nil {
+		return nil
+	}
+
+	violation.Kind, violation.Nonce = "batch", batch.BatchNonce
+	return e.report(violation)
+}
+
+func (e *Engine) checkBatch(batch types.OutgoingTxBatch) *Violation {
+	token := ethcmn.HexToAddress(batch.TokenContract)
+
+	if len(e.policy.AllowedTokens) > 0 && !e.policy.AllowedTokens[token] {
+		return &Violation{Rule: RuleAllowedTokens, Reason: fmt.Sprintf("token %s is not allowed", token.Hex())}
+	}
+
+	for _, tx := range batch.Transactions {
+		if e.policy.DeniedDestinations[ethcmn.HexToAddress(tx.DestAddress)] {
+			return &Violation{
+				Rule:   RuleDeniedDestinations,
+				Reason: fmt.Sprintf("transfer %d is sent to denied address %s", tx.Id, tx.DestAddress),
+			}
+		}
+	}
+
+	limits, ok := e.policy.TokenLimits[token]
+	if !ok {
+		return nil
+	}
+
+	value := batchValue(batch)
+
+	if limits.MaxBatchValue != nil && value.Cmp(limits.MaxBatchValue) > 0 {
+		return &Violation{
+			Rule:   RuleMaxBatchValue,
+			Reason: fmt.Sprintf("batch value %s is over %s", value, limits.MaxBatchValue),
+		}
+	}
+
+	if limits.MaxHourlyValue != nil {
+		atRisk := new(big.Int).Add(e.hourlyValue(token, batch.BatchNonce), value)
+		if atRisk.Cmp(limits.MaxHourlyValue) > 0 {
+			return &Violation{
+				Rule:   RuleMaxHourlyValue,
+				Reason: fmt.Sprintf("value signed over the last hour would be %s, over %s", atRisk, limits.MaxHourlyValue),
+			}
+		}
+	}
+
+	return nil
+}
+
+// RecordBatch adds the value of a signed batch to the value at risk. A batch signed again, e.g. because our first
+// confirm never made it to Cosmos, is only counted once.
+func (e *Engine) RecordBatch(batch types.OutgoingTxBatch) {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	token := ethcmn.HexToAddress(batch.TokenContract)
+	for _, v := range e.signed {
+		if v.token == token && v.nonce == batch.BatchNonce {
+			return
+		}
+	}
+
+	e.signed = append(e.signed, signedValue{
+		token:  token,
+		nonce:  batch.BatchNonce,
+		amount: batchValue(batch),
+		time:   e.now(),
+	})
+}
+
+// hourlyValue returns the value of the token signed over the last hour, leaving out the batch being checked in case
+// it was already signed.
+func (e *Engine) hourlyValue(token ethcmn.Address, nonce uint64) *big.Int {
+	e.mtx.Lock()
+	defer e.mtx.Unlock()
+
+	since := e.now().Add(-valueAtRiskWindow)
+
+	// forget what went out of the window
+	i := 0
+	for i < len(e.signed) && e.signed[i].time.Before(since) {
+		i++
+	}
+	e.signed = e.signed[i:]
+
+	total := new(big.Int)
+	for _, v := range e.signed {
+		if v.token == token && v.nonce != nonce {
+			total.Add(total, v.amount)
+		}
+	}
+
+	return total
+}
+
+// CheckValset returns a *Violation if the valset must not be signed. The previous valset is nil if unknown, in which
+// case only the membership of the new one is checked.
+func (e *Engine) CheckValset(previous *types.Valset, valset types.Valset) error {
+	violation := checkValsetMembership(valset)
+	if violation == nil && previous != nil && e.policy.MaxValsetPowerChange > 0 {
+		if change := powerChange(*previous, valset); change > e.policy.MaxValsetPowerChange {
+			violation = &Violation{
+				Rule: RuleMaxValsetPowerChange,
+				Reason: fmt.Sprintf(
+					"%.2f%% of the power changed since valset %d, over %.2f%%",
+					change*100,
+					previous.Nonce,
+					e.policy.MaxValsetPowerChange*100,
+				),
+			}
+		}
+	}
+
+	if violation == nil {
+		return nil
+	}
+
+	violation.Kind, violation.Nonce = "valset", valset.Nonce
+	return e.report(violation)
+}
+
+func checkValsetMembership(valset types.Valset) *Violation {
+	if len(valset.Members) == 0 {
+		return &Violation{Rule: RuleValsetMembership, Reason: "valset has no members"}
+	}
+
+	var (
+		total uint64
+		seen  = map[ethcmn.Address]bool{}
+	)
+
+	for _, m := range valset.Members {
+		if !ethcmn.IsHexAddress(m.EthereumAddress) {
+			return &Violation{
+				Rule:   RuleValsetMembership,
+				Reason: fmt.Sprintf("invalid member address %q", m.EthereumAddress),
+			}
+		}
+
+		address := ethcmn.HexToAddress(m.EthereumAddress)
+		if seen[address] {
+			return &Violation{
+				Rule:   RuleValsetMembership,
+				Reason: fmt.Sprintf("member %s appears twice", address.Hex()),
+			}
+		}
+		seen[address] = true
+
+		if m.Power == 0 {
+			return &Violation{
+				Rule:   RuleValsetMembership,
+				Reason: fmt.Sprintf("member %s has no power", address.Hex()),
+			}
+		}
+
+		total += m.Power
+	}
+
+	if total < powerThreshold {
+		return &Violation{
+			Rule:   RuleValsetMembership,
+			Reason: fmt.Sprintf("total power %d is under the %d needed to sign anything", total, powerThreshold),
+		}
+	}
+
+	return nil
+}
+
+// powerChange returns the share of the total power that moved from one valset to the next one.
+func powerChange(previous, valset types.Valset) float64 {
+	powers := map[ethcmn.Address]int64{}
+
+	var previousTotal, total uint64
+	for _, m := range previous.Members {
+		powers[ethcmn.HexToAddress(m.EthereumAddress)] -= int64(m.Power)
+		previousTotal += m.Power
+	}
+
+	for _, m := range valset.Members {
+		powers[ethcmn.HexToAddress(m.EthereumAddress)] += int64(m.Power)
+		total += m.Power
+	}
+
+	if previousTotal > total {
+		total = previousTotal
+	}
+
+	if total == 0 {
+		return 0
+	}
+
+	var moved uint64
+	for _, diff := range powers {
+		if diff < 0 {
+			diff = -diff
+		}
+		moved += uint64(diff)
+	}
+
+	// every unit of power that moved is counted once where it left and once where it went
+	return float64(moved) / 2 / float64(total)
+}
+
+// report raises an alert the first time an item violates the policy and records the violation.
+func (e *Engine) report(violation *Violation) error {
+	violation.Time = e.now()
+
+	e.mtx.Lock()
+	key := fmt.Sprintf("%s/%d/%s", violation.Kind, violation.Nonce, violation.Rule)
+	alreadyReported := e.reported[key]
+	e.reported[key] = true
+	e.mtx.Unlock()
+
+	if alreadyReported {
+		return violation
+	}
+
+	e.logger.Error().
+		Str("kind", violation.Kind).
+		Uint64("nonce", violation.Nonce).
+		Str("rule", violation.Rule).
+		Str("reason", violation.Reason).
+		Msg("SIGNING POLICY VIOLATION: holding back our signature")
+
+	if e.policy.ViolationsFile != "" {
+		if err := appendViolation(e.policy.ViolationsFile, violation); err != nil {
+			e.logger.Err(err).Str("file", e.policy.ViolationsFile).Msg("failed to record the policy violation")
+		}
+	}
+
+	return violation
+}
+
+func appendViolation(path string, violation *Violation) error {
+	line, err := json.Marshal(violation)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal violation")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func batchValue(batch types.OutgoingTxBatch) *big.Int {
+	total := new(big.Int)
+
+	for _, tx := range batch.Transactions {
+		if !tx.Erc20Token.Amount.IsNil() {
+			total.Add(total, tx.Erc20Token.Amount.BigInt())
+		}
+		if !tx.Erc20Fee.Amount.IsNil() {
+			total.Add(total, tx.Erc20Fee.Amount.BigInt())
+		}
+	}
+
+	return total
+}
@@ -0,0 +1,156 @@
+package signpolicy
+
+import (
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckBatch(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	token := ethcmn.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+	denied := ethcmn.HexToAddress("0x000000000000000000000000000000000000dEaD")
+
+	newBatch := func(nonce uint64, tokenContract ethcmn.Address, dest ethcmn.Address, amount int64) types.OutgoingTxBatch {
+		return types.OutgoingTxBatch{
+			BatchNonce:    nonce,
+			TokenContract: tokenContract.Hex(),
+			Transactions: []types.OutgoingTransferTx{{
+				Id:          1,
+				DestAddress: dest.Hex(),
+				Erc20Token:  types.ERC20Token{Contract: tokenContract.Hex(), Amount: sdk.NewInt(amount)},
+				Erc20Fee:    types.ERC20Token{Contract: tokenContract.Hex(), Amount: sdk.NewInt(10)},
+			}},
+		}
+	}
+
+	newEngine := func() *Engine {
+		return NewEngine(logger, Policy{
+			AllowedTokens:      map[ethcmn.Address]bool{token: true},
+			DeniedDestinations: map[ethcmn.Address]bool{denied: true},
+			TokenLimits: map[ethcmn.Address]TokenLimits{token: {
+				MaxBatchValue:  big.NewInt(1000),
+				MaxHourlyValue: big.NewInt(1500),
+			}},
+		})
+	}
+
+	dest := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+	t.Run("ok", func(t *testing.T) {
+		assert.Nil(t, newEngine().CheckBatch(newBatch(1, token, dest, 990)))
+	})
+
+	t.Run("token not allowed", func(t *testing.T) {
+		err := newEngine().CheckBatch(newBatch(1, dest, dest, 10))
+		violation, ok := err.(*Violation)
+		assert.True(t, ok)
+		assert.Equal(t, RuleAllowedTokens, violation.Rule)
+	})
+
+	t.Run("denied destination", func(t *testing.T) {
+		err := newEngine().CheckBatch(newBatch(1, token, denied, 10))
+		violation, ok := err.(*Violation)
+		assert.True(t, ok)
+		assert.Equal(t, RuleDeniedDestinations, violation.Rule)
+	})
+
+	t.Run("batch value", func(t *testing.T) {
+		err := newEngine().CheckBatch(newBatch(1, token, dest, 991))
+		assert.EqualError(t, err, "batch 1 violates the max_batch_value rule: batch value 1001 is over 1000")
+	})
+
+	t.Run("hourly value", func(t *testing.T) {
+		now := time.Now()
+		engine := newEngine()
+		engine.now = func() time.Time { return now }
+
+		engine.RecordBatch(newBatch(1, token, dest, 990))
+
+		err := engine.CheckBatch(newBatch(2, token, dest, 990))
+		assert.EqualError(
+			t,
+			err,
+			"batch 2 violates the max_hourly_value rule: value signed over the last hour would be 2000, over 1500",
+		)
+
+		// an hour later the first batch is no longer at risk
+		now = now.Add(time.Hour + time.Second)
+		assert.Nil(t, engine.CheckBatch(newBatch(2, token, dest, 990)))
+	})
+	t.Run("batch signed again", func(t *testing.T) {
+		engine := newEngine()
+
+		engine.RecordBatch(newBatch(1, token, dest, 690))
+		engine.RecordBatch(newBatch(1, token, dest, 690))
+		assert.Nil(t, engine.CheckBatch(newBatch(1, token, dest, 690)))
+		assert.Nil(t, engine.CheckBatch(newBatch(2, token, dest, 690)))
+	})
+}
+
+func TestCheckValset(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	engine := NewEngine(logger, Policy{MaxValsetPowerChange: 0.25})
+
+	member := func(address string, power uint64) types.BridgeValidator {
+		return types.BridgeValidator{EthereumAddress: address, Power: power}
+	}
+
+	const (
+		a = "0x0000000000000000000000000000000000000001"
+		b = "0x0000000000000000000000000000000000000002"
+		c = "0x0000000000000000000000000000000000000003"
+	)
+
+	previous := types.Valset{Nonce: 1, Members: []types.BridgeValidator{
+		member(a, 2147483648),
+		member(b, 2147483648),
+	}}
+
+	t.Run("ok", func(t *testing.T) {
+		assert.Nil(t, engine.CheckValset(&previous, types.Valset{Nonce: 2, Members: []types.BridgeValidator{
+			member(a, 2147483648+429496729),
+			member(b, 2147483648-429496729),
+		}}))
+	})
+
+	t.Run("power change", func(t *testing.T) {
+		err := engine.CheckValset(&previous, types.Valset{Nonce: 3, Members: []types.BridgeValidator{
+			member(a, 2147483648),
+			member(c, 2147483648),
+		}})
+		assert.EqualError(
+			t,
+			err,
+			"valset 3 violates the max_valset_power_change rule: 50.00% of the power changed since valset 1, over 25.00%",
+		)
+	})
+
+	t.Run("duplicate member", func(t *testing.T) {
+		err := engine.CheckValset(nil, types.Valset{Nonce: 4, Members: []types.BridgeValidator{
+			member(a, 2147483648),
+			member(a, 2147483648),
+		}})
+		violation, ok := err.(*Violation)
+		assert.True(t, ok)
+		assert.Equal(t, RuleValsetMembership, violation.Rule)
+	})
+
+	t.Run("not enough power", func(t *testing.T) {
+		err := engine.CheckValset(nil, types.Valset{Nonce: 5, Members: []types.BridgeValidator{
+			member(a, 1000),
+		}})
+		assert.EqualError(
+			t,
+			err,
+			"valset 5 violates the valset_membership rule: total power 1000 is under the 2863311530 needed to sign anything",
+		)
+	})
+}
@@ -0,0 +1,160 @@
+package signpolicy
+
+import (
+	"math/big"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/knadh/koanf"
+	"github.com/knadh/koanf/parsers/toml"
+	"github.com/knadh/koanf/providers/file"
+	"github.com/pkg/errors"
+)
+
+// TokenLimits are the value limits of a token, in its smallest unit. A nil limit is not enforced.
+type TokenLimits struct {
+	// MaxBatchValue is the maximum value (amounts plus fees) of a single batch.
+	MaxBatchValue *big.Int
+
+	// MaxHourlyValue is the maximum value of all the batches signed over the last hour, i.e. the value at risk if
+	// the Cosmos state turned out to be malicious.
+	MaxHourlyValue *big.Int
+}
+
+// Policy is the set of rules a batch or a valset must follow before our Ethereum key signs it.
+type Policy struct {
+	// AllowedTokens is the list of tokens we sign batches of. If empty, any token is allowed.
+	AllowedTokens map[ethcmn.Address]bool
+
+	// DeniedDestinations is the list of addresses we never sign a transfer to.
+	DeniedDestinations map[ethcmn.Address]bool
+
+	// TokenLimits are the value limits per token.
+	TokenLimits map[ethcmn.Address]TokenLimits
+
+	// MaxValsetPowerChange is the maximum share of the total power (between 0 and 1) that can move between
+	// consecutive valsets. Zero disables the rule.
+	MaxValsetPowerChange float64
+
+	// ViolationsFile is the (optional) file the violations are appended to, one JSON object per line.
+	ViolationsFile string
+}
+
+type policyConfig struct {
+	AllowedTokens        []string `koanf:"allowed_tokens"`
+	DeniedDestinations   []string `koanf:"denied_destinations"`
+	MaxValsetPowerChange float64  `koanf:"max_valset_power_change"`
+	ViolationsFile       string   `koanf:"violations_file"`
+}
+
+type tokenLimitsConfig struct {
+	MaxBatchValue  string `koanf:"max_batch_value"`
+	MaxHourlyValue string `koanf:"max_hourly_value"`
+}
+
+// LoadPolicy loads a signing policy from a TOML file. For instance:
+//
+//	allowed_tokens = ["0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"]
+//	denied_destinations = ["0x000000000000000000000000000000000000dEaD"]
+//	max_valset_power_change = 0.25
+//	violations_file = "/var/lib/loran/policy-violations.jsonl"
+//
+//	[tokens.0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48]
+//	max_batch_value = "1000000000000"
+//	max_hourly_value = "5000000000000"
+func LoadPolicy(path string) (Policy, error) {
+	k := koanf.New(".")
+	if err := k.Load(file.Provider(path), toml.Parser()); err != nil {
+		return Policy{}, errors.Wrap(err, "failed to load signing policy")
+	}
+
+	var cfg policyConfig
+	if err := k.Unmarshal("", &cfg); err != nil {
+		return Policy{}, errors.Wrap(err, "failed to parse signing policy")
+	}
+
+	if cfg.MaxValsetPowerChange < 0 || cfg.MaxValsetPowerChange > 1 {
+		return Policy{}, errors.Errorf(
+			"max_valset_power_change must be between 0 and 1, got %v",
+			cfg.MaxValsetPowerChange,
+		)
+	}
+
+	policy := Policy{
+		TokenLimits:          map[ethcmn.Address]TokenLimits{},
+		MaxValsetPowerChange: cfg.MaxValsetPowerChange,
+		ViolationsFile:       cfg.ViolationsFile,
+	}
+
+	var err error
+	if policy.AllowedTokens, err = parseAddresses(cfg.AllowedTokens); err != nil {
+		return Policy{}, errors.Wrap(err, "invalid allowed_tokens")
+	}
+
+	if policy.DeniedDestinations, err = parseAddresses(cfg.DeniedDestinations); err != nil {
+		return Policy{}, errors.Wrap(err, "invalid denied_destinations")
+	}
+
+	for _, key := range k.MapKeys("tokens") {
+		if !ethcmn.IsHexAddress(key) {
+			return Policy{}, errors.Errorf("invalid token address in signing policy: %s", key)
+		}
+
+		var limitsCfg tokenLimitsConfig
+		if err := k.Unmarshal("tokens."+key, &limitsCfg); err != nil {
+			return Policy{}, errors.Wrapf(err, "failed to parse the limits of token %s", key)
+		}
+
+		limits, err := parseTokenLimits(limitsCfg)
+		if err != nil {
+			return Policy{}, errors.Wrapf(err, "invalid limits for token %s", key)
+		}
+
+		policy.TokenLimits[ethcmn.HexToAddress(key)] = limits
+	}
+
+	return policy, nil
+}
+
+func parseAddresses(addresses []string) (map[ethcmn.Address]bool, error) {
+	res := map[ethcmn.Address]bool{}
+
+	for _, address := range addresses {
+		if !ethcmn.IsHexAddress(address) {
+			return nil, errors.Errorf("invalid address: %s", address)
+		}
+
+		res[ethcmn.HexToAddress(address)] = true
+	}
+
+	return res, nil
+}
+
+func parseTokenLimits(cfg tokenLimitsConfig) (TokenLimits, error) {
+	var (
+		limits TokenLimits
+		err    error
+	)
+
+	if limits.MaxBatchValue, err = parseAmount(cfg.MaxBatchValue); err != nil {
+		return TokenLimits{}, errors.Wrap(err, "invalid max_batch_value")
+	}
+
+	if limits.MaxHourlyValue, err = parseAmount(cfg.MaxHourlyValue); err != nil {
+		return TokenLimits{}, errors.Wrap(err, "invalid max_hourly_value")
+	}
+
+	return limits, nil
+}
+
+func parseAmount(amount string) (*big.Int, error) {
+	if amount == "" {
+		return nil, nil
+	}
+
+	v, ok := new(big.Int).SetString(amount, 10)
+	if !ok || v.Sign() < 0 {
+		return nil, errors.Errorf("not a positive integer: %s", amount)
+	}
+
+	return v, nil
+}
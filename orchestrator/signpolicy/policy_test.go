@@ -0,0 +1,71 @@
+package signpolicy
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadPolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "signpolicy")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	writePolicy := func(content string) string {
+		path := filepath.Join(dir, "policy.toml")
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), 0600))
+		return path
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		policy, err := LoadPolicy(writePolicy(`
+allowed_tokens = ["0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48"]
+denied_destinations = ["0x000000000000000000000000000000000000dEaD"]
+max_valset_power_change = 0.25
+
+[tokens.0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48]
+max_batch_value = "1000"
+max_hourly_value = "5000"
+`))
+		assert.NoError(t, err)
+
+		token := ethcmn.HexToAddress("0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48")
+		assert.Equal(t, map[ethcmn.Address]bool{token: true}, policy.AllowedTokens)
+		assert.Equal(t, map[ethcmn.Address]bool{
+			ethcmn.HexToAddress("0x000000000000000000000000000000000000dEaD"): true,
+		}, policy.DeniedDestinations)
+		assert.Equal(t, 0.25, policy.MaxValsetPowerChange)
+		assert.Equal(t, map[ethcmn.Address]TokenLimits{token: {
+			MaxBatchValue:  big.NewInt(1000),
+			MaxHourlyValue: big.NewInt(5000),
+		}}, policy.TokenLimits)
+	})
+
+	t.Run("invalid address", func(t *testing.T) {
+		_, err := LoadPolicy(writePolicy(`denied_destinations = ["0xdead"]`))
+		assert.EqualError(t, err, "invalid denied_destinations: invalid address: 0xdead")
+	})
+
+	t.Run("invalid power change", func(t *testing.T) {
+		_, err := LoadPolicy(writePolicy(`max_valset_power_change = 2.0`))
+		assert.EqualError(t, err, "max_valset_power_change must be between 0 and 1, got 2")
+	})
+
+	t.Run("invalid limit", func(t *testing.T) {
+		_, err := LoadPolicy(writePolicy(`
+[tokens.0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48]
+max_batch_value = "lots"
+`))
+		assert.EqualError(
+			t,
+			err,
+			"invalid limits for token 0xa0b86991c6218b36c1d19d4a2e9eb0ce3606eb48: invalid max_batch_value: "+
+				"not a positive integer: lots",
+		)
+	})
+}
@@ -0,0 +1,82 @@
+package watchtower
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Kinds of alerts raised by the watchtower.
+const (
+	// AlertForeignValset is raised when the Gravity contract has a valset the Cosmos chain didn't produce.
+	AlertForeignValset = "foreign_valset"
+
+	// AlertUnknownBatch is raised when a batch the Cosmos chain never created is executed on Ethereum.
+	AlertUnknownBatch = "unknown_batch"
+
+	// AlertUnknownEvent is raised when the Cosmos chain observed an event nonce the Gravity contract never reached.
+	AlertUnknownEvent = "unknown_event"
+
+	// AlertAttestationStall is raised when the Cosmos chain stopped observing the events of the Gravity contract.
+	AlertAttestationStall = "attestation_stall"
+)
+
+// webhookTimeout bounds the time spent posting an alert to the webhook.
+const webhookTimeout = 10 * time.Second
+
+// Alert describes something wrong the watchtower found on the bridge.
+type Alert struct {
+	Time   time.Time `json:"time"`
+	Kind   string    `json:"kind"`
+	Nonce  uint64    `json:"nonce"`
+	Reason string    `json:"reason"`
+}
+
+func appendAlert(path string, alert *Alert) error {
+	line, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal alert")
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
+
+func postAlert(ctx context.Context, url string, alert *Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal alert")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, webhookTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return errors.Errorf("webhook returned status %s", resp.Status)
+	}
+
+	return nil
+}
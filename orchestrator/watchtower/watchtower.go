@@ -0,0 +1,444 @@
+package watchtower
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	"github.com/ethereum/go-ethereum"
+	multierror "github.com/hashicorp/go-multierror"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/cicizeo/loran/orchestrator/blockrange"
+	gravity "github.com/cicizeo/loran/orchestrator/ethereum/gravity"
+	"github.com/cicizeo/loran/orchestrator/ethereum/provider"
+	"github.com/cicizeo/loran/orchestrator/eventindex"
+	"github.com/cicizeo/loran/orchestrator/finality"
+	"github.com/cicizeo/loran/orchestrator/loops"
+	"github.com/cicizeo/loran/orchestrator/relayer"
+	wrappers "github.com/cicizeo/loran/solwrappers/Gravity.sol"
+)
+
+// attestationsLimit is the number of attestations looked at to find the last event nonce observed by Cosmos. This is
+// the maximum the Gravity module returns.
+const attestationsLimit = 1000
+
+// Watchtower compares the state of the Gravity contract with what the Cosmos chain attests, and raises an alert when
+// they disagree. It only needs read access to both chains, so it can be run by anyone.
+type Watchtower interface {
+	Start(ctx context.Context) error
+
+	// Check runs every check once, scanning the events emitted on Ethereum since the last call.
+	Check(ctx context.Context) error
+
+	// SetAlertsFile sets the (optional) file the alerts are appended to, one JSON object per line.
+	SetAlertsFile(path string)
+
+	// SetAlertWebhook sets the (optional) URL every alert is posted to as a JSON object.
+	SetAlertWebhook(url string)
+}
+
+type gravityWatchtower struct {
+	logger            zerolog.Logger
+	cosmosQueryClient types.QueryClient
+	claimUnpacker     codectypes.AnyUnpacker
+	gravityContract   gravity.Contract
+	ethProvider       provider.EVMProvider
+	relayer           relayer.GravityRelayer
+	finalityStrategy  finality.Strategy
+	blockRange        *blockrange.Sizer
+	loopDuration      time.Duration
+	stallTimeout      time.Duration
+	alertsFile        string
+	alertWebhook      string
+	now               func() time.Time
+
+	lastCheckedBlock  uint64
+	highestBatchNonce uint64
+
+	// lastObservedNonce is the last event nonce observed by Cosmos. behindSince is when Cosmos was first seen behind
+	// the contract without observing anything new since, zero while it is up to date.
+	lastObservedNonce uint64
+	behindSince       time.Time
+
+	reported map[string]bool
+}
+
+func NewWatchtower(
+	logger zerolog.Logger,
+	cosmosQueryClient types.QueryClient,
+	claimUnpacker codectypes.AnyUnpacker,
+	gravityContract gravity.Contract,
+	relayer relayer.GravityRelayer,
+	finalityStrategy finality.Strategy,
+	loopDuration time.Duration,
+	ethBlocksPerLoop int64,
+	stallTimeout time.Duration,
+	options ...func(Watchtower),
+) Watchtower {
+	watchtower := &gravityWatchtower{
+		logger:            logger.With().Str("module", "watchtower").Logger(),
+		cosmosQueryClient: cosmosQueryClient,
+		claimUnpacker:     claimUnpacker,
+		gravityContract:   gravityContract,
+		ethProvider:       gravityContract.Provider(),
+		relayer:           relayer,
+		finalityStrategy:  finalityStrategy,
+		blockRange:        blockrange.NewSizer(logger, uint64(ethBlocksPerLoop)),
+		loopDuration:      loopDuration,
+		stallTimeout:      stallTimeout,
+		now:               time.Now,
+		reported:          make(map[string]bool),
+	}
+
+	for _, option := range options {
+		option(watchtower)
+	}
+
+	return watchtower
+}
+
+func SetAlertsFile(path string) func(Watchtower) {
+	return func(w Watchtower) { w.SetAlertsFile(path) }
+}
+
+func (w *gravityWatchtower) SetAlertsFile(path string) {
+	w.alertsFile = path
+}
+
+func SetAlertWebhook(url string) func(Watchtower) {
+	return func(w Watchtower) { w.SetAlertWebhook(url) }
+}
+
+func (w *gravityWatchtower) SetAlertWebhook(url string) {
+	w.alertWebhook = url
+}
+
+// Start watches the bridge from the latest final block on, until the context is done. Failing checks are logged and
+// retried on the next loop, the watchtower never stops on its own.
+func (w *gravityWatchtower) Start(ctx context.Context) error {
+	if err := w.init(ctx); err != nil {
+		return err
+	}
+
+	w.logger.Info().
+		Uint64("start_block", w.lastCheckedBlock).
+		Uint64("highest_batch_nonce", w.highestBatchNonce).
+		Stringer("finality", w.finalityStrategy).
+		Msg("watching the bridge")
+
+	return loops.RunLoop(ctx, w.logger, w.loopDuration, func() error {
+		if err := w.Check(ctx); err != nil {
+			w.logger.Err(err).Msg("failed to check the bridge; retrying on the next loop")
+		}
+
+		return nil
+	})
+}
+
+// init starts the watch at the latest final block. The batches executed before aren't checked, so the batch nonces
+// Cosmos already knows of are the ones pending or claimed executed.
+func (w *gravityWatchtower) init(ctx context.Context) error {
+	latestBlock, err := w.finalityStrategy.LatestFinalBlock(ctx, w.ethProvider)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the latest final block")
+	}
+
+	w.lastCheckedBlock = latestBlock
+
+	claims, err := w.observedClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, claim := range claims {
+		if batchClaim, ok := claim.(*types.MsgBatchSendToEthClaim); ok && batchClaim.BatchNonce > w.highestBatchNonce {
+			w.highestBatchNonce = batchClaim.BatchNonce
+		}
+	}
+
+	return nil
+}
+
+func (w *gravityWatchtower) Check(ctx context.Context) error {
+	var result *multierror.Error
+
+	if err := w.checkLatestValset(ctx); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	if err := w.refreshBatchNonces(ctx); err != nil {
+		result = multierror.Append(result, err)
+	} else if err := w.checkEvents(ctx); err != nil {
+		// Without the batch nonces known to Cosmos, every executed batch would look unknown.
+		result = multierror.Append(result, err)
+	}
+
+	if err := w.checkEventNonces(ctx); err != nil {
+		result = multierror.Append(result, err)
+	}
+
+	return result.ErrorOrNil()
+}
+
+// checkLatestValset compares the current valset of the Gravity contract with the one Cosmos has for its nonce.
+func (w *gravityWatchtower) checkLatestValset(ctx context.Context) error {
+	valset, err := w.relayer.FindLatestValset(ctx)
+	if err == relayer.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return errors.Wrap(err, "failed to find the latest valset on Ethereum")
+	}
+
+	return w.checkValset(ctx, valset)
+}
+
+// checkValset compares a valset found on Ethereum with the one Cosmos has for its nonce. Old valsets may be pruned, so
+// a missing one below the latest nonce of Cosmos is not a foreign valset.
+func (w *gravityWatchtower) checkValset(ctx context.Context, valset *types.Valset) error {
+	resp, err := w.cosmosQueryClient.ValsetRequest(ctx, &types.QueryValsetRequestRequest{Nonce: valset.Nonce})
+	if err != nil {
+		return errors.Wrapf(err, "failed to get cosmos valset %d", valset.Nonce)
+	}
+
+	var cosmosValset *types.Valset
+	if resp != nil {
+		cosmosValset = resp.Valset
+	}
+
+	if cosmosValset == nil {
+		latest, err := w.cosmosQueryClient.LastValsetRequests(ctx, &types.QueryLastValsetRequestsRequest{})
+		if err != nil {
+			return errors.Wrap(err, "failed to get the latest cosmos valsets")
+		}
+
+		for _, v := range latest.Valsets {
+			if v.Nonce >= valset.Nonce {
+				w.logger.Debug().Uint64("valset_nonce", valset.Nonce).Msg("valset pruned, can't compare it")
+				return nil
+			}
+		}
+	}
+
+	if err := w.relayer.CheckValset(cosmosValset, valset); errors.Is(err, relayer.ErrValsetsDiffer) {
+		w.alert(ctx, AlertForeignValset, valset.Nonce, err.Error())
+	} else if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// refreshBatchNonces records the highest batch nonce Cosmos created so far. Batch nonces are shared by all tokens
+// and only grow, so an executed batch above it was never created by Cosmos.
+func (w *gravityWatchtower) refreshBatchNonces(ctx context.Context) error {
+	resp, err := w.cosmosQueryClient.OutgoingTxBatches(ctx, &types.QueryOutgoingTxBatchesRequest{})
+	if err != nil {
+		return errors.Wrap(err, "failed to get the outgoing batches")
+	}
+
+	if resp == nil {
+		return nil
+	}
+
+	for _, batch := range resp.Batches {
+		if batch.BatchNonce > w.highestBatchNonce {
+			w.highestBatchNonce = batch.BatchNonce
+		}
+	}
+
+	return nil
+}
+
+// checkEvents scans the valset updates and batches executed on Ethereum since the last checked block.
+func (w *gravityWatchtower) checkEvents(ctx context.Context) error {
+	currentBlock, err := w.finalityStrategy.LatestFinalBlock(ctx, w.ethProvider)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the latest final block")
+	}
+
+	if currentBlock <= w.lastCheckedBlock {
+		return nil
+	}
+
+	startingBlock := w.lastCheckedBlock + 1
+	if blocksPerLoop := w.blockRange.Size(); currentBlock-startingBlock > blocksPerLoop {
+		currentBlock = startingBlock + blocksPerLoop
+	}
+
+	gravityFilterer, err := wrappers.NewGravityFilterer(w.gravityContract.Address(), w.ethProvider)
+	if err != nil {
+		return errors.Wrap(err, "failed to init Gravity events filterer")
+	}
+
+	logs, err := w.blockRange.FilterLogs(ctx, w.ethProvider, func(fromBlock, toBlock uint64) ethereum.FilterQuery {
+		return eventindex.FilterQuery(w.gravityContract.Address(), fromBlock, toBlock)
+	}, startingBlock, currentBlock)
+	if err != nil {
+		return errors.Wrap(err, "failed to scan past Gravity events from Ethereum")
+	}
+
+	events, err := eventindex.DecodeEvents(gravityFilterer, logs)
+	if err != nil {
+		return err
+	}
+
+	for _, ev := range events.ValsetUpdated {
+		if ev.NewValsetNonce.Uint64() == 0 {
+			// bootstrapping valset, set when the contract was deployed
+			continue
+		}
+
+		if err := w.checkValset(ctx, relayer.ValsetFromEvent(ev)); err != nil {
+			return err
+		}
+	}
+
+	for _, ev := range events.TransactionBatchExecuted {
+		if nonce := ev.BatchNonce.Uint64(); nonce > w.highestBatchNonce {
+			w.alert(ctx, AlertUnknownBatch, nonce, fmt.Sprintf(
+				"batch %d of token %s executed in tx %s, Cosmos never created a batch above %d",
+				nonce,
+				ev.Token.Hex(),
+				ev.Raw.TxHash.Hex(),
+				w.highestBatchNonce,
+			))
+		}
+	}
+
+	w.logger.Debug().
+		Uint64("start", startingBlock).
+		Uint64("end", currentBlock).
+		Int("num_valset_updated", len(events.ValsetUpdated)).
+		Int("num_transaction_batch_executed", len(events.TransactionBatchExecuted)).
+		Msg("checked Gravity events")
+
+	w.lastCheckedBlock = currentBlock
+	return nil
+}
+
+// checkEventNonces compares the last event nonce of the Gravity contract with the last one Cosmos observed. Cosmos
+// can't be ahead of the contract, and must keep up with it.
+func (w *gravityWatchtower) checkEventNonces(ctx context.Context) error {
+	latestBlock, err := w.finalityStrategy.LatestFinalBlock(ctx, w.ethProvider)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the latest final block")
+	}
+
+	ethNonce, err := w.gravityContract.GetLastEventNonce(
+		ctx,
+		w.gravityContract.FromAddress(),
+		new(big.Int).SetUint64(latestBlock),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to get the last event nonce from the Gravity contract")
+	}
+
+	claims, err := w.observedClaims(ctx)
+	if err != nil {
+		return err
+	}
+
+	var observedNonce uint64
+	for _, claim := range claims {
+		if claim.GetEventNonce() > observedNonce {
+			observedNonce = claim.GetEventNonce()
+		}
+	}
+
+	// A stall is timed from when the contract got ahead, not from the last attestation, as the bridge may have been
+	// idle for long before.
+	now := w.now()
+	switch {
+	case observedNonce >= ethNonce.Uint64():
+		w.behindSince = time.Time{}
+	case w.behindSince.IsZero() || observedNonce != w.lastObservedNonce:
+		w.behindSince = now
+	}
+	w.lastObservedNonce = observedNonce
+
+	switch {
+	case observedNonce > ethNonce.Uint64():
+		w.alert(ctx, AlertUnknownEvent, observedNonce, fmt.Sprintf(
+			"Cosmos observed event %d, the Gravity contract is at event %d as of block %d",
+			observedNonce,
+			ethNonce.Uint64(),
+			latestBlock,
+		))
+
+	case observedNonce < ethNonce.Uint64() && now.Sub(w.behindSince) >= w.stallTimeout:
+		w.alert(ctx, AlertAttestationStall, observedNonce, fmt.Sprintf(
+			"Cosmos has been stuck at event %d for %s, the Gravity contract is at event %d",
+			observedNonce,
+			now.Sub(w.behindSince).Round(time.Second),
+			ethNonce.Uint64(),
+		))
+	}
+
+	return nil
+}
+
+// observedClaims returns the claims of the most recent attestations Cosmos observed.
+func (w *gravityWatchtower) observedClaims(ctx context.Context) ([]types.EthereumClaim, error) {
+	resp, err := w.cosmosQueryClient.GetAttestations(ctx, &types.QueryAttestationsRequest{Limit: attestationsLimit})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get the attestations")
+	}
+
+	if resp == nil {
+		return nil, nil
+	}
+
+	claims := make([]types.EthereumClaim, 0, len(resp.Attestations))
+	for _, att := range resp.Attestations {
+		if !att.Observed || att.Claim == nil {
+			continue
+		}
+
+		var claim types.EthereumClaim
+		if err := w.claimUnpacker.UnpackAny(att.Claim, &claim); err != nil {
+			return nil, errors.Wrap(err, "failed to unpack the attestation claim")
+		}
+
+		claims = append(claims, claim)
+	}
+
+	return claims, nil
+}
+
+// alert raises an alert the first time something is found wrong with the bridge.
+func (w *gravityWatchtower) alert(ctx context.Context, kind string, nonce uint64, reason string) {
+	key := fmt.Sprintf("%s/%d", kind, nonce)
+	if w.reported[key] {
+		return
+	}
+	w.reported[key] = true
+
+	alert := &Alert{
+		Time:   w.now(),
+		Kind:   kind,
+		Nonce:  nonce,
+		Reason: reason,
+	}
+
+	w.logger.Error().
+		Str("kind", alert.Kind).
+		Uint64("nonce", alert.Nonce).
+		Str("reason", alert.Reason).
+		Msg("BRIDGE ALERT: Ethereum and Cosmos disagree")
+
+	if w.alertsFile != "" {
+		if err := appendAlert(w.alertsFile, alert); err != nil {
+			w.logger.Err(err).Str("file", w.alertsFile).Msg("failed to record the alert")
+		}
+	}
+
+	if w.alertWebhook != "" {
+		if err := postAlert(ctx, w.alertWebhook, alert); err != nil {
+			w.logger.Err(err).Msg("failed to post the alert to the webhook")
+		}
+	}
+}
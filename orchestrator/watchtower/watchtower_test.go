@@ -0,0 +1,269 @@
+package watchtower
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Gravity-Bridge/Gravity-Bridge/module/x/gravity/types"
+	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
+	ethcmn "github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/gogo/protobuf/proto"
+	"github.com/golang/mock/gomock"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+	"github.com/cicizeo/loran/mocks"
+	gravityMocks "github.com/cicizeo/loran/mocks/gravity"
+	"github.com/cicizeo/loran/orchestrator/finality"
+	"github.com/cicizeo/loran/orchestrator/relayer"
+)
+
+func TestWatchtower(t *testing.T) {
+	logger := zerolog.New(zerolog.ConsoleWriter{Out: os.Stderr})
+	fromAddress := ethcmn.HexToAddress("0xd8da6bf26964af9d7eed9e03e53415d37aa96045")
+
+	registry := codectypes.NewInterfaceRegistry()
+	types.RegisterInterfaces(registry)
+
+	newWatchtower := func(
+		t *testing.T,
+		mockCtrl *gomock.Controller,
+	) (*gravityWatchtower, *mocks.MockQueryClient, *gravityMocks.MockContract) {
+		mockQClient := mocks.NewMockQueryClient(mockCtrl)
+
+		mockGravityContract := gravityMocks.NewMockContract(mockCtrl)
+		mockGravityContract.EXPECT().Provider().Return(nil).AnyTimes()
+		mockGravityContract.EXPECT().FromAddress().Return(fromAddress).AnyTimes()
+
+		w := NewWatchtower(
+			logger,
+			mockQClient,
+			registry,
+			mockGravityContract,
+			relayer.NewGravityRelayer(logger, mockQClient, mockGravityContract, false, false, false, 0, 0, 1),
+			finality.FixedDepth(5),
+			time.Minute,
+			2000,
+			time.Hour,
+			SetAlertsFile(filepath.Join(t.TempDir(), "alerts.jsonl")),
+		).(*gravityWatchtower)
+
+		return w, mockQClient, mockGravityContract
+	}
+
+	readAlerts := func(t *testing.T, w *gravityWatchtower) []Alert {
+		bz, err := os.ReadFile(w.alertsFile)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		assert.Nil(t, err)
+
+		var alerts []Alert
+		for _, line := range strings.Split(strings.TrimSpace(string(bz)), "\n") {
+			var alert Alert
+			assert.Nil(t, json.Unmarshal([]byte(line), &alert))
+			alerts = append(alerts, alert)
+		}
+
+		return alerts
+	}
+
+	attestations := func(t *testing.T, claims ...proto.Message) *types.QueryAttestationsResponse {
+		resp := &types.QueryAttestationsResponse{}
+		for _, claim := range claims {
+			anyClaim, err := codectypes.NewAnyWithValue(claim)
+			assert.Nil(t, err)
+			resp.Attestations = append(resp.Attestations, types.Attestation{Observed: true, Claim: anyClaim})
+		}
+
+		return resp
+	}
+
+	valset := func(nonce uint64, power uint64) *types.Valset {
+		return &types.Valset{
+			Nonce: nonce,
+			Members: []types.BridgeValidator{
+				{EthereumAddress: ethcmn.HexToAddress("0x1").Hex(), Power: power},
+			},
+		}
+	}
+
+	t.Run("same valset", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		w, mockQClient, _ := newWatchtower(t, mockCtrl)
+		mockQClient.EXPECT().ValsetRequest(gomock.Any(), &types.QueryValsetRequestRequest{Nonce: 3}).
+			Return(&types.QueryValsetRequestResponse{Valset: valset(3, 100)}, nil)
+
+		assert.Nil(t, w.checkValset(context.Background(), valset(3, 100)))
+		assert.Empty(t, readAlerts(t, w))
+	})
+
+	t.Run("foreign valset", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		w, mockQClient, _ := newWatchtower(t, mockCtrl)
+		mockQClient.EXPECT().ValsetRequest(gomock.Any(), &types.QueryValsetRequestRequest{Nonce: 3}).
+			Return(&types.QueryValsetRequestResponse{Valset: valset(3, 100)}, nil).Times(2)
+
+		assert.Nil(t, w.checkValset(context.Background(), valset(3, 200)))
+		assert.Nil(t, w.checkValset(context.Background(), valset(3, 200)))
+
+		alerts := readAlerts(t, w)
+		assert.Len(t, alerts, 1)
+		assert.Equal(t, AlertForeignValset, alerts[0].Kind)
+		assert.Equal(t, uint64(3), alerts[0].Nonce)
+	})
+
+	t.Run("valset unknown to cosmos", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		w, mockQClient, _ := newWatchtower(t, mockCtrl)
+		mockQClient.EXPECT().ValsetRequest(gomock.Any(), gomock.Any()).
+			Return(&types.QueryValsetRequestResponse{}, nil).Times(2)
+		mockQClient.EXPECT().LastValsetRequests(gomock.Any(), gomock.Any()).
+			Return(&types.QueryLastValsetRequestsResponse{Valsets: []types.Valset{*valset(5, 100)}}, nil).Times(2)
+
+		// pruned
+		assert.Nil(t, w.checkValset(context.Background(), valset(3, 100)))
+		assert.Empty(t, readAlerts(t, w))
+
+		// beyond the latest cosmos valset
+		assert.Nil(t, w.checkValset(context.Background(), valset(6, 100)))
+
+		alerts := readAlerts(t, w)
+		assert.Len(t, alerts, 1)
+		assert.Equal(t, AlertForeignValset, alerts[0].Kind)
+		assert.Equal(t, uint64(6), alerts[0].Nonce)
+	})
+
+	t.Run("batch nonces", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		w, mockQClient, _ := newWatchtower(t, mockCtrl)
+		mockQClient.EXPECT().OutgoingTxBatches(gomock.Any(), gomock.Any()).
+			Return(&types.QueryOutgoingTxBatchesResponse{Batches: []types.OutgoingTxBatch{
+				{BatchNonce: 12},
+				{BatchNonce: 9},
+			}}, nil)
+
+		w.highestBatchNonce = 10
+		assert.Nil(t, w.refreshBatchNonces(context.Background()))
+		assert.Equal(t, uint64(12), w.highestBatchNonce)
+	})
+
+	t.Run("unknown event", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		w, mockQClient, mockGravityContract := newWatchtower(t, mockCtrl)
+		w.lastCheckedBlock = 90
+
+		// the contract is queried at the latest final block, not the last scanned one
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).
+			Return(&ethtypes.Header{Number: big.NewInt(105)}, nil).AnyTimes()
+		w.ethProvider = ethProvider
+
+		mockGravityContract.EXPECT().GetLastEventNonce(gomock.Any(), fromAddress, big.NewInt(100)).
+			Return(big.NewInt(6), nil)
+		mockQClient.EXPECT().GetAttestations(gomock.Any(), gomock.Any()).
+			Return(attestations(t,
+				&types.MsgSendToCosmosClaim{EventNonce: 6},
+				&types.MsgBatchSendToEthClaim{EventNonce: 7, BatchNonce: 2},
+			), nil)
+
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+
+		alerts := readAlerts(t, w)
+		assert.Len(t, alerts, 1)
+		assert.Equal(t, AlertUnknownEvent, alerts[0].Kind)
+		assert.Equal(t, uint64(7), alerts[0].Nonce)
+	})
+
+	t.Run("attestation stall", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		w, mockQClient, mockGravityContract := newWatchtower(t, mockCtrl)
+		w.lastCheckedBlock = 90
+
+		// the contract is queried at the latest final block, not the last scanned one
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).
+			Return(&ethtypes.Header{Number: big.NewInt(105)}, nil).AnyTimes()
+		w.ethProvider = ethProvider
+
+		now := time.Unix(1000, 0)
+		w.now = func() time.Time { return now }
+
+		mockGravityContract.EXPECT().GetLastEventNonce(gomock.Any(), fromAddress, big.NewInt(100)).
+			Return(big.NewInt(8), nil).Times(3)
+		mockQClient.EXPECT().GetAttestations(gomock.Any(), gomock.Any()).
+			Return(attestations(t, &types.MsgSendToCosmosClaim{EventNonce: 6}), nil).Times(3)
+
+		// behind, but not for long
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+		now = now.Add(30 * time.Minute)
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+		assert.Empty(t, readAlerts(t, w))
+
+		now = now.Add(30 * time.Minute)
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+
+		alerts := readAlerts(t, w)
+		assert.Len(t, alerts, 1)
+		assert.Equal(t, AlertAttestationStall, alerts[0].Kind)
+		assert.Equal(t, uint64(6), alerts[0].Nonce)
+	})
+
+	t.Run("idle, then a new event", func(t *testing.T) {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+
+		w, mockQClient, mockGravityContract := newWatchtower(t, mockCtrl)
+
+		ethProvider := mocks.NewMockEVMProviderWithRet(mockCtrl)
+		ethProvider.EXPECT().HeaderByNumber(gomock.Any(), nil).
+			Return(&ethtypes.Header{Number: big.NewInt(105)}, nil).AnyTimes()
+		w.ethProvider = ethProvider
+
+		now := time.Unix(1000, 0)
+		w.now = func() time.Time { return now }
+
+		ethNonce := int64(6)
+		mockGravityContract.EXPECT().GetLastEventNonce(gomock.Any(), fromAddress, big.NewInt(100)).
+			DoAndReturn(func(context.Context, ethcmn.Address, *big.Int) (*big.Int, error) {
+				return big.NewInt(ethNonce), nil
+			}).Times(4)
+		mockQClient.EXPECT().GetAttestations(gomock.Any(), gomock.Any()).
+			Return(attestations(t, &types.MsgSendToCosmosClaim{EventNonce: 6}), nil).Times(4)
+
+		// up to date for hours
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+		now = now.Add(3 * time.Hour)
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+
+		// a deposit lands, validators have yet to attest it
+		ethNonce = 7
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+		assert.Empty(t, readAlerts(t, w))
+
+		now = now.Add(time.Hour)
+		assert.Nil(t, w.checkEventNonces(context.Background()))
+
+		alerts := readAlerts(t, w)
+		assert.Len(t, alerts, 1)
+		assert.Equal(t, AlertAttestationStall, alerts[0].Kind)
+	})
+}